@@ -17,6 +17,9 @@ type MockBeaconInstance struct {
 	MockProposerDuties     *ProposerDutiesResponse
 	MockProposerDutiesErr  error
 	MockFetchValidatorsErr error
+	MockBlock              *GetBlockResponse
+	MockRandao             *GetRandaoResponse
+	MockWithdrawals        *GetWithdrawalsResponse
 
 	ResponseDelay time.Duration
 }
@@ -35,6 +38,9 @@ func NewMockBeaconInstance() *MockBeaconInstance {
 		MockSyncStatusErr:      nil,
 		MockProposerDutiesErr:  nil,
 		MockFetchValidatorsErr: nil,
+		MockBlock:              nil,
+		MockRandao:             nil,
+		MockWithdrawals:        nil,
 
 		ResponseDelay: 0,
 
@@ -110,7 +116,7 @@ func (c *MockBeaconInstance) GetGenesis() (*GetGenesisResponse, error) {
 }
 
 func (c *MockBeaconInstance) GetBlock(blockID string) (block *GetBlockResponse, err error) {
-	return nil, nil
+	return c.MockBlock, nil
 }
 
 func (c *MockBeaconInstance) GetSpec() (spec *GetSpecResponse, err error) {
@@ -122,9 +128,9 @@ func (c *MockBeaconInstance) GetForkSchedule() (spec *GetForkScheduleResponse, e
 }
 
 func (c *MockBeaconInstance) GetRandao(slot uint64) (spec *GetRandaoResponse, err error) {
-	return nil, nil
+	return c.MockRandao, nil
 }
 
 func (c *MockBeaconInstance) GetWithdrawals(slot uint64) (spec *GetWithdrawalsResponse, err error) {
-	return nil, nil
+	return c.MockWithdrawals, nil
 }
@@ -12,6 +12,8 @@ func init() {
 	toolCmd.AddCommand(tool.DataAPIExportBids)
 	toolCmd.AddCommand(tool.ArchiveExecutionPayloads)
 	toolCmd.AddCommand(tool.Migrate)
+	toolCmd.AddCommand(tool.MockBlockSim)
+	toolCmd.AddCommand(tool.ExportDemotions)
 	rootCmd.AddCommand(toolCmd)
 }
 
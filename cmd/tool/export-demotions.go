@@ -0,0 +1,106 @@
+package tool
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/spf13/cobra"
+)
+
+var exportDemotionsBuilderPubkey string
+
+func init() {
+	ExportDemotions.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+	ExportDemotions.Flags().Uint64Var(&slotFrom, "slot-from", 0, "start slot (inclusive)")
+	ExportDemotions.Flags().Uint64Var(&slotTo, "slot-to", 0, "end slot (inclusive)")
+	ExportDemotions.Flags().StringVar(&exportDemotionsBuilderPubkey, "builder", "", "only export demotions of this builder pubkey")
+	ExportDemotions.Flags().StringSliceVar(&outFiles, "out", []string{}, "output filename")
+}
+
+var ExportDemotions = &cobra.Command{
+	Use:   "export-demotions",
+	Short: "export builder demotions with the owed refunds and their evidence",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(outFiles) == 0 {
+			outFnBase := fmt.Sprintf("demotions_slot-%d-to-%d", slotFrom, slotTo)
+			outFiles = append(outFiles, outFnBase+".csv")
+			outFiles = append(outFiles, outFnBase+".json")
+		}
+		log.Infof("exporting demotions to %s", strings.Join(outFiles, ", "))
+
+		if slotFrom == 0 || slotTo == 0 {
+			log.Fatal("must specify --slot-from and --slot-to")
+		}
+
+		// Connect to Postgres
+		dbURL, err := url.Parse(postgresDSN)
+		if err != nil {
+			log.WithError(err).Fatalf("couldn't read db URL")
+		}
+		log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
+		db, err := database.NewDatabaseService(postgresDSN)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
+		}
+
+		demotions, err := db.GetBuilderDemotions(database.GetBuilderDemotionsFilters{SlotFrom: slotFrom, SlotTo: slotTo, BuilderPubkey: exportDemotionsBuilderPubkey}) //nolint:exhaustruct
+		if err != nil {
+			log.WithError(err).Fatal("failed getting demotions")
+		}
+
+		numDelivered := 0
+		entries := make([]common.BuilderDemotionRefundJSON, len(demotions))
+		for i, demotion := range demotions {
+			entries[i] = database.BuilderDemotionEntryToRefundJSON(demotion)
+			if demotion.WasDelivered {
+				numDelivered++
+			}
+		}
+		log.Infof("got %d demotions, %d with delivered blocks", len(entries), numDelivered)
+
+		if len(entries) == 0 {
+			return
+		}
+
+		writeToFile := func(outFile string) {
+			f, err := os.Create(outFile)
+			if err != nil {
+				log.WithError(err).Fatal("failed to open file")
+			}
+			defer f.Close()
+
+			if strings.HasSuffix(outFile, ".csv") {
+				// write CSV
+				w := csv.NewWriter(f)
+				defer w.Flush()
+				if err := w.Write(entries[0].CSVHeader()); err != nil {
+					log.WithError(err).Fatal("error writing record to file")
+				}
+				for _, record := range entries {
+					if err := w.Write(record.ToCSVRecord()); err != nil {
+						log.WithError(err).Fatal("error writing record to file")
+					}
+				}
+			} else {
+				// write JSON
+				encoder := json.NewEncoder(f)
+				err = encoder.Encode(entries)
+				if err != nil {
+					log.WithError(err).Fatal("failed to write json to file")
+				}
+			}
+
+			log.Infof("Wrote %d entries to %s", len(entries), outFile)
+		}
+
+		for _, outFile := range outFiles {
+			writeToFile(outFile)
+		}
+	},
+}
@@ -0,0 +1,199 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/flashbots/go-utils/jsonrpc"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mockBlockSimListenAddr    string
+	mockBlockSimFailHashes    []string
+	mockBlockSimFailError     string
+	mockBlockSimError         string
+	mockBlockSimErrorRate     float64
+	mockBlockSimLatency       time.Duration
+	mockBlockSimLatencyJitter time.Duration
+	mockBlockSimTimeoutRate   float64
+	mockBlockSimTimeoutDelay  time.Duration
+
+	// shortcuts for the errors the relay knows about (the relay prefixes them with "simulation failed: ")
+	mockBlockSimErrorPresets = map[string]string{
+		"block-already-known":  "block already known",
+		"block-requires-reorg": "block requires a reorg",
+		"missing-trie-node":    "missing trie node 23e21f94cd97b3b27ae5c758277639dd387a6e3da5923c5485f24ec6c71e16b8 (path ) <nil>",
+	}
+)
+
+func init() {
+	MockBlockSim.Flags().StringVar(&mockBlockSimListenAddr, "listen-addr", "localhost:8545", "listen address for the JSON-RPC server")
+	MockBlockSim.Flags().StringSliceVar(&mockBlockSimFailHashes, "fail-block-hashes", []string{}, "block hashes for which validation fails")
+	MockBlockSim.Flags().StringVar(&mockBlockSimFailError, "fail-error", "invalid block", "error returned for --fail-block-hashes")
+	MockBlockSim.Flags().StringVar(&mockBlockSimError, "error", "", "error returned for every request, or one of: block-already-known, block-requires-reorg, missing-trie-node")
+	MockBlockSim.Flags().Float64Var(&mockBlockSimErrorRate, "error-rate", 1, "probability (0-1) that --error is returned")
+	MockBlockSim.Flags().DurationVar(&mockBlockSimLatency, "latency", 0, "latency added to every response")
+	MockBlockSim.Flags().DurationVar(&mockBlockSimLatencyJitter, "latency-jitter", 0, "random latency added on top of --latency")
+	MockBlockSim.Flags().Float64Var(&mockBlockSimTimeoutRate, "timeout-rate", 0, "probability (0-1) that a request hangs for --timeout-delay")
+	MockBlockSim.Flags().DurationVar(&mockBlockSimTimeoutDelay, "timeout-delay", 30*time.Second, "how long timed out requests hang")
+}
+
+var MockBlockSim = &cobra.Command{
+	Use:   "mock-blocksim",
+	Short: "serve a mock block simulator (flashbots_validateBuilderSubmissionV1/V2) for devnets and tests",
+	Run: func(cmd *cobra.Command, args []string) {
+		if preset, ok := mockBlockSimErrorPresets[mockBlockSimError]; ok {
+			mockBlockSimError = preset
+		}
+
+		failHashes := make(map[string]bool)
+		for _, hash := range mockBlockSimFailHashes {
+			failHashes[strings.ToLower(hash)] = true
+		}
+
+		sim := &mockBlockSim{
+			log:        log.WithField("service", "mock-blocksim"),
+			failHashes: failHashes,
+			upgrader:   websocket.Upgrader{}, //nolint:exhaustruct
+		}
+
+		log.WithFields(logrus.Fields{
+			"failBlockHashes": len(failHashes),
+			"error":           mockBlockSimError,
+			"errorRate":       mockBlockSimErrorRate,
+			"latency":         mockBlockSimLatency,
+			"latencyJitter":   mockBlockSimLatencyJitter,
+			"timeoutRate":     mockBlockSimTimeoutRate,
+		}).Infof("mock block simulator listening on %s (http:// and ws://)", mockBlockSimListenAddr)
+
+		srv := &http.Server{ //nolint:exhaustruct
+			Addr:              mockBlockSimListenAddr,
+			Handler:           sim,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		if err := srv.ListenAndServe(); err != nil {
+			log.WithError(err).Fatal("server error")
+		}
+	},
+}
+
+type mockBlockSim struct {
+	log        *logrus.Entry
+	failHashes map[string]bool
+	upgrader   websocket.Upgrader
+}
+
+func (s *mockBlockSim) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if websocket.IsWebSocketUpgrade(req) {
+		conn, err := s.upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			s.log.WithError(err).Error("failed to upgrade to websocket")
+			return
+		}
+		s.serveWebSocket(conn)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := s.handle(body, req.Header.Get("X-High-Priority") == "true")
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.log.WithError(err).Error("failed to write response")
+	}
+}
+
+func (s *mockBlockSim) serveWebSocket(conn *websocket.Conn) {
+	defer conn.Close()
+
+	// Responses are written by a single goroutine. resC is only closed once all
+	// requests in flight are handled, even if the client disconnected meanwhile.
+	resC := make(chan *jsonrpc.JSONRPCResponse)
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		for res := range resC {
+			if err := conn.WriteJSON(res); err != nil {
+				s.log.WithError(err).Error("failed to write response")
+			}
+		}
+	}()
+
+	var handlers sync.WaitGroup
+	for {
+		_, body, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		handlers.Add(1)
+		go func() {
+			defer handlers.Done()
+			resC <- s.handle(body, false)
+		}()
+	}
+	handlers.Wait()
+	close(resC)
+	<-writerDone
+}
+
+// handle applies the configured behaviours to a single JSON-RPC request
+func (s *mockBlockSim) handle(body []byte, isHighPrio bool) *jsonrpc.JSONRPCResponse {
+	req := new(jsonrpc.JSONRPCRequest)
+	if err := json.Unmarshal(body, req); err != nil {
+		return &jsonrpc.JSONRPCResponse{ID: 0, Version: "2.0", Result: nil, Error: &jsonrpc.JSONRPCError{Code: jsonrpc.ErrParse, Message: err.Error()}} //nolint:exhaustruct
+	}
+
+	blockHash, _ := jsonparser.GetString(body, "params", "[0]", "message", "block_hash")
+	log := s.log.WithFields(logrus.Fields{
+		"method":     req.Method,
+		"blockHash":  blockHash,
+		"isHighPrio": isHighPrio,
+	})
+
+	respondError := func(msg string) *jsonrpc.JSONRPCResponse {
+		log.WithField("error", msg).Info("validation failed")
+		return &jsonrpc.JSONRPCResponse{ID: req.ID, Version: "2.0", Result: nil, Error: &jsonrpc.JSONRPCError{Code: -32000, Message: msg}} //nolint:exhaustruct
+	}
+
+	if req.Method != "flashbots_validateBuilderSubmissionV1" && req.Method != "flashbots_validateBuilderSubmissionV2" {
+		return respondError(fmt.Sprintf("method %s not supported", req.Method))
+	}
+
+	//nolint:gosec
+	if mockBlockSimTimeoutRate > 0 && rand.Float64() < mockBlockSimTimeoutRate {
+		log.Info("simulating timeout")
+		time.Sleep(mockBlockSimTimeoutDelay)
+	}
+
+	latency := mockBlockSimLatency
+	if mockBlockSimLatencyJitter > 0 {
+		latency += time.Duration(rand.Int63n(int64(mockBlockSimLatencyJitter))) //nolint:gosec
+	}
+	time.Sleep(latency)
+
+	if s.failHashes[strings.ToLower(blockHash)] {
+		return respondError(mockBlockSimFailError)
+	}
+
+	//nolint:gosec
+	if mockBlockSimError != "" && rand.Float64() < mockBlockSimErrorRate {
+		return respondError(mockBlockSimError)
+	}
+
+	log.Info("validation successful")
+	return &jsonrpc.JSONRPCResponse{ID: req.ID, Version: "2.0", Result: json.RawMessage("null"), Error: nil}
+}
@@ -36,15 +36,17 @@ type BuilderStatus struct {
 
 // Profile captures performance metrics for the block submission handler. Each
 // field corresponds to the number of microseconds in each stage. The `Total`
-// field is the number of microseconds taken for entire flow.
+// field is the number of microseconds taken for entire flow. `SimCacheHit` is
+// true if the simulation result was taken from the cache.
 type Profile struct {
 	Decode      uint64
 	Prechecks   uint64
 	Simulation  uint64
 	RedisUpdate uint64
 	Total       uint64
+	SimCacheHit bool
 }
 
 func (p *Profile) String() string {
-	return fmt.Sprintf("%v,%v,%v,%v,%v", p.Decode, p.Prechecks, p.Simulation, p.RedisUpdate, p.Total)
+	return fmt.Sprintf("%v,%v,%v,%v,%v,%v", p.Decode, p.Prechecks, p.Simulation, p.RedisUpdate, p.Total, p.SimCacheHit)
 }
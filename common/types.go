@@ -270,6 +270,134 @@ func (b *BidTraceV2WithTimestampJSON) ToCSVRecord() []string {
 	}
 }
 
+// BuilderDemotionRefundJSON describes a demotion and the refund owed to the proposer. A refund
+// is only owed if the invalid block was delivered, and then amounts to the bid value.
+type BuilderDemotionRefundJSON struct {
+	ID                          int64           `json:"id,string"`
+	Slot                        uint64          `json:"slot,string"`
+	Epoch                       uint64          `json:"epoch,string"`
+	BuilderPubkey               string          `json:"builder_pubkey"`
+	ProposerPubkey              string          `json:"proposer_pubkey"`
+	BlockHash                   string          `json:"block_hash"`
+	FeeRecipient                string          `json:"fee_recipient"`
+	Value                       string          `json:"value"`
+	SimError                    string          `json:"sim_error"`
+	DemotedAt                   int64           `json:"demoted_at,string"`
+	WasDelivered                bool            `json:"was_delivered"`
+	RefundOwed                  string          `json:"refund_owed"`
+	ReviewStatus                string          `json:"review_status"`
+	ReviewNotes                 string          `json:"review_notes"`
+	SubmitBlockRequest          json.RawMessage `json:"submit_block_request,omitempty"`
+	SignedBeaconBlock           json.RawMessage `json:"signed_beacon_block,omitempty"`
+	SignedValidatorRegistration json.RawMessage `json:"signed_validator_registration,omitempty"`
+}
+
+func (b *BuilderDemotionRefundJSON) CSVHeader() []string {
+	return []string{
+		"id",
+		"slot",
+		"epoch",
+		"builder_pubkey",
+		"proposer_pubkey",
+		"block_hash",
+		"fee_recipient",
+		"value",
+		"sim_error",
+		"demoted_at",
+		"was_delivered",
+		"refund_owed",
+		"review_status",
+		"review_notes",
+		"submit_block_request",
+		"signed_beacon_block",
+		"signed_validator_registration",
+	}
+}
+
+func (b *BuilderDemotionRefundJSON) ToCSVRecord() []string {
+	return []string{
+		fmt.Sprint(b.ID),
+		fmt.Sprint(b.Slot),
+		fmt.Sprint(b.Epoch),
+		b.BuilderPubkey,
+		b.ProposerPubkey,
+		b.BlockHash,
+		b.FeeRecipient,
+		b.Value,
+		b.SimError,
+		fmt.Sprint(b.DemotedAt),
+		fmt.Sprint(b.WasDelivered),
+		b.RefundOwed,
+		b.ReviewStatus,
+		b.ReviewNotes,
+		string(b.SubmitBlockRequest),
+		string(b.SignedBeaconBlock),
+		string(b.SignedValidatorRegistration),
+	}
+}
+
+// BuilderStatsJSON is the per-builder response of the builders data API
+type BuilderStatsJSON struct {
+	BuilderPubkey          string  `json:"builder_pubkey"`
+	BuilderID              string  `json:"builder_id"`
+	Description            string  `json:"description"`
+	IsHighPrio             bool    `json:"is_high_prio"`
+	IsBlacklisted          bool    `json:"is_blacklisted"`
+	IsOptimistic           bool    `json:"is_optimistic"`
+	NumSubmissionsTotal    uint64  `json:"num_submissions_total,string"`
+	NumSubmissionsSimError uint64  `json:"num_submissions_simerror,string"`
+	NumSentGetPayload      uint64  `json:"num_sent_getpayload,string"`
+	SimErrorRate           float64 `json:"sim_error_rate"`
+	WindowSlotFrom         uint64  `json:"window_slot_from,string"`
+	NumSlotsBid            uint64  `json:"num_slots_bid,string"`
+	NumSlotsDelivered      uint64  `json:"num_slots_delivered,string"`
+	WinRate                float64 `json:"win_rate"`
+	ValueDelivered         string  `json:"value_delivered"`
+	MedianTotalDurationUs  uint64  `json:"median_total_duration_us,string"`
+}
+
+func (b *BuilderStatsJSON) CSVHeader() []string {
+	return []string{
+		"builder_pubkey",
+		"builder_id",
+		"description",
+		"is_high_prio",
+		"is_blacklisted",
+		"is_optimistic",
+		"num_submissions_total",
+		"num_submissions_simerror",
+		"num_sent_getpayload",
+		"sim_error_rate",
+		"window_slot_from",
+		"num_slots_bid",
+		"num_slots_delivered",
+		"win_rate",
+		"value_delivered",
+		"median_total_duration_us",
+	}
+}
+
+func (b *BuilderStatsJSON) ToCSVRecord() []string {
+	return []string{
+		b.BuilderPubkey,
+		b.BuilderID,
+		b.Description,
+		fmt.Sprint(b.IsHighPrio),
+		fmt.Sprint(b.IsBlacklisted),
+		fmt.Sprint(b.IsOptimistic),
+		fmt.Sprint(b.NumSubmissionsTotal),
+		fmt.Sprint(b.NumSubmissionsSimError),
+		fmt.Sprint(b.NumSentGetPayload),
+		fmt.Sprint(b.SimErrorRate),
+		fmt.Sprint(b.WindowSlotFrom),
+		fmt.Sprint(b.NumSlotsBid),
+		fmt.Sprint(b.NumSlotsDelivered),
+		fmt.Sprint(b.WinRate),
+		b.ValueDelivered,
+		fmt.Sprint(b.MedianTotalDurationUs),
+	}
+}
+
 type SignedBlindedBeaconBlock struct {
 	Bellatrix *boostTypes.SignedBlindedBeaconBlock
 	Capella   *apiv1capella.SignedBlindedBeaconBlock
@@ -777,3 +905,152 @@ func (b *BuilderSubmitBlockRequest) Withdrawals() []*consensuscapella.Withdrawal
 	}
 	return nil
 }
+
+// AuctionTopBidJSON is a change of the top bid during an auction
+type AuctionTopBidJSON struct {
+	TimestampMs   int64  `json:"timestamp_ms,string"`
+	BuilderPubkey string `json:"builder_pubkey"`
+	BlockHash     string `json:"block_hash"`
+	Value         string `json:"value"`
+}
+
+// SlotAuctionSummaryJSON is the response of the slot auction summary data API
+type SlotAuctionSummaryJSON struct {
+	Slot                  uint64          `json:"slot,string"`
+	Epoch                 uint64          `json:"epoch,string"`
+	ParentHash            string          `json:"parent_hash"`
+	ProposerPubkey        string          `json:"proposer_pubkey"`
+	NumBids               uint64          `json:"num_bids,string"`
+	NumBuilders           uint64          `json:"num_builders,string"`
+	TopBidTimeline        json.RawMessage `json:"top_bid_timeline"`
+	FloorValue            string          `json:"floor_value"`
+	WinningBuilderPubkey  string          `json:"winning_builder_pubkey"`
+	WinningBlockHash      string          `json:"winning_block_hash"`
+	WinningValue          string          `json:"winning_value"`
+	RunnerUpBuilderPubkey string          `json:"runner_up_builder_pubkey"`
+	RunnerUpValue         string          `json:"runner_up_value"`
+	Margin                string          `json:"margin"`
+	GetPayloadCalled      bool            `json:"getpayload_called"`
+	GetPayloadTooLate     bool            `json:"getpayload_too_late"`
+	GetPayloadMsIntoSlot  uint64          `json:"getpayload_ms_into_slot,string"`
+	PublishMs             uint64          `json:"publish_ms,string"`
+}
+
+func (b *SlotAuctionSummaryJSON) CSVHeader() []string {
+	return []string{
+		"slot",
+		"epoch",
+		"parent_hash",
+		"proposer_pubkey",
+		"num_bids",
+		"num_builders",
+		"top_bid_timeline",
+		"floor_value",
+		"winning_builder_pubkey",
+		"winning_block_hash",
+		"winning_value",
+		"runner_up_builder_pubkey",
+		"runner_up_value",
+		"margin",
+		"getpayload_called",
+		"getpayload_too_late",
+		"getpayload_ms_into_slot",
+		"publish_ms",
+	}
+}
+
+func (b *SlotAuctionSummaryJSON) ToCSVRecord() []string {
+	return []string{
+		fmt.Sprint(b.Slot),
+		fmt.Sprint(b.Epoch),
+		b.ParentHash,
+		b.ProposerPubkey,
+		fmt.Sprint(b.NumBids),
+		fmt.Sprint(b.NumBuilders),
+		string(b.TopBidTimeline),
+		b.FloorValue,
+		b.WinningBuilderPubkey,
+		b.WinningBlockHash,
+		b.WinningValue,
+		b.RunnerUpBuilderPubkey,
+		b.RunnerUpValue,
+		b.Margin,
+		fmt.Sprint(b.GetPayloadCalled),
+		fmt.Sprint(b.GetPayloadTooLate),
+		fmt.Sprint(b.GetPayloadMsIntoSlot),
+		fmt.Sprint(b.PublishMs),
+	}
+}
+
+// VersionedDataJSON is fork-dependent raw JSON together with its fork version, like in the beacon API
+type VersionedDataJSON struct {
+	Version string          `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// GetPayloadTimingJSON is a getPayload call of a proposer, which either got the payload delivered or was too late
+type GetPayloadTimingJSON struct {
+	Slot               uint64 `json:"slot,string"`
+	ProposerPubkey     string `json:"proposer_pubkey"`
+	BlockHash          string `json:"block_hash"`
+	Delivered          bool   `json:"delivered"`
+	TooLate            bool   `json:"too_late"`
+	SlotStartTimestamp uint64 `json:"slot_start_timestamp,string"`
+	DecodedAtMs        uint64 `json:"decoded_at_ms,string"` // when the relay decoded the signed getPayload request
+	MsIntoSlot         uint64 `json:"ms_into_slot,string"`
+	PublishMs          uint64 `json:"publish_ms,string"`
+}
+
+func (b *GetPayloadTimingJSON) CSVHeader() []string {
+	return []string{
+		"slot",
+		"proposer_pubkey",
+		"block_hash",
+		"delivered",
+		"too_late",
+		"slot_start_timestamp",
+		"decoded_at_ms",
+		"ms_into_slot",
+		"publish_ms",
+	}
+}
+
+func (b *GetPayloadTimingJSON) ToCSVRecord() []string {
+	return []string{
+		fmt.Sprint(b.Slot),
+		b.ProposerPubkey,
+		b.BlockHash,
+		fmt.Sprint(b.Delivered),
+		fmt.Sprint(b.TooLate),
+		fmt.Sprint(b.SlotStartTimestamp),
+		fmt.Sprint(b.DecodedAtMs),
+		fmt.Sprint(b.MsIntoSlot),
+		fmt.Sprint(b.PublishMs),
+	}
+}
+
+// DataAPIKeyJSON is a data API key as shown on the internal API. The key itself is only returned when it's issued.
+type DataAPIKeyJSON struct {
+	ID          int64  `json:"id,string"`
+	Key         string `json:"key,omitempty"`
+	Description string `json:"description"`
+	QuotaPerMin uint64 `json:"quota_per_min,string"`
+	IssuedAt    int64  `json:"issued_at,string"`
+	Revoked     bool   `json:"revoked"`
+	RevokedAt   int64  `json:"revoked_at,string,omitempty"`
+}
+
+// InternalAPIAuditJSON is an entry of the internal API audit log
+type InternalAPIAuditJSON struct {
+	ID         int64           `json:"id,string"`
+	Timestamp  int64           `json:"timestamp,string"`
+	Actor      string          `json:"actor"`
+	RemoteIP   string          `json:"remote_ip"`
+	RemoteAddr string          `json:"remote_addr"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	Action     string          `json:"action"`
+	Target     string          `json:"target"`
+	OldValue   json.RawMessage `json:"old_value"`
+	NewValue   json.RawMessage `json:"new_value"`
+}
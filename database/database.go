@@ -24,6 +24,7 @@ type IDatabaseService interface {
 	GetLatestValidatorRegistrations(timestampOnly bool) ([]*ValidatorRegistrationEntry, error)
 	GetValidatorRegistration(pubkey string) (*ValidatorRegistrationEntry, error)
 	GetValidatorRegistrationsForPubkeys(pubkeys []string) ([]*ValidatorRegistrationEntry, error)
+	GetValidatorRegistrationHistory(pubkey string, limit uint64) ([]*ValidatorRegistrationEntry, error)
 
 	SaveBuilderBlockSubmission(payload *common.BuilderSubmitBlockRequest, requestError, validationError error, receivedAt, eligibleAt time.Time, wasSimulated, saveExecPayload bool, profile common.Profile, optimisticSubmission bool) (entry *BuilderBlockSubmissionEntry, err error)
 	GetBlockSubmissionEntry(slot uint64, proposerPubkey, blockHash string) (entry *BuilderBlockSubmissionEntry, err error)
@@ -38,21 +39,41 @@ type IDatabaseService interface {
 	GetNumDeliveredPayloads() (uint64, error)
 	GetRecentDeliveredPayloads(filters GetPayloadsFilters) ([]*DeliveredPayloadEntry, error)
 	GetDeliveredPayloads(idFirst, idLast uint64) (entries []*DeliveredPayloadEntry, err error)
+	GetDeliveredPayloadByBlockHash(blockHash string) (*DeliveredPayloadEntry, error)
 
 	GetBlockBuilders() ([]*BlockBuilderEntry, error)
+	GetFilteredBlockBuilders(filters GetBlockBuildersFilters) ([]*BlockBuilderEntry, error)
 	GetBlockBuilderByPubkey(pubkey string) (*BlockBuilderEntry, error)
 	SetBlockBuilderStatus(pubkey string, status common.BuilderStatus) error
+	UpdateBlockBuilders(pubkeys []string, update BlockBuilderUpdate) error
 	SetBlockBuilderIDStatusIsOptimistic(pubkey string, isOptimistic bool) error
 	SetBlockBuilderCollateral(pubkey, builderID, collateral string) error
 	UpsertBlockBuilderEntryAfterSubmission(lastSubmission *BuilderBlockSubmissionEntry, isError bool) error
 	IncBlockBuilderStatsAfterGetPayload(builderPubkey string) error
+	GetBuilderStats(filters GetBuilderStatsFilters) ([]*BuilderStatsEntry, error)
 
 	InsertBuilderDemotion(submitBlockRequest *common.BuilderSubmitBlockRequest, simError error) error
 	UpdateBuilderDemotion(trace *common.BidTraceV2, signedBlock *common.SignedBeaconBlock, signedRegistration *types.SignedValidatorRegistration) error
 	GetBuilderDemotion(trace *common.BidTraceV2) (*BuilderDemotionEntry, error)
+	GetBuilderDemotions(filters GetBuilderDemotionsFilters) ([]*BuilderDemotionEntry, error)
+	GetBuilderDemotionByID(id int64) (*BuilderDemotionEntry, error)
+	ResolveBuilderDemotion(id int64, reviewStatus, reviewNotes string) error
+	GetBuilderDemotionReviewCounts(pubkey string) (map[string]uint64, error)
 
 	GetTooLateGetPayload(slot uint64) (entries []*TooLateGetPayloadEntry, err error)
+	GetTooLateGetPayloads(slotFrom, slotTo uint64, proposerPubkey string, limit uint64) (entries []*TooLateGetPayloadEntry, err error)
 	InsertTooLateGetPayload(slot uint64, proposerPubkey, blockHash string, slotStart, requestTime, decodeTime, msIntoSlot uint64) error
+
+	SaveSlotAuctionSummary(entry *SlotAuctionSummaryEntry) error
+	GetSlotAuctionSummaries(slotFrom, slotTo uint64) ([]*SlotAuctionSummaryEntry, error)
+
+	InsertDataAPIKey(entry *DataAPIKeyEntry) error
+	GetDataAPIKeyByHash(keyHash string) (*DataAPIKeyEntry, error)
+	GetDataAPIKeys() ([]*DataAPIKeyEntry, error)
+	RevokeDataAPIKey(id int64) (*DataAPIKeyEntry, error)
+
+	InsertInternalAPIAudit(entry *InternalAPIAuditEntry) error
+	GetInternalAPIAudit(filters GetInternalAPIAuditFilters) ([]*InternalAPIAuditEntry, error)
 }
 
 type DatabaseService struct {
@@ -99,8 +120,8 @@ func (s *DatabaseService) prepareNamedQueries() (err error) {
 
 	// Insert block builder submission
 	query = `INSERT INTO ` + vars.TableBuilderBlockSubmission + `
-	(received_at, eligible_at, execution_payload_id, was_simulated, sim_success, sim_error, sim_req_error, signature, slot, parent_hash, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, gas_used, gas_limit, num_tx, value, epoch, block_number, decode_duration, prechecks_duration, simulation_duration, redis_update_duration, total_duration, optimistic_submission) VALUES
-	(:received_at, :eligible_at, :execution_payload_id, :was_simulated, :sim_success, :sim_error, :sim_req_error, :signature, :slot, :parent_hash, :block_hash, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :gas_used, :gas_limit, :num_tx, :value, :epoch, :block_number, :decode_duration, :prechecks_duration, :simulation_duration, :redis_update_duration, :total_duration, :optimistic_submission)
+	(received_at, eligible_at, execution_payload_id, was_simulated, sim_success, sim_error, sim_req_error, signature, slot, parent_hash, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, gas_used, gas_limit, num_tx, value, epoch, block_number, decode_duration, prechecks_duration, simulation_duration, redis_update_duration, total_duration, sim_cache_hit, optimistic_submission) VALUES
+	(:received_at, :eligible_at, :execution_payload_id, :was_simulated, :sim_success, :sim_error, :sim_req_error, :signature, :slot, :parent_hash, :block_hash, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :gas_used, :gas_limit, :num_tx, :value, :epoch, :block_number, :decode_duration, :prechecks_duration, :simulation_duration, :redis_update_duration, :total_duration, :sim_cache_hit, :optimistic_submission)
 	RETURNING id`
 	s.nstmtInsertBlockBuilderSubmission, err = s.DB.PrepareNamed(query)
 	return err
@@ -162,6 +183,18 @@ func (s *DatabaseService) GetValidatorRegistrationsForPubkeys(pubkeys []string)
 	return entries, err
 }
 
+// GetValidatorRegistrationHistory returns the stored registrations of a validator, newest first. Only registrations
+// that changed the fee recipient or gas limit are stored, so this is the history of those changes.
+func (s *DatabaseService) GetValidatorRegistrationHistory(pubkey string, limit uint64) (entries []*ValidatorRegistrationEntry, err error) {
+	query := `SELECT id, inserted_at, pubkey, fee_recipient, timestamp, gas_limit, signature
+		FROM ` + vars.TableValidatorRegistration + `
+		WHERE pubkey=$1
+		ORDER BY timestamp DESC
+		LIMIT $2;`
+	err = s.DB.Select(&entries, query, pubkey, limit)
+	return entries, err
+}
+
 func (s *DatabaseService) GetLatestValidatorRegistrations(timestampOnly bool) ([]*ValidatorRegistrationEntry, error) {
 	// query details: https://stackoverflow.com/questions/3800551/select-first-row-in-each-group-by-group/7630564#7630564
 	query := `SELECT DISTINCT ON (pubkey) pubkey, fee_recipient, timestamp, gas_limit, signature`
@@ -234,6 +267,7 @@ func (s *DatabaseService) SaveBuilderBlockSubmission(payload *common.BuilderSubm
 		SimulationDuration:   profile.Simulation,
 		RedisUpdateDuration:  profile.RedisUpdate,
 		TotalDuration:        profile.Total,
+		SimCacheHit:          profile.SimCacheHit,
 		OptimisticSubmission: optimisticSubmission,
 	}
 	err = s.nstmtInsertBlockBuilderSubmission.QueryRow(blockSubmissionEntry).Scan(&blockSubmissionEntry.ID)
@@ -241,7 +275,7 @@ func (s *DatabaseService) SaveBuilderBlockSubmission(payload *common.BuilderSubm
 }
 
 func (s *DatabaseService) GetBlockSubmissionEntry(slot uint64, proposerPubkey, blockHash string) (entry *BuilderBlockSubmissionEntry, err error) {
-	query := `SELECT id, inserted_at, received_at, eligible_at, execution_payload_id, sim_success, sim_error, signature, slot, parent_hash, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, gas_used, gas_limit, num_tx, value, epoch, block_number, decode_duration, prechecks_duration, simulation_duration, redis_update_duration, total_duration, optimistic_submission 
+	query := `SELECT id, inserted_at, received_at, eligible_at, execution_payload_id, sim_success, sim_error, signature, slot, parent_hash, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, gas_used, gas_limit, num_tx, value, epoch, block_number, decode_duration, prechecks_duration, simulation_duration, redis_update_duration, total_duration, sim_cache_hit, optimistic_submission 
 	FROM ` + vars.TableBuilderBlockSubmission + `
 	WHERE slot=$1 AND proposer_pubkey=$2 AND block_hash=$3
 	ORDER BY builder_pubkey ASC
@@ -309,6 +343,8 @@ func (s *DatabaseService) GetRecentDeliveredPayloads(queryArgs GetPayloadsFilter
 	arg := map[string]interface{}{
 		"limit":           queryArgs.Limit,
 		"slot":            queryArgs.Slot,
+		"slot_from":       queryArgs.SlotFrom,
+		"slot_to":         queryArgs.SlotTo,
 		"cursor":          queryArgs.Cursor,
 		"block_hash":      queryArgs.BlockHash,
 		"block_number":    queryArgs.BlockNumber,
@@ -324,6 +360,12 @@ func (s *DatabaseService) GetRecentDeliveredPayloads(queryArgs GetPayloadsFilter
 	} else if queryArgs.Cursor > 0 {
 		whereConds = append(whereConds, "slot <= :cursor")
 	}
+	if queryArgs.SlotFrom > 0 {
+		whereConds = append(whereConds, "slot >= :slot_from")
+	}
+	if queryArgs.SlotTo > 0 {
+		whereConds = append(whereConds, "slot <= :slot_to")
+	}
 	if queryArgs.BlockHash != "" {
 		whereConds = append(whereConds, "block_hash = :block_hash")
 	}
@@ -379,6 +421,18 @@ func (s *DatabaseService) GetDeliveredPayloads(idFirst, idLast uint64) (entries
 	return entries, err
 }
 
+// GetDeliveredPayloadByBlockHash returns the delivered payload including the signed blinded beacon block
+func (s *DatabaseService) GetDeliveredPayloadByBlockHash(blockHash string) (*DeliveredPayloadEntry, error) {
+	query := `SELECT id, inserted_at, signed_at, signed_blinded_beacon_block, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, gas_used, gas_limit, publish_ms
+	FROM ` + vars.TableDeliveredPayload + `
+	WHERE block_hash = $1
+	ORDER BY slot DESC
+	LIMIT 1`
+	entry := &DeliveredPayloadEntry{}
+	err := s.DB.Get(entry, query, blockHash)
+	return entry, err
+}
+
 func (s *DatabaseService) GetNumDeliveredPayloads() (uint64, error) {
 	var count uint64
 	err := s.DB.QueryRow("SELECT COUNT(*) FROM " + vars.TableDeliveredPayload).Scan(&count)
@@ -387,41 +441,62 @@ func (s *DatabaseService) GetNumDeliveredPayloads() (uint64, error) {
 
 func (s *DatabaseService) GetBuilderSubmissions(filters GetBuilderSubmissionsFilters) ([]*BuilderBlockSubmissionEntry, error) {
 	arg := map[string]interface{}{
-		"limit":          filters.Limit,
-		"slot":           filters.Slot,
-		"block_hash":     filters.BlockHash,
-		"block_number":   filters.BlockNumber,
-		"builder_pubkey": filters.BuilderPubkey,
+		"limit":           filters.Limit,
+		"slot":            filters.Slot,
+		"slot_from":       filters.SlotFrom,
+		"slot_to":         filters.SlotTo,
+		"received_after":  filters.ReceivedAfter,
+		"received_before": filters.ReceivedBefore,
+		"cursor":          filters.Cursor,
+		"block_hash":      filters.BlockHash,
+		"block_number":    filters.BlockNumber,
+		"builder_pubkey":  filters.BuilderPubkey,
 	}
 
 	fields := "id, inserted_at, received_at, eligible_at, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, gas_used, gas_limit, optimistic_submission"
-	limit := "LIMIT :limit"
+	limit := ""
+	if filters.Limit > 0 {
+		limit = "LIMIT :limit"
+	}
 
 	whereConds := []string{
 		"(sim_success = true OR optimistic_submission = true)",
 	}
 	if filters.Slot > 0 {
 		whereConds = append(whereConds, "slot = :slot")
-		limit = "" // remove the limit when filtering by slot
+	}
+	if filters.SlotFrom > 0 {
+		whereConds = append(whereConds, "slot >= :slot_from")
+	}
+	if filters.SlotTo > 0 {
+		whereConds = append(whereConds, "slot <= :slot_to")
+	}
+	if !filters.ReceivedAfter.IsZero() {
+		whereConds = append(whereConds, "received_at >= :received_after")
+	}
+	if !filters.ReceivedBefore.IsZero() {
+		whereConds = append(whereConds, "received_at < :received_before")
 	}
 	if filters.BlockNumber > 0 {
 		whereConds = append(whereConds, "block_number = :block_number")
-		limit = "" // remove the limit when filtering by block_number
 	}
 	if filters.BlockHash != "" {
 		whereConds = append(whereConds, "block_hash = :block_hash")
-		limit = "" // remove the limit when filtering by block_hash
 	}
 	if filters.BuilderPubkey != "" {
 		whereConds = append(whereConds, "builder_pubkey = :builder_pubkey")
 	}
+	if filters.Cursor > 0 {
+		whereConds = append(whereConds, "id <= :cursor")
+	}
 
 	where := ""
 	if len(whereConds) > 0 {
 		where = "WHERE " + strings.Join(whereConds, " AND ")
 	}
 
-	query := fmt.Sprintf("SELECT %s FROM %s %s ORDER BY slot DESC, inserted_at DESC %s", fields, vars.TableBuilderBlockSubmission, where, limit)
+	// ordering by id keeps pages stable while new submissions are inserted
+	query := fmt.Sprintf("SELECT %s FROM %s %s ORDER BY id DESC %s", fields, vars.TableBuilderBlockSubmission, where, limit)
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
@@ -478,25 +553,151 @@ func (s *DatabaseService) UpsertBlockBuilderEntryAfterSubmission(lastSubmission
 }
 
 func (s *DatabaseService) GetBlockBuilders() ([]*BlockBuilderEntry, error) {
-	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` ORDER BY id ASC;`
+	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, blacklisted_until, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` ORDER BY id ASC;`
 	entries := []*BlockBuilderEntry{}
 	err := s.DB.Select(&entries, query)
 	return entries, err
 }
 
+// GetFilteredBlockBuilders returns the builders matching the filters, ordered by pubkey
+func (s *DatabaseService) GetFilteredBlockBuilders(filters GetBlockBuildersFilters) ([]*BlockBuilderEntry, error) {
+	arg := map[string]interface{}{
+		"builder_id":    filters.BuilderID,
+		"is_optimistic": filters.IsOptimistic.Bool,
+		"cursor":        filters.Cursor,
+	}
+
+	whereConds := []string{}
+	if filters.BuilderID != "" {
+		whereConds = append(whereConds, "builder_id = :builder_id")
+	}
+	if filters.IsOptimistic.Valid {
+		whereConds = append(whereConds, "is_optimistic = :is_optimistic")
+	}
+	if filters.Cursor != "" {
+		whereConds = append(whereConds, "builder_pubkey > :cursor")
+	}
+
+	where := ""
+	if len(whereConds) > 0 {
+		where = "WHERE " + strings.Join(whereConds, " AND ")
+	}
+	limit := ""
+	if filters.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", filters.Limit)
+	}
+
+	query, args, err := sqlx.Named(`SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, blacklisted_until, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM `+vars.TableBlockBuilder+` `+where+` ORDER BY builder_pubkey ASC `+limit, arg)
+	if err != nil {
+		return nil, err
+	}
+	entries := []*BlockBuilderEntry{}
+	err = s.DB.Select(&entries, s.DB.Rebind(query), args...)
+	return entries, err
+}
+
+// GetBuilderStats returns the statistics of all builders (optionally only those of one builder_id), with the
+// window statistics computed over the slots starting at filters.SlotFrom.
+func (s *DatabaseService) GetBuilderStats(filters GetBuilderStatsFilters) (entries []*BuilderStatsEntry, err error) {
+	args := []interface{}{filters.BuilderID, filters.SlotFrom}
+	cursor := ""
+	if filters.CursorID > 0 {
+		cursor = `AND (COALESCE(p.value_delivered, 0) < $3::numeric OR (COALESCE(p.value_delivered, 0) = $3::numeric AND b.id > $4))`
+		args = append(args, filters.CursorValueDelivered, filters.CursorID)
+	}
+	limit := ""
+	if filters.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", filters.Limit)
+	}
+
+	query := `SELECT b.id, b.builder_pubkey, b.builder_id, b.description, b.is_high_prio, b.is_optimistic,
+			b.is_blacklisted AND (b.blacklisted_until IS NULL OR b.blacklisted_until > now()) AS is_blacklisted,
+			b.num_submissions_total, b.num_submissions_simerror, b.num_sent_getpayload,
+			COALESCE(s.num_slots_bid, 0) AS num_slots_bid,
+			COALESCE(s.median_total_duration, 0) AS median_total_duration,
+			COALESCE(p.num_slots_delivered, 0) AS num_slots_delivered,
+			COALESCE(p.value_delivered, 0)::text AS value_delivered
+		FROM ` + vars.TableBlockBuilder + ` b
+		LEFT JOIN (
+			SELECT builder_pubkey, COUNT(DISTINCT slot) AS num_slots_bid, percentile_cont(0.5) WITHIN GROUP (ORDER BY total_duration)::bigint AS median_total_duration
+			FROM ` + vars.TableBuilderBlockSubmission + `
+			WHERE slot >= $2
+			GROUP BY builder_pubkey
+		) s ON s.builder_pubkey = b.builder_pubkey
+		LEFT JOIN (
+			SELECT builder_pubkey, COUNT(*) AS num_slots_delivered, SUM(value) AS value_delivered
+			FROM ` + vars.TableDeliveredPayload + `
+			WHERE slot >= $2
+			GROUP BY builder_pubkey
+		) p ON p.builder_pubkey = b.builder_pubkey
+		WHERE ($1 = '' OR b.builder_id = $1) ` + cursor + `
+		ORDER BY COALESCE(p.value_delivered, 0) DESC, b.id ASC ` + limit
+	err = s.DB.Select(&entries, query, args...)
+	return entries, err
+}
+
 func (s *DatabaseService) GetBlockBuilderByPubkey(pubkey string) (*BlockBuilderEntry, error) {
-	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` WHERE builder_pubkey=$1;`
+	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, blacklisted_until, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` WHERE builder_pubkey=$1;`
 	entry := &BlockBuilderEntry{}
 	err := s.DB.Get(entry, query, pubkey)
 	return entry, err
 }
 
+// SetBlockBuilderStatus sets the status flags of a builder. The expiry of a blacklist is kept while it stays
+// blacklisted, blacklisting it again after the expiry makes the blacklist permanent.
 func (s *DatabaseService) SetBlockBuilderStatus(pubkey string, status common.BuilderStatus) error {
-	query := `UPDATE ` + vars.TableBlockBuilder + ` SET is_high_prio=$1, is_blacklisted=$2, is_optimistic=$3 WHERE builder_pubkey=$4;`
+	query := `UPDATE ` + vars.TableBlockBuilder + ` SET is_high_prio=$1, is_blacklisted=$2, is_optimistic=$3,
+			blacklisted_until=CASE WHEN $2 AND is_blacklisted AND blacklisted_until > now() THEN blacklisted_until ELSE NULL END
+		WHERE builder_pubkey=$4;`
 	_, err := s.DB.Exec(query, status.IsHighPrio, status.IsBlacklisted, status.IsOptimistic, pubkey)
 	return err
 }
 
+// UpdateBlockBuilders applies the update to all the given builder pubkeys
+func (s *DatabaseService) UpdateBlockBuilders(pubkeys []string, update BlockBuilderUpdate) error {
+	arg := map[string]interface{}{
+		"pubkeys":           pubkeys,
+		"is_high_prio":      update.IsHighPrio,
+		"is_blacklisted":    update.IsBlacklisted,
+		"blacklisted_until": update.BlacklistedUntil,
+		"is_optimistic":     update.IsOptimistic,
+		"description":       update.Description,
+	}
+
+	sets := []string{}
+	if update.IsHighPrio != nil {
+		sets = append(sets, "is_high_prio=:is_high_prio")
+	}
+	if update.IsBlacklisted != nil {
+		sets = append(sets, "is_blacklisted=:is_blacklisted")
+		if *update.IsBlacklisted {
+			sets = append(sets, "blacklisted_until=:blacklisted_until")
+		} else {
+			sets = append(sets, "blacklisted_until=NULL")
+		}
+	}
+	if update.IsOptimistic != nil {
+		sets = append(sets, "is_optimistic=:is_optimistic")
+	}
+	if update.Description != nil {
+		sets = append(sets, "description=:description")
+	}
+	if len(sets) == 0 || len(pubkeys) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.Named(`UPDATE `+vars.TableBlockBuilder+` SET `+strings.Join(sets, ", ")+` WHERE builder_pubkey IN (:pubkeys);`, arg)
+	if err != nil {
+		return err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.Exec(s.DB.Rebind(query), args...)
+	return err
+}
+
 func (s *DatabaseService) SetBlockBuilderIDStatusIsOptimistic(pubkey string, isOptimistic bool) error {
 	builder, err := s.GetBlockBuilderByPubkey(pubkey)
 	if err != nil {
@@ -584,7 +785,7 @@ func (s *DatabaseService) UpdateBuilderDemotion(trace *common.BidTraceV2, signed
 }
 
 func (s *DatabaseService) GetBuilderDemotion(trace *common.BidTraceV2) (*BuilderDemotionEntry, error) {
-	query := `SELECT submit_block_request, signed_beacon_block, signed_validator_registration, epoch, slot, builder_pubkey, proposer_pubkey, value, fee_recipient, block_hash, sim_error FROM ` + vars.TableBuilderDemotions + `
+	query := `SELECT submit_block_request, signed_beacon_block, signed_validator_registration, epoch, slot, builder_pubkey, proposer_pubkey, value, fee_recipient, block_hash, sim_error, review_status, review_notes, reviewed_at FROM ` + vars.TableBuilderDemotions + `
 	WHERE slot=$1 AND builder_pubkey=$2 AND block_hash=$3`
 	entry := &BuilderDemotionEntry{}
 	err := s.DB.Get(entry, query, trace.Slot, trace.BuilderPubkey.String(), trace.BlockHash.String())
@@ -594,12 +795,88 @@ func (s *DatabaseService) GetBuilderDemotion(trace *common.BidTraceV2) (*Builder
 	return entry, nil
 }
 
+// GetBuilderDemotions returns the demotions in a slot range (optionally only for one builder),
+// including whether the demoted block was delivered to the proposer
+func (s *DatabaseService) GetBuilderDemotions(filters GetBuilderDemotionsFilters) (entries []*BuilderDemotionEntry, err error) {
+	args := []interface{}{filters.SlotFrom, filters.SlotTo, filters.BuilderPubkey}
+	cursor := ""
+	if filters.CursorID > 0 {
+		cursor = `AND (d.slot, d.id) > ($4, $5)`
+		args = append(args, filters.CursorSlot, filters.CursorID)
+	}
+	limit := ""
+	if filters.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", filters.Limit)
+	}
+
+	query := `SELECT d.id, d.inserted_at, d.submit_block_request, d.signed_beacon_block, d.signed_validator_registration, d.epoch, d.slot, d.builder_pubkey, d.proposer_pubkey, d.value, d.fee_recipient, d.block_hash, d.sim_error, d.review_status, d.review_notes, d.reviewed_at,
+		EXISTS (SELECT 1 FROM ` + vars.TableDeliveredPayload + ` p WHERE p.slot = d.slot AND p.block_hash = d.block_hash) AS was_delivered
+	FROM ` + vars.TableBuilderDemotions + ` d
+	WHERE d.slot >= $1 AND d.slot <= $2 AND ($3 = '' OR d.builder_pubkey = $3) ` + cursor + `
+	ORDER BY d.slot ASC, d.id ASC ` + limit
+	err = s.DB.Select(&entries, query, args...)
+	return entries, err
+}
+
+func (s *DatabaseService) GetBuilderDemotionByID(id int64) (*BuilderDemotionEntry, error) {
+	query := `SELECT d.id, d.inserted_at, d.submit_block_request, d.signed_beacon_block, d.signed_validator_registration, d.epoch, d.slot, d.builder_pubkey, d.proposer_pubkey, d.value, d.fee_recipient, d.block_hash, d.sim_error, d.review_status, d.review_notes, d.reviewed_at,
+		EXISTS (SELECT 1 FROM ` + vars.TableDeliveredPayload + ` p WHERE p.slot = d.slot AND p.block_hash = d.block_hash) AS was_delivered
+	FROM ` + vars.TableBuilderDemotions + ` d
+	WHERE d.id=$1`
+	entry := &BuilderDemotionEntry{}
+	err := s.DB.Get(entry, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ResolveBuilderDemotion records the outcome of an operator's review of a demotion
+func (s *DatabaseService) ResolveBuilderDemotion(id int64, reviewStatus, reviewNotes string) error {
+	query := `UPDATE ` + vars.TableBuilderDemotions + ` SET review_status=$1, review_notes=$2, reviewed_at=now() WHERE id=$3;`
+	_, err := s.DB.Exec(query, reviewStatus, reviewNotes, id)
+	return err
+}
+
+// GetBuilderDemotionReviewCounts returns the number of demotions per review status, for
+// the given builder pubkey and all other pubkeys with the same builder id
+func (s *DatabaseService) GetBuilderDemotionReviewCounts(pubkey string) (map[string]uint64, error) {
+	query := `SELECT review_status, COUNT(*) AS num FROM ` + vars.TableBuilderDemotions + `
+	WHERE builder_pubkey=$1 OR builder_pubkey IN (
+		SELECT builder_pubkey FROM ` + vars.TableBlockBuilder + ` WHERE builder_id != '' AND builder_id = (SELECT builder_id FROM ` + vars.TableBlockBuilder + ` WHERE builder_pubkey=$1)
+	)
+	GROUP BY review_status`
+	rows := []struct {
+		ReviewStatus string `db:"review_status"`
+		Num          uint64 `db:"num"`
+	}{}
+	if err := s.DB.Select(&rows, query, pubkey); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]uint64)
+	for _, row := range rows {
+		counts[row.ReviewStatus] = row.Num
+	}
+	return counts, nil
+}
+
 func (s *DatabaseService) GetTooLateGetPayload(slot uint64) (entries []*TooLateGetPayloadEntry, err error) {
 	query := `SELECT id, inserted_at, slot, slot_start_timestamp, request_timestamp, decode_timestamp, proposer_pubkey, block_hash, ms_into_slot FROM ` + vars.TableTooLateGetPayload + ` WHERE slot = $1`
 	err = s.DB.Select(&entries, query, slot)
 	return entries, err
 }
 
+// GetTooLateGetPayloads returns the too late getPayload calls in an inclusive slot range (optionally only for one proposer), newest first
+func (s *DatabaseService) GetTooLateGetPayloads(slotFrom, slotTo uint64, proposerPubkey string, limit uint64) (entries []*TooLateGetPayloadEntry, err error) {
+	query := `SELECT id, inserted_at, slot, slot_start_timestamp, request_timestamp, decode_timestamp, proposer_pubkey, block_hash, ms_into_slot
+		FROM ` + vars.TableTooLateGetPayload + `
+		WHERE slot >= $1 AND slot <= $2 AND ($3 = '' OR proposer_pubkey = $3)
+		ORDER BY slot DESC, id DESC
+		LIMIT $4`
+	err = s.DB.Select(&entries, query, slotFrom, slotTo, proposerPubkey, limit)
+	return entries, err
+}
+
 func (s *DatabaseService) InsertTooLateGetPayload(slot uint64, proposerPubkey, blockHash string, slotStart, requestTime, decodeTime, msIntoSlot uint64) error {
 	entry := TooLateGetPayloadEntry{
 		Slot:               slot,
@@ -618,3 +895,139 @@ func (s *DatabaseService) InsertTooLateGetPayload(slot uint64, proposerPubkey, b
 	_, err := s.DB.NamedExec(query, entry)
 	return err
 }
+
+// SaveSlotAuctionSummary inserts the summary of a slot, replacing an earlier one
+func (s *DatabaseService) SaveSlotAuctionSummary(entry *SlotAuctionSummaryEntry) error {
+	query := `INSERT INTO ` + vars.TableSlotAuctionSummary + `
+		(slot, epoch, parent_hash, proposer_pubkey, num_bids, num_builders, top_bid_timeline, floor_value, winning_builder_pubkey, winning_block_hash, winning_value, runner_up_builder_pubkey, runner_up_value, margin, getpayload_called, getpayload_too_late, getpayload_ms_into_slot, publish_ms) VALUES
+		(:slot, :epoch, :parent_hash, :proposer_pubkey, :num_bids, :num_builders, :top_bid_timeline, :floor_value, :winning_builder_pubkey, :winning_block_hash, :winning_value, :runner_up_builder_pubkey, :runner_up_value, :margin, :getpayload_called, :getpayload_too_late, :getpayload_ms_into_slot, :publish_ms)
+		ON CONFLICT (slot) DO UPDATE SET
+			inserted_at = current_timestamp,
+			parent_hash = EXCLUDED.parent_hash,
+			proposer_pubkey = EXCLUDED.proposer_pubkey,
+			num_bids = EXCLUDED.num_bids,
+			num_builders = EXCLUDED.num_builders,
+			top_bid_timeline = EXCLUDED.top_bid_timeline,
+			floor_value = EXCLUDED.floor_value,
+			winning_builder_pubkey = EXCLUDED.winning_builder_pubkey,
+			winning_block_hash = EXCLUDED.winning_block_hash,
+			winning_value = EXCLUDED.winning_value,
+			runner_up_builder_pubkey = EXCLUDED.runner_up_builder_pubkey,
+			runner_up_value = EXCLUDED.runner_up_value,
+			margin = EXCLUDED.margin,
+			getpayload_called = EXCLUDED.getpayload_called,
+			getpayload_too_late = EXCLUDED.getpayload_too_late,
+			getpayload_ms_into_slot = EXCLUDED.getpayload_ms_into_slot,
+			publish_ms = EXCLUDED.publish_ms;`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
+
+// GetSlotAuctionSummaries returns the auction summaries in an inclusive slot range, newest first
+func (s *DatabaseService) GetSlotAuctionSummaries(slotFrom, slotTo uint64) (entries []*SlotAuctionSummaryEntry, err error) {
+	query := `SELECT id, inserted_at, slot, epoch, parent_hash, proposer_pubkey, num_bids, num_builders, top_bid_timeline, floor_value, winning_builder_pubkey, winning_block_hash, winning_value, runner_up_builder_pubkey, runner_up_value, margin, getpayload_called, getpayload_too_late, getpayload_ms_into_slot, publish_ms
+		FROM ` + vars.TableSlotAuctionSummary + `
+		WHERE slot >= $1 AND slot <= $2
+		ORDER BY slot DESC;`
+	err = s.DB.Select(&entries, query, slotFrom, slotTo)
+	return entries, err
+}
+
+// InsertDataAPIKey stores a new data API key, and sets its id and insertion time
+func (s *DatabaseService) InsertDataAPIKey(entry *DataAPIKeyEntry) error {
+	query := `INSERT INTO ` + vars.TableDataAPIKey + `
+		(key_hash, description, quota_per_min) VALUES
+		($1, $2, $3)
+		RETURNING id, inserted_at`
+	return s.DB.QueryRow(query, entry.KeyHash, entry.Description, entry.QuotaPerMin).Scan(&entry.ID, &entry.InsertedAt)
+}
+
+func (s *DatabaseService) GetDataAPIKeyByHash(keyHash string) (*DataAPIKeyEntry, error) {
+	query := `SELECT id, inserted_at, key_hash, description, quota_per_min, revoked_at FROM ` + vars.TableDataAPIKey + ` WHERE key_hash=$1`
+	entry := &DataAPIKeyEntry{}
+	err := s.DB.Get(entry, query, keyHash)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// GetDataAPIKeys returns all data API keys, including revoked ones
+func (s *DatabaseService) GetDataAPIKeys() (entries []*DataAPIKeyEntry, err error) {
+	query := `SELECT id, inserted_at, key_hash, description, quota_per_min, revoked_at FROM ` + vars.TableDataAPIKey + ` ORDER BY id ASC`
+	err = s.DB.Select(&entries, query)
+	return entries, err
+}
+
+// RevokeDataAPIKey revokes a data API key and returns it, returns sql.ErrNoRows if there's no such key
+func (s *DatabaseService) RevokeDataAPIKey(id int64) (*DataAPIKeyEntry, error) {
+	query := `UPDATE ` + vars.TableDataAPIKey + ` SET revoked_at=COALESCE(revoked_at, now()) WHERE id=$1
+		RETURNING id, inserted_at, key_hash, description, quota_per_min, revoked_at`
+	entry := &DataAPIKeyEntry{}
+	err := s.DB.Get(entry, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// InsertInternalAPIAudit stores an audit log entry, and sets its id and insertion time
+func (s *DatabaseService) InsertInternalAPIAudit(entry *InternalAPIAuditEntry) error {
+	query := `INSERT INTO ` + vars.TableInternalAPIAudit + `
+		(actor, remote_ip, remote_addr, method, path, action, target, old_value, new_value) VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, inserted_at`
+	return s.DB.QueryRow(query, entry.Actor, entry.RemoteIP, entry.RemoteAddr, entry.Method, entry.Path, entry.Action, entry.Target, entry.OldValue, entry.NewValue).Scan(&entry.ID, &entry.InsertedAt)
+}
+
+// GetInternalAPIAudit returns the audit log entries matching the filters, newest first
+func (s *DatabaseService) GetInternalAPIAudit(filters GetInternalAPIAuditFilters) ([]*InternalAPIAuditEntry, error) {
+	arg := map[string]interface{}{
+		"actor":  filters.Actor,
+		"action": filters.Action,
+		"target": filters.Target,
+		"cursor": filters.Cursor,
+		"limit":  filters.Limit,
+	}
+
+	whereConds := []string{}
+	if filters.Actor != "" {
+		whereConds = append(whereConds, "actor = :actor")
+	}
+	if filters.Action != "" {
+		whereConds = append(whereConds, "action = :action")
+	}
+	if filters.Target != "" {
+		whereConds = append(whereConds, "target = :target")
+	}
+	if filters.Cursor > 0 {
+		whereConds = append(whereConds, "id <= :cursor")
+	}
+
+	where := ""
+	if len(whereConds) > 0 {
+		where = "WHERE " + strings.Join(whereConds, " AND ")
+	}
+	limit := ""
+	if filters.Limit > 0 {
+		limit = "LIMIT :limit"
+	}
+
+	fields := "id, inserted_at, actor, remote_ip, remote_addr, method, path, action, target, old_value, new_value"
+	query := fmt.Sprintf("SELECT %s FROM %s %s ORDER BY id DESC %s", fields, vars.TableInternalAPIAudit, where, limit)
+
+	entries := []*InternalAPIAuditEntry{}
+	rows, err := s.DB.NamedQuery(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		entry := new(InternalAPIAuditEntry)
+		if err = rows.StructScan(entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
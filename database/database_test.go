@@ -4,10 +4,12 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"sort"
 	"testing"
 	"time"
 
 	v1 "github.com/attestantio/go-builder-client/api/v1"
+	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
@@ -42,6 +44,7 @@ var (
 		Simulation:  44,
 		RedisUpdate: 45,
 		Total:       46,
+		SimCacheHit: true,
 	}
 	errFoo = fmt.Errorf("fake simulation error")
 )
@@ -288,6 +291,88 @@ func TestSetBlockBuilderCollateral(t *testing.T) {
 	require.Equal(t, collateralStr, builder.Collateral)
 }
 
+func TestUpdateBlockBuilders(t *testing.T) {
+	db := resetDatabase(t)
+	pubkey1 := insertTestBuilder(t, db)
+	pubkey2 := insertTestBuilder(t, db)
+	pubkey3 := insertTestBuilder(t, db)
+
+	isTrue := true
+	description := "test builder"
+	err := db.UpdateBlockBuilders([]string{pubkey1, pubkey2}, BlockBuilderUpdate{ //nolint:exhaustruct
+		IsHighPrio:       &isTrue,
+		IsBlacklisted:    &isTrue,
+		BlacklistedUntil: NewNullTime(time.Now().Add(time.Hour)),
+		Description:      &description,
+	})
+	require.NoError(t, err)
+
+	for _, pubkey := range []string{pubkey1, pubkey2} {
+		builder, err := db.GetBlockBuilderByPubkey(pubkey)
+		require.NoError(t, err)
+		require.True(t, builder.IsHighPrio)
+		require.True(t, builder.IsBlacklistedAt(time.Now()))
+		require.False(t, builder.IsBlacklistedAt(time.Now().Add(2*time.Hour)))
+		require.Equal(t, description, builder.Description)
+		require.False(t, builder.IsOptimistic)
+	}
+	builder, err := db.GetBlockBuilderByPubkey(pubkey3)
+	require.NoError(t, err)
+	require.False(t, builder.IsHighPrio)
+	require.False(t, builder.IsBlacklisted)
+	require.Equal(t, "", builder.Description)
+
+	// Changing other flags keeps the expiry of a blacklist
+	err = db.SetBlockBuilderStatus(pubkey1, common.BuilderStatus{IsBlacklisted: true, IsOptimistic: true})
+	require.NoError(t, err)
+	builder, err = db.GetBlockBuilderByPubkey(pubkey1)
+	require.NoError(t, err)
+	require.True(t, builder.BlacklistedUntil.Valid)
+
+	// Builder stats only show active blacklists
+	err = db.UpdateBlockBuilders([]string{pubkey2}, BlockBuilderUpdate{ //nolint:exhaustruct
+		IsBlacklisted:    &isTrue,
+		BlacklistedUntil: NewNullTime(time.Now().Add(-time.Hour)),
+	})
+	require.NoError(t, err)
+	stats, err := db.GetBuilderStats(GetBuilderStatsFilters{BuilderID: "", SlotFrom: 0}) //nolint:exhaustruct
+	require.NoError(t, err)
+	for _, entry := range stats {
+		require.Equal(t, entry.BuilderPubkey == pubkey1, entry.IsBlacklisted, entry.BuilderPubkey)
+	}
+
+	// Statistics can be paged through in their order
+	require.Len(t, stats, 3)
+	statsPage, err := db.GetBuilderStats(GetBuilderStatsFilters{CursorValueDelivered: stats[0].ValueDelivered, CursorID: stats[0].ID, Limit: 1}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, statsPage, 1)
+	require.Equal(t, stats[1].BuilderPubkey, statsPage[0].BuilderPubkey)
+
+	// Builders can be filtered and paged through by pubkey
+	pubkeys := []string{pubkey1, pubkey2, pubkey3}
+	sort.Strings(pubkeys)
+	page, err := db.GetFilteredBlockBuilders(GetBlockBuildersFilters{Limit: 2}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, pubkeys[:2], []string{page[0].BuilderPubkey, page[1].BuilderPubkey})
+	page, err = db.GetFilteredBlockBuilders(GetBlockBuildersFilters{Cursor: page[1].BuilderPubkey, Limit: 2}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, pubkeys[2], page[0].BuilderPubkey)
+	page, err = db.GetFilteredBlockBuilders(GetBlockBuildersFilters{IsOptimistic: NewNullBool(true)}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, pubkey1, page[0].BuilderPubkey)
+
+	// Blacklisting again after the expiry is permanent
+	err = db.SetBlockBuilderStatus(pubkey2, common.BuilderStatus{IsBlacklisted: true})
+	require.NoError(t, err)
+	builder, err = db.GetBlockBuilderByPubkey(pubkey2)
+	require.NoError(t, err)
+	require.False(t, builder.BlacklistedUntil.Valid)
+	require.True(t, builder.IsBlacklistedAt(time.Now().Add(24*time.Hour)))
+}
+
 func TestInsertBuilderDemotion(t *testing.T) {
 	db := resetDatabase(t)
 	pk, sk := getTestKeyPair(t)
@@ -369,6 +454,151 @@ func TestUpdateBuilderDemotion(t *testing.T) {
 	require.NotEmpty(t, demotion.SignedValidatorRegistration.String)
 }
 
+func TestGetBuilderDemotions(t *testing.T) {
+	db := resetDatabase(t)
+
+	pk, sk := getTestKeyPair(t)
+	var testBlockHash phase0.Hash32
+	hashSlice, err := hexutil.Decode(blockHashStr)
+	require.NoError(t, err)
+	copy(testBlockHash[:], hashSlice)
+	bt := &common.BidTraceV2{
+		BidTrace: v1.BidTrace{
+			BlockHash:            testBlockHash,
+			Slot:                 slot,
+			BuilderPubkey:        *pk,
+			ProposerPubkey:       *pk,
+			ProposerFeeRecipient: feeRecipient,
+			Value:                uint256.NewInt(collateral),
+		},
+	}
+	req := common.TestBuilderSubmitBlockRequest(sk, bt)
+	err = db.InsertBuilderDemotion(&req, errFoo)
+	require.NoError(t, err)
+
+	// Outside of the slot range or for another builder
+	entries, err := db.GetBuilderDemotions(GetBuilderDemotionsFilters{SlotFrom: slot + 1, SlotTo: slot + 10, BuilderPubkey: ""}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Empty(t, entries)
+	entries, err = db.GetBuilderDemotions(GetBuilderDemotionsFilters{SlotFrom: slot, SlotTo: slot, BuilderPubkey: "0x1234"}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	entries, err = db.GetBuilderDemotions(GetBuilderDemotionsFilters{SlotFrom: slot, SlotTo: slot, BuilderPubkey: pk.String()}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, blockHashStr, entries[0].BlockHash)
+	require.False(t, entries[0].WasDelivered)
+
+	// Once the block is delivered, the demotion is marked as such
+	err = db.SaveDeliveredPayload(bt, &common.SignedBlindedBeaconBlock{Bellatrix: &types.SignedBlindedBeaconBlock{}}, time.Now(), 1)
+	require.NoError(t, err)
+	entries, err = db.GetBuilderDemotions(GetBuilderDemotionsFilters{SlotFrom: slot, SlotTo: slot, BuilderPubkey: ""}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].WasDelivered)
+	demotion, err := db.GetBuilderDemotionByID(entries[0].ID)
+	require.NoError(t, err)
+	require.True(t, demotion.WasDelivered)
+
+	// Nothing comes after the last demotion
+	entries, err = db.GetBuilderDemotions(GetBuilderDemotionsFilters{SlotFrom: slot, SlotTo: slot, CursorSlot: slot, CursorID: demotion.ID, Limit: 10}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestResolveBuilderDemotion(t *testing.T) {
+	db := resetDatabase(t)
+	pkStr := insertTestBuilder(t, db)
+	err := db.SetBlockBuilderCollateral(pkStr, "builder0x69", "10000")
+	require.NoError(t, err)
+
+	pk, sk := getTestKeyPair(t)
+	var testBlockHash phase0.Hash32
+	hashSlice, err := hexutil.Decode(blockHashStr)
+	require.NoError(t, err)
+	copy(testBlockHash[:], hashSlice)
+	bt := &common.BidTraceV2{
+		BidTrace: v1.BidTrace{
+			BlockHash:            testBlockHash,
+			Slot:                 slot,
+			BuilderPubkey:        *pk,
+			ProposerPubkey:       *pk,
+			ProposerFeeRecipient: feeRecipient,
+			Value:                uint256.NewInt(collateral),
+		},
+	}
+	req := common.TestBuilderSubmitBlockRequest(sk, bt)
+	submission, err := db.SaveBuilderBlockSubmission(&req, nil, nil, time.Now(), time.Now(), true, false, profile, optimisticSubmission)
+	require.NoError(t, err)
+	err = db.UpsertBlockBuilderEntryAfterSubmission(submission, false)
+	require.NoError(t, err)
+	err = db.InsertBuilderDemotion(&req, errFoo)
+	require.NoError(t, err)
+
+	// The demotion is open, and counted for all pubkeys of the builder id
+	counts, err := db.GetBuilderDemotionReviewCounts(pk.String())
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), counts[DemotionReviewOpen])
+	err = db.SetBlockBuilderCollateral(pk.String(), "builder0x69", "10000")
+	require.NoError(t, err)
+	counts, err = db.GetBuilderDemotionReviewCounts(pkStr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), counts[DemotionReviewOpen])
+
+	entries, err := db.GetBuilderDemotions(GetBuilderDemotionsFilters{SlotFrom: slot, SlotTo: slot, BuilderPubkey: pk.String()}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	err = db.ResolveBuilderDemotion(entries[0].ID, DemotionReviewRefunded, "refund paid")
+	require.NoError(t, err)
+
+	demotion, err := db.GetBuilderDemotionByID(entries[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, DemotionReviewRefunded, demotion.ReviewStatus)
+	require.Equal(t, "refund paid", demotion.ReviewNotes)
+	require.True(t, demotion.ReviewedAt.Valid)
+
+	counts, err = db.GetBuilderDemotionReviewCounts(pk.String())
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), counts[DemotionReviewOpen])
+	require.Equal(t, uint64(1), counts[DemotionReviewRefunded])
+}
+
+func TestGetValidatorRegistrationHistory(t *testing.T) {
+	db := resetDatabase(t)
+	pubkey := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+	for i := 0; i < 3; i++ {
+		err := db.SaveValidatorRegistration(ValidatorRegistrationEntry{
+			Pubkey:       pubkey,
+			FeeRecipient: fmt.Sprintf("0x%040d", i),
+			Timestamp:    uint64(1690000000 + i),
+			GasLimit:     30000000,
+			Signature:    "0xab",
+		})
+		require.NoError(t, err)
+	}
+
+	// unchanged fee recipient and gas limit are not stored
+	err := db.SaveValidatorRegistration(ValidatorRegistrationEntry{
+		Pubkey:       pubkey,
+		FeeRecipient: fmt.Sprintf("0x%040d", 2),
+		Timestamp:    uint64(1690000010),
+		GasLimit:     30000000,
+		Signature:    "0xab",
+	})
+	require.NoError(t, err)
+
+	entries, err := db.GetValidatorRegistrationHistory(pubkey, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, uint64(1690000002), entries[0].Timestamp)
+	require.Equal(t, uint64(1690000000), entries[2].Timestamp)
+
+	entries, err = db.GetValidatorRegistrationHistory(pubkey, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
 func TestGetBlockSubmissionEntry(t *testing.T) {
 	db := resetDatabase(t)
 	pubkey := insertTestBuilder(t, db)
@@ -381,6 +611,7 @@ func TestGetBlockSubmissionEntry(t *testing.T) {
 	require.Equal(t, profile.Simulation, entry.SimulationDuration)
 	require.Equal(t, profile.RedisUpdate, entry.RedisUpdateDuration)
 	require.Equal(t, profile.Total, entry.TotalDuration)
+	require.Equal(t, profile.SimCacheHit, entry.SimCacheHit)
 
 	require.True(t, entry.OptimisticSubmission)
 	require.True(t, entry.EligibleAt.Valid)
@@ -403,6 +634,107 @@ func TestGetBuilderSubmissions(t *testing.T) {
 	require.Equal(t, fmt.Sprint(collateral), e.Value)
 }
 
+func TestGetBuilderSubmissionsCursor(t *testing.T) {
+	db := resetDatabase(t)
+	pk, sk := getTestKeyPair(t)
+	for i := 0; i < 3; i++ {
+		req := common.TestBuilderSubmitBlockRequest(sk, &common.BidTraceV2{
+			BidTrace: v1.BidTrace{
+				Slot:                 slot + uint64(i),
+				BuilderPubkey:        *pk,
+				ProposerPubkey:       *pk,
+				ProposerFeeRecipient: feeRecipient,
+				Value:                uint256.NewInt(collateral),
+			},
+		})
+		_, err := db.SaveBuilderBlockSubmission(&req, nil, nil, time.Now(), time.Now(), true, false, profile, false)
+		require.NoError(t, err)
+	}
+	pubkey := pk.String()
+
+	entries, err := db.GetBuilderSubmissions(GetBuilderSubmissionsFilters{
+		BuilderPubkey: pubkey,
+		Limit:         2,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Greater(t, entries[0].ID, entries[1].ID)
+	require.Equal(t, slot+2, entries[0].Slot)
+
+	entries2, err := db.GetBuilderSubmissions(GetBuilderSubmissionsFilters{
+		BuilderPubkey: pubkey,
+		Cursor:        uint64(entries[1].ID - 1),
+		Limit:         2,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries2, 1)
+	require.Equal(t, slot, entries2[0].Slot)
+}
+
+func TestGetBuilderSubmissionsRange(t *testing.T) {
+	db := resetDatabase(t)
+	pk, sk := getTestKeyPair(t)
+	receivedAt := time.Now().Truncate(time.Second)
+	for i := 0; i < 3; i++ {
+		req := common.TestBuilderSubmitBlockRequest(sk, &common.BidTraceV2{
+			BidTrace: v1.BidTrace{
+				Slot:                 slot + uint64(i),
+				BuilderPubkey:        *pk,
+				ProposerPubkey:       *pk,
+				ProposerFeeRecipient: feeRecipient,
+				Value:                uint256.NewInt(collateral),
+			},
+		})
+		_, err := db.SaveBuilderBlockSubmission(&req, nil, nil, receivedAt.Add(time.Duration(i)*time.Minute), time.Now(), true, false, profile, false)
+		require.NoError(t, err)
+	}
+
+	entries, err := db.GetBuilderSubmissions(GetBuilderSubmissionsFilters{
+		SlotFrom: slot + 1,
+		SlotTo:   slot + 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	entries, err = db.GetBuilderSubmissions(GetBuilderSubmissionsFilters{
+		BuilderPubkey:  pk.String(),
+		ReceivedAfter:  receivedAt,
+		ReceivedBefore: receivedAt.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, slot, entries[0].Slot)
+}
+
+func TestGetBuilderStats(t *testing.T) {
+	db := resetDatabase(t)
+	pubkey := insertTestBuilder(t, db)
+	err := db.SetBlockBuilderCollateral(pubkey, "builder-a", "1")
+	require.NoError(t, err)
+
+	entries, err := db.GetBuilderStats(GetBuilderStatsFilters{BuilderID: "", SlotFrom: 0}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	require.Equal(t, pubkey, entry.BuilderPubkey)
+	require.Equal(t, "builder-a", entry.BuilderID)
+	require.Equal(t, uint64(1), entry.NumSubmissionsTotal)
+	require.Equal(t, uint64(1), entry.NumSlotsBid)
+	require.Equal(t, uint64(0), entry.NumSlotsDelivered)
+	require.Equal(t, "0", entry.ValueDelivered)
+	require.Equal(t, profile.Total, entry.MedianTotalDuration)
+
+	// the window starts after the submission
+	entries, err = db.GetBuilderStats(GetBuilderStatsFilters{BuilderID: "builder-a", SlotFrom: slot + 1}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, uint64(0), entries[0].NumSlotsBid)
+
+	entries, err = db.GetBuilderStats(GetBuilderStatsFilters{BuilderID: "builder-b", SlotFrom: 0}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, entries, 0)
+}
+
 func TestUpsertTooLateGetPayload(t *testing.T) {
 	db := resetDatabase(t)
 	slot := uint64(12345)
@@ -440,3 +772,189 @@ func TestUpsertTooLateGetPayload(t *testing.T) {
 	entry = entries[1]
 	require.Equal(t, hash2, entry.BlockHash)
 }
+
+func TestSlotAuctionSummary(t *testing.T) {
+	db := resetDatabase(t)
+	summary := &SlotAuctionSummaryEntry{
+		Slot:                  slot,
+		Epoch:                 slot / common.SlotsPerEpoch,
+		ParentHash:            blockHashStr,
+		ProposerPubkey:        "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908",
+		NumBids:               10,
+		NumBuilders:           2,
+		TopBidTimeline:        `[{"timestamp_ms":"1","builder_pubkey":"0x01","block_hash":"0x02","value":"3"}]`,
+		FloorValue:            "1",
+		WinningBuilderPubkey:  "0x01",
+		WinningBlockHash:      "0x02",
+		WinningValue:          "3",
+		RunnerUpBuilderPubkey: "0x03",
+		RunnerUpValue:         "2",
+		Margin:                "1",
+		GetPayloadCalled:      true,
+		GetPayloadMsIntoSlot:  1200,
+		PublishMs:             100,
+	}
+	err := db.SaveSlotAuctionSummary(summary)
+	require.NoError(t, err)
+
+	// saving again replaces the summary
+	summary.NumBids = 11
+	err = db.SaveSlotAuctionSummary(summary)
+	require.NoError(t, err)
+
+	entries, err := db.GetSlotAuctionSummaries(slot, slot)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	require.Equal(t, uint64(11), entry.NumBids)
+	require.Equal(t, "3", entry.WinningValue)
+	require.Equal(t, "1", entry.Margin)
+	require.JSONEq(t, summary.TopBidTimeline, entry.TopBidTimeline)
+	require.True(t, entry.GetPayloadCalled)
+	require.False(t, entry.GetPayloadTooLate)
+
+	entries, err = db.GetSlotAuctionSummaries(slot+1, slot+10)
+	require.NoError(t, err)
+	require.Len(t, entries, 0)
+}
+
+func TestGetDeliveredPayloadByBlockHash(t *testing.T) {
+	db := resetDatabase(t)
+	pk, _ := getTestKeyPair(t)
+	var testBlockHash phase0.Hash32
+	hashSlice, err := hexutil.Decode(blockHashStr)
+	require.NoError(t, err)
+	copy(testBlockHash[:], hashSlice)
+	bidTrace := &common.BidTraceV2{
+		BidTrace: v1.BidTrace{
+			BlockHash:            testBlockHash,
+			Slot:                 slot,
+			BuilderPubkey:        *pk,
+			ProposerPubkey:       *pk,
+			ProposerFeeRecipient: feeRecipient,
+			Value:                uint256.NewInt(collateral),
+		},
+	}
+	signedBlindedBeaconBlock := &common.SignedBlindedBeaconBlock{
+		Capella: &apiv1capella.SignedBlindedBeaconBlock{
+			Message: &apiv1capella.BlindedBeaconBlock{
+				Slot: phase0.Slot(slot),
+				Body: &apiv1capella.BlindedBeaconBlockBody{
+					ExecutionPayloadHeader: &consensuscapella.ExecutionPayloadHeader{BlockHash: testBlockHash},
+				},
+			},
+		},
+	}
+	err = db.SaveDeliveredPayload(bidTrace, signedBlindedBeaconBlock, time.Now(), 10)
+	require.NoError(t, err)
+
+	entry, err := db.GetDeliveredPayloadByBlockHash(blockHashStr)
+	require.NoError(t, err)
+	require.Equal(t, slot, entry.Slot)
+	require.True(t, entry.SignedBlindedBeaconBlock.Valid)
+	require.Contains(t, entry.SignedBlindedBeaconBlock.String, blockHashStr)
+
+	_, err = db.GetDeliveredPayloadByBlockHash("0x00")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetTooLateGetPayloads(t *testing.T) {
+	db := resetDatabase(t)
+	pk1 := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+	pk2 := "0xb67a5148a03229926e34b190af81a82a81c4df66831c98c03a139778418dd09a3b542ced0022620d19f35781ece6dc36"
+	for i := uint64(0); i < 3; i++ {
+		err := db.InsertTooLateGetPayload(slot+i, pk1, blockHashStr, 1, 2, 3, 4000+i)
+		require.NoError(t, err)
+	}
+	err := db.InsertTooLateGetPayload(slot, pk2, blockHashStr, 1, 2, 3, 5000)
+	require.NoError(t, err)
+
+	entries, err := db.GetTooLateGetPayloads(slot, slot+10, "", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.Equal(t, slot+2, entries[0].Slot)
+
+	entries, err = db.GetTooLateGetPayloads(slot, slot+1, pk1, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	entries, err = db.GetTooLateGetPayloads(0, slot+10, pk2, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, uint64(5000), entries[0].MsIntoSlot)
+
+	entries, err = db.GetTooLateGetPayloads(0, slot+10, "", 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestDataAPIKeys(t *testing.T) {
+	db := resetDatabase(t)
+
+	entry := &DataAPIKeyEntry{KeyHash: "abcd", Description: "test", QuotaPerMin: 10} //nolint:exhaustruct
+	err := db.InsertDataAPIKey(entry)
+	require.NoError(t, err)
+	require.NotZero(t, entry.ID)
+
+	err = db.InsertDataAPIKey(&DataAPIKeyEntry{KeyHash: "abcd", Description: "duplicate"}) //nolint:exhaustruct
+	require.Error(t, err)
+
+	fetched, err := db.GetDataAPIKeyByHash("abcd")
+	require.NoError(t, err)
+	require.Equal(t, entry.ID, fetched.ID)
+	require.Equal(t, uint64(10), fetched.QuotaPerMin)
+	require.False(t, fetched.RevokedAt.Valid)
+
+	_, err = db.GetDataAPIKeyByHash("efgh")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	revoked, err := db.RevokeDataAPIKey(entry.ID)
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+	_, err = db.RevokeDataAPIKey(entry.ID + 1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	entries, err := db.GetDataAPIKeys()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].RevokedAt.Valid)
+}
+
+func TestInternalAPIAudit(t *testing.T) {
+	db := resetDatabase(t)
+
+	for i, target := range []string{"0x01", "0x02", "0x01"} {
+		entry := &InternalAPIAuditEntry{ //nolint:exhaustruct
+			Actor:      "alice",
+			RemoteIP:   "10.0.0.1",
+			RemoteAddr: "192.168.0.1:1234",
+			Method:     "POST",
+			Path:       "/internal/v1/builder/" + target,
+			Action:     "builder_status",
+			Target:     target,
+			OldValue:   `{"IsHighPrio":false}`,
+			NewValue:   fmt.Sprintf(`{"IsHighPrio":%t}`, i%2 == 0),
+		}
+		if i == 2 {
+			entry.Actor = "bob"
+		}
+		require.NoError(t, db.InsertInternalAPIAudit(entry))
+		require.Equal(t, int64(i+1), entry.ID)
+	}
+
+	entries, err := db.GetInternalAPIAudit(GetInternalAPIAuditFilters{}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, int64(3), entries[0].ID)
+	require.Equal(t, `{"IsHighPrio":true}`, entries[0].NewValue)
+
+	entries, err = db.GetInternalAPIAudit(GetInternalAPIAuditFilters{Target: "0x01", Limit: 1}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "bob", entries[0].Actor)
+
+	entries, err = db.GetInternalAPIAudit(GetInternalAPIAuditFilters{Actor: "alice", Cursor: 1}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "0x01", entries[0].Target)
+}
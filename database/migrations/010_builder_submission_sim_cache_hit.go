@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration010BuilderSubmissionSimCacheHit records whether the simulation result
+// of a submission was taken from the simulation result cache.
+var Migration010BuilderSubmissionSimCacheHit = &migrate.Migration{
+	Id: "010-builder-submission-sim-cache-hit",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBuilderBlockSubmission + ` ADD sim_cache_hit bool NOT NULL default false;
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration011BuilderDemotionReview adds the review state of a demotion (open,
+// refunded, reinstated or revoked), with the operator's notes.
+var Migration011BuilderDemotionReview = &migrate.Migration{
+	Id: "011-builder-demotion-review",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBuilderDemotions + ` ADD review_status varchar(16) NOT NULL default 'open';
+		ALTER TABLE ` + vars.TableBuilderDemotions + ` ADD review_notes  text NOT NULL default '';
+		ALTER TABLE ` + vars.TableBuilderDemotions + ` ADD reviewed_at   timestamp;
+	`, `
+		CREATE INDEX IF NOT EXISTS ` + vars.TableBuilderDemotions + `_builder_pubkey_review_status_idx ON ` + vars.TableBuilderDemotions + `(builder_pubkey, review_status);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
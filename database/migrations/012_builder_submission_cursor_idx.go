@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration012BuilderSubmissionCursorIdx adds an index to page through a builder's submissions by id
+var Migration012BuilderSubmissionCursorIdx = &migrate.Migration{
+	Id: "012-builder-submission-cursor-idx",
+	Up: []string{`
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS ` + vars.TableBuilderBlockSubmission + `_builderpubkey_id_idx ON ` + vars.TableBuilderBlockSubmission + `(builder_pubkey, id DESC);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true, // cannot create index concurrently inside a transaction
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration013DataAPIRangeIdx adds indexes for the slot range filters of the data API
+var Migration013DataAPIRangeIdx = &migrate.Migration{
+	Id: "013-data-api-range-idx",
+	Up: []string{`
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS ` + vars.TableBuilderBlockSubmission + `_builderpubkey_slot_idx ON ` + vars.TableBuilderBlockSubmission + `(builder_pubkey, slot);
+	`, `
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS ` + vars.TableDeliveredPayload + `_builderpubkey_slot_idx ON ` + vars.TableDeliveredPayload + `(builder_pubkey, slot);
+	`, `
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS ` + vars.TableDeliveredPayload + `_proposerpubkey_slot_idx ON ` + vars.TableDeliveredPayload + `(proposer_pubkey, slot);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true, // cannot create index concurrently inside a transaction
+	DisableTransactionDown: true,
+}
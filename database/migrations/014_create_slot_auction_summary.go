@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+var Migration014CreateSlotAuctionSummary = &migrate.Migration{
+	Id: "014-create-slot-auction-summary",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableSlotAuctionSummary + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			slot  bigint NOT NULL,
+			epoch bigint NOT NULL,
+
+			parent_hash     varchar(66) NOT NULL,
+			proposer_pubkey varchar(98) NOT NULL,
+
+			num_bids         bigint NOT NULL,
+			num_builders     bigint NOT NULL,
+			top_bid_timeline json NOT NULL,
+			floor_value      NUMERIC(48, 0) NOT NULL,
+
+			winning_builder_pubkey   varchar(98) NOT NULL,
+			winning_block_hash       varchar(66) NOT NULL,
+			winning_value            NUMERIC(48, 0) NOT NULL,
+			runner_up_builder_pubkey varchar(98) NOT NULL,
+			runner_up_value          NUMERIC(48, 0) NOT NULL,
+			margin                   NUMERIC(48, 0) NOT NULL,
+
+			getpayload_called       boolean NOT NULL,
+			getpayload_too_late     boolean NOT NULL,
+			getpayload_ms_into_slot bigint NOT NULL,
+			publish_ms              bigint NOT NULL,
+
+			UNIQUE (slot)
+		);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
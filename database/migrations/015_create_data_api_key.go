@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+var Migration015CreateDataAPIKey = &migrate.Migration{
+	Id: "015-create-data-api-key",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableDataAPIKey + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			key_hash      varchar(64) NOT NULL,
+			description   text NOT NULL,
+			quota_per_min bigint NOT NULL,
+			revoked_at    timestamp,
+
+			UNIQUE (key_hash)
+		);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
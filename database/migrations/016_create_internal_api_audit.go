@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+var Migration016CreateInternalAPIAudit = &migrate.Migration{
+	Id: "016-create-internal-api-audit",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableInternalAPIAudit + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			actor       text NOT NULL,
+			remote_ip   text NOT NULL,
+			remote_addr text NOT NULL,
+			method      varchar(16) NOT NULL,
+			path        text NOT NULL,
+
+			action    varchar(64) NOT NULL,
+			target    text NOT NULL,
+			old_value text NOT NULL,
+			new_value text NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS ` + vars.TableInternalAPIAudit + `_target_idx ON ` + vars.TableInternalAPIAudit + `("target");
+		CREATE INDEX IF NOT EXISTS ` + vars.TableInternalAPIAudit + `_actor_idx ON ` + vars.TableInternalAPIAudit + `("actor");
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
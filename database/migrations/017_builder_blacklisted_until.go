@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration017BuilderBlacklistedUntil adds an optional expiry to builder blacklists.
+// Blacklists without expiry stay in place until removed.
+var Migration017BuilderBlacklistedUntil = &migrate.Migration{
+	Id: "017-builder-blacklisted-until",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBlockBuilder + ` ADD blacklisted_until timestamp;
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -16,5 +16,13 @@ var Migrations = migrate.MemoryMigrationSource{
 		Migration007BuilderSubmissionWasSimulated,
 		Migration008Optimistic,
 		Migration009BlockBuilderRemoveReference,
+		Migration010BuilderSubmissionSimCacheHit,
+		Migration011BuilderDemotionReview,
+		Migration012BuilderSubmissionCursorIdx,
+		Migration013DataAPIRangeIdx,
+		Migration014CreateSlotAuctionSummary,
+		Migration015CreateDataAPIKey,
+		Migration016CreateInternalAPIAudit,
+		Migration017BuilderBlacklistedUntil,
 	},
 }
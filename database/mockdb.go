@@ -1,17 +1,28 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/flashbots/go-boost-utils/types"
 	"github.com/flashbots/mev-boost-relay/common"
+	"golang.org/x/exp/slices"
 )
 
 type MockDB struct {
-	Builders  map[string]*BlockBuilderEntry
-	Demotions map[string]bool
-	Refunds   map[string]bool
+	Builders           map[string]*BlockBuilderEntry
+	Demotions          map[string]bool
+	DemotionEntries    []*BuilderDemotionEntry // ordered by slot and id
+	Refunds            map[string]bool
+	Registrations      []*ValidatorRegistrationEntry // ordered by timestamp descending
+	AuctionSummaries   map[uint64]*SlotAuctionSummaryEntry
+	ExecutionPayloads  map[string]*ExecutionPayloadEntry // by block hash
+	TooLateGetPayloads []*TooLateGetPayloadEntry         // ordered by slot descending
+	DeliveredPayloads  []*DeliveredPayloadEntry          // ordered by slot descending
+	BuilderSubmissions []*BuilderBlockSubmissionEntry    // ordered by id descending
+	DataAPIKeys        map[string]*DataAPIKeyEntry       // by key hash
+	InternalAPIAudit   map[int64]*InternalAPIAuditEntry  // by id
 }
 
 func (db MockDB) NumRegisteredValidators() (count uint64, err error) {
@@ -27,7 +38,27 @@ func (db MockDB) GetValidatorRegistration(pubkey string) (*ValidatorRegistration
 }
 
 func (db MockDB) GetValidatorRegistrationsForPubkeys(pubkeys []string) (entries []*ValidatorRegistrationEntry, err error) {
-	return nil, nil
+	seen := make(map[string]bool)
+	for _, entry := range db.Registrations {
+		if seen[entry.Pubkey] || !slices.Contains(pubkeys, entry.Pubkey) {
+			continue
+		}
+		seen[entry.Pubkey] = true
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (db MockDB) GetValidatorRegistrationHistory(pubkey string, limit uint64) (entries []*ValidatorRegistrationEntry, err error) {
+	for _, entry := range db.Registrations {
+		if uint64(len(entries)) == limit {
+			break
+		}
+		if entry.Pubkey == pubkey {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
 }
 
 func (db MockDB) GetLatestValidatorRegistrations(timestampOnly bool) ([]*ValidatorRegistrationEntry, error) {
@@ -43,7 +74,7 @@ func (db MockDB) GetExecutionPayloadEntryByID(executionPayloadID int64) (entry *
 }
 
 func (db MockDB) GetExecutionPayloadEntryBySlotPkHash(slot uint64, proposerPubkey, blockHash string) (entry *ExecutionPayloadEntry, err error) {
-	return nil, nil
+	return db.ExecutionPayloads[blockHash], nil
 }
 
 func (db MockDB) GetExecutionPayloads(idFirst, idLast uint64) (entries []*ExecutionPayloadEntry, err error) {
@@ -59,7 +90,23 @@ func (db MockDB) GetBlockSubmissionEntry(slot uint64, proposerPubkey, blockHash
 }
 
 func (db MockDB) GetRecentDeliveredPayloads(filters GetPayloadsFilters) ([]*DeliveredPayloadEntry, error) {
-	return nil, nil
+	entries := []*DeliveredPayloadEntry{}
+	for _, entry := range db.DeliveredPayloads {
+		if filters.Cursor > 0 && entry.Slot > filters.Cursor {
+			continue
+		}
+		if (filters.SlotFrom > 0 && entry.Slot < filters.SlotFrom) || (filters.SlotTo > 0 && entry.Slot > filters.SlotTo) {
+			continue
+		}
+		if filters.ProposerPubkey != "" && entry.ProposerPubkey != filters.ProposerPubkey {
+			continue
+		}
+		if filters.Limit > 0 && uint64(len(entries)) == filters.Limit {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
 }
 
 func (db MockDB) GetDeliveredPayloads(idFirst, idLast uint64) (entries []*DeliveredPayloadEntry, err error) {
@@ -70,8 +117,30 @@ func (db MockDB) GetNumDeliveredPayloads() (uint64, error) {
 	return 0, nil
 }
 
+func (db MockDB) GetDeliveredPayloadByBlockHash(blockHash string) (*DeliveredPayloadEntry, error) {
+	for _, entry := range db.DeliveredPayloads {
+		if entry.BlockHash == blockHash {
+			return entry, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
 func (db MockDB) GetBuilderSubmissions(filters GetBuilderSubmissionsFilters) ([]*BuilderBlockSubmissionEntry, error) {
-	return nil, nil
+	entries := []*BuilderBlockSubmissionEntry{}
+	for _, entry := range db.BuilderSubmissions {
+		if filters.Cursor > 0 && uint64(entry.ID) > filters.Cursor {
+			continue
+		}
+		if filters.BuilderPubkey != "" && entry.BuilderPubkey != filters.BuilderPubkey {
+			continue
+		}
+		if filters.Limit > 0 && uint64(len(entries)) == filters.Limit {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
 }
 
 func (db MockDB) GetBuilderSubmissionsBySlots(slotFrom, slotTo uint64) (entries []*BuilderBlockSubmissionEntry, err error) {
@@ -94,6 +163,58 @@ func (db MockDB) GetBlockBuilders() ([]*BlockBuilderEntry, error) {
 	return res, nil
 }
 
+func (db MockDB) GetFilteredBlockBuilders(filters GetBlockBuildersFilters) ([]*BlockBuilderEntry, error) {
+	res := []*BlockBuilderEntry{}
+	for _, v := range db.Builders {
+		if (filters.BuilderID != "" && v.BuilderID != filters.BuilderID) ||
+			(filters.IsOptimistic.Valid && v.IsOptimistic != filters.IsOptimistic.Bool) ||
+			(filters.Cursor != "" && v.BuilderPubkey <= filters.Cursor) {
+			continue
+		}
+		res = append(res, v)
+	}
+	slices.SortFunc(res, func(a, b *BlockBuilderEntry) bool { return a.BuilderPubkey < b.BuilderPubkey })
+	if filters.Limit > 0 && uint64(len(res)) > filters.Limit {
+		res = res[:filters.Limit]
+	}
+	return res, nil
+}
+
+func (db MockDB) GetBuilderStats(filters GetBuilderStatsFilters) ([]*BuilderStatsEntry, error) {
+	res := []*BuilderStatsEntry{}
+	for _, v := range db.Builders {
+		if filters.BuilderID != "" && v.BuilderID != filters.BuilderID {
+			continue
+		}
+		res = append(res, &BuilderStatsEntry{ //nolint:exhaustruct
+			ID:                     v.ID,
+			BuilderPubkey:          v.BuilderPubkey,
+			BuilderID:              v.BuilderID,
+			Description:            v.Description,
+			IsHighPrio:             v.IsHighPrio,
+			IsBlacklisted:          v.IsBlacklistedAt(time.Now()),
+			IsOptimistic:           v.IsOptimistic,
+			NumSubmissionsTotal:    v.NumSubmissionsTotal,
+			NumSubmissionsSimError: v.NumSubmissionsSimError,
+			NumSentGetPayload:      v.NumSentGetPayload,
+			ValueDelivered:         "0",
+		})
+	}
+	// no value is delivered in the mock, so the builders are ordered by id
+	slices.SortFunc(res, func(a, b *BuilderStatsEntry) bool { return a.ID < b.ID })
+	if filters.CursorID > 0 {
+		i := 0
+		for i < len(res) && res[i].ID <= filters.CursorID {
+			i++
+		}
+		res = res[i:]
+	}
+	if filters.Limit > 0 && uint64(len(res)) > filters.Limit {
+		res = res[:filters.Limit]
+	}
+	return res, nil
+}
+
 func (db MockDB) GetBlockBuilderByPubkey(pubkey string) (*BlockBuilderEntry, error) {
 	builder, ok := db.Builders[pubkey]
 	if !ok {
@@ -109,12 +230,41 @@ func (db MockDB) SetBlockBuilderStatus(pubkey string, status common.BuilderStatu
 	}
 
 	// Single key.
+	if !status.IsBlacklisted || !builder.IsBlacklistedAt(time.Now()) {
+		builder.BlacklistedUntil = sql.NullTime{}
+	}
 	builder.IsHighPrio = status.IsHighPrio
 	builder.IsBlacklisted = status.IsBlacklisted
 	builder.IsOptimistic = status.IsOptimistic
 	return nil
 }
 
+func (db MockDB) UpdateBlockBuilders(pubkeys []string, update BlockBuilderUpdate) error {
+	for _, pubkey := range pubkeys {
+		builder, ok := db.Builders[pubkey]
+		if !ok {
+			continue
+		}
+		if update.IsHighPrio != nil {
+			builder.IsHighPrio = *update.IsHighPrio
+		}
+		if update.IsBlacklisted != nil {
+			builder.IsBlacklisted = *update.IsBlacklisted
+			builder.BlacklistedUntil = sql.NullTime{}
+			if *update.IsBlacklisted {
+				builder.BlacklistedUntil = update.BlacklistedUntil
+			}
+		}
+		if update.IsOptimistic != nil {
+			builder.IsOptimistic = *update.IsOptimistic
+		}
+		if update.Description != nil {
+			builder.Description = *update.Description
+		}
+	}
+	return nil
+}
+
 func (db MockDB) SetBlockBuilderIDStatusIsOptimistic(pubkey string, isOptimistic bool) error {
 	builder, ok := db.Builders[pubkey]
 	if !ok {
@@ -177,10 +327,137 @@ func (db MockDB) GetBuilderDemotion(trace *common.BidTraceV2) (*BuilderDemotionE
 	return nil, nil
 }
 
+func (db MockDB) GetBuilderDemotions(filters GetBuilderDemotionsFilters) ([]*BuilderDemotionEntry, error) {
+	res := []*BuilderDemotionEntry{}
+	for _, entry := range db.DemotionEntries {
+		if entry.Slot < filters.SlotFrom || entry.Slot > filters.SlotTo ||
+			(filters.BuilderPubkey != "" && entry.BuilderPubkey != filters.BuilderPubkey) ||
+			(filters.CursorID > 0 && (entry.Slot < filters.CursorSlot || (entry.Slot == filters.CursorSlot && entry.ID <= filters.CursorID))) {
+			continue
+		}
+		if filters.Limit > 0 && uint64(len(res)) == filters.Limit {
+			break
+		}
+		res = append(res, entry)
+	}
+	return res, nil
+}
+
+func (db MockDB) GetBuilderDemotionByID(id int64) (*BuilderDemotionEntry, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (db MockDB) ResolveBuilderDemotion(id int64, reviewStatus, reviewNotes string) error {
+	return nil
+}
+
+func (db MockDB) GetBuilderDemotionReviewCounts(pubkey string) (map[string]uint64, error) {
+	counts := make(map[string]uint64)
+	if db.Demotions[pubkey] && !db.Refunds[pubkey] {
+		counts[DemotionReviewOpen] = 1
+	}
+	return counts, nil
+}
+
 func (db MockDB) GetTooLateGetPayload(slot uint64) (entries []*TooLateGetPayloadEntry, err error) {
 	return nil, nil
 }
 
+func (db MockDB) GetTooLateGetPayloads(slotFrom, slotTo uint64, proposerPubkey string, limit uint64) (entries []*TooLateGetPayloadEntry, err error) {
+	for _, entry := range db.TooLateGetPayloads {
+		if entry.Slot < slotFrom || entry.Slot > slotTo || (proposerPubkey != "" && entry.ProposerPubkey != proposerPubkey) {
+			continue
+		}
+		if uint64(len(entries)) == limit {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
 func (db MockDB) InsertTooLateGetPayload(slot uint64, proposerPubkey, blockHash string, slotStart, requestTime, decodeTime, msIntoSlot uint64) error {
 	return nil
 }
+
+func (db MockDB) SaveSlotAuctionSummary(entry *SlotAuctionSummaryEntry) error {
+	if db.AuctionSummaries != nil {
+		db.AuctionSummaries[entry.Slot] = entry
+	}
+	return nil
+}
+
+func (db MockDB) GetSlotAuctionSummaries(slotFrom, slotTo uint64) ([]*SlotAuctionSummaryEntry, error) {
+	entries := []*SlotAuctionSummaryEntry{}
+	for slot := slotTo; slot >= slotFrom && slot > 0; slot-- {
+		if entry, ok := db.AuctionSummaries[slot]; ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (db MockDB) InsertDataAPIKey(entry *DataAPIKeyEntry) error {
+	if db.DataAPIKeys != nil {
+		entry.ID = int64(len(db.DataAPIKeys) + 1)
+		entry.InsertedAt = time.Now()
+		db.DataAPIKeys[entry.KeyHash] = entry
+	}
+	return nil
+}
+
+func (db MockDB) GetDataAPIKeyByHash(keyHash string) (*DataAPIKeyEntry, error) {
+	entry, ok := db.DataAPIKeys[keyHash]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+func (db MockDB) GetDataAPIKeys() ([]*DataAPIKeyEntry, error) {
+	entries := []*DataAPIKeyEntry{}
+	for _, entry := range db.DataAPIKeys {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b *DataAPIKeyEntry) bool { return a.ID < b.ID })
+	return entries, nil
+}
+
+func (db MockDB) RevokeDataAPIKey(id int64) (*DataAPIKeyEntry, error) {
+	for _, entry := range db.DataAPIKeys {
+		if entry.ID == id {
+			if !entry.RevokedAt.Valid {
+				entry.RevokedAt = NewNullTime(time.Now())
+			}
+			return entry, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (db MockDB) InsertInternalAPIAudit(entry *InternalAPIAuditEntry) error {
+	if db.InternalAPIAudit != nil {
+		entry.ID = int64(len(db.InternalAPIAudit) + 1)
+		entry.InsertedAt = time.Now()
+		db.InternalAPIAudit[entry.ID] = entry
+	}
+	return nil
+}
+
+func (db MockDB) GetInternalAPIAudit(filters GetInternalAPIAuditFilters) ([]*InternalAPIAuditEntry, error) {
+	entries := []*InternalAPIAuditEntry{}
+	for _, entry := range db.InternalAPIAudit {
+		if (filters.Actor != "" && entry.Actor != filters.Actor) ||
+			(filters.Action != "" && entry.Action != filters.Action) ||
+			(filters.Target != "" && entry.Target != filters.Target) ||
+			(filters.Cursor > 0 && uint64(entry.ID) > filters.Cursor) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b *InternalAPIAuditEntry) bool { return a.ID > b.ID })
+	if filters.Limit > 0 && uint64(len(entries)) > filters.Limit {
+		entries = entries[:filters.Limit]
+	}
+	return entries, nil
+}
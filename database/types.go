@@ -22,6 +22,13 @@ func NewNullString(s string) sql.NullString {
 	}
 }
 
+func NewNullBool(b bool) sql.NullBool {
+	return sql.NullBool{
+		Bool:  b,
+		Valid: true,
+	}
+}
+
 func NewNullTime(t time.Time) sql.NullTime {
 	return sql.NullTime{
 		Time:  t,
@@ -31,6 +38,8 @@ func NewNullTime(t time.Time) sql.NullTime {
 
 type GetPayloadsFilters struct {
 	Slot           uint64
+	SlotFrom       uint64 // inclusive
+	SlotTo         uint64 // inclusive
 	Cursor         uint64
 	Limit          uint64
 	BlockHash      string
@@ -41,12 +50,49 @@ type GetPayloadsFilters struct {
 }
 
 type GetBuilderSubmissionsFilters struct {
-	Slot        uint64
-	Limit       uint64
-	BlockHash   string
-	BlockNumber uint64
-	// Cursor      uint64
+	Slot           uint64
+	SlotFrom       uint64    // inclusive
+	SlotTo         uint64    // inclusive
+	ReceivedAfter  time.Time // inclusive
+	ReceivedBefore time.Time // exclusive
+	Cursor         uint64    // inclusive upper bound on the submission id, for keyset pagination
+	Limit          uint64    // 0 means no limit
+	BlockHash      string
+	BlockNumber    uint64
+	BuilderPubkey  string
+}
+
+// GetBlockBuildersFilters selects builders, ordered by pubkey
+type GetBlockBuildersFilters struct {
+	BuilderID    string
+	IsOptimistic sql.NullBool
+	Cursor       string // exclusive lower bound on the builder pubkey, for keyset pagination
+	Limit        uint64 // 0 means no limit
+}
+
+// GetBuilderStatsFilters selects builder statistics, ordered by the value delivered in the window
+type GetBuilderStatsFilters struct {
+	BuilderID string
+	SlotFrom  uint64 // start of the window
+
+	// keyset pagination: only the builders after the one with this value delivered and id
+	CursorValueDelivered string
+	CursorID             int64 // 0 means no cursor
+
+	Limit uint64 // 0 means no limit
+}
+
+// GetBuilderDemotionsFilters selects demotions, ordered by slot and id
+type GetBuilderDemotionsFilters struct {
+	SlotFrom      uint64 // inclusive
+	SlotTo        uint64 // inclusive
 	BuilderPubkey string
+
+	// keyset pagination: only the demotions after the one with this slot and id
+	CursorSlot uint64
+	CursorID   int64 // 0 means no cursor
+
+	Limit uint64 // 0 means no limit
 }
 
 type ValidatorRegistrationEntry struct {
@@ -165,6 +211,7 @@ type BuilderBlockSubmissionEntry struct {
 	SimulationDuration   uint64 `db:"simulation_duration"`
 	RedisUpdateDuration  uint64 `db:"redis_update_duration"`
 	TotalDuration        uint64 `db:"total_duration"`
+	SimCacheHit          bool   `db:"sim_cache_hit"`
 	OptimisticSubmission bool   `db:"optimistic_submission"`
 }
 
@@ -202,9 +249,10 @@ type BlockBuilderEntry struct {
 	BuilderPubkey string `db:"builder_pubkey" json:"builder_pubkey"`
 	Description   string `db:"description"    json:"description"`
 
-	IsHighPrio    bool `db:"is_high_prio"   json:"is_high_prio"`
-	IsBlacklisted bool `db:"is_blacklisted" json:"is_blacklisted"`
-	IsOptimistic  bool `db:"is_optimistic"  json:"is_optimistic"`
+	IsHighPrio       bool         `db:"is_high_prio"      json:"is_high_prio"`
+	IsBlacklisted    bool         `db:"is_blacklisted"    json:"is_blacklisted"`
+	BlacklistedUntil sql.NullTime `db:"blacklisted_until" json:"blacklisted_until"` // the blacklist is permanent if not set
+	IsOptimistic     bool         `db:"is_optimistic"     json:"is_optimistic"`
 
 	Collateral string `db:"collateral" json:"collateral"`
 	BuilderID  string `db:"builder_id" json:"builder_id"`
@@ -218,6 +266,52 @@ type BlockBuilderEntry struct {
 	NumSentGetPayload uint64 `db:"num_sent_getpayload" json:"num_sent_getpayload"`
 }
 
+// IsBlacklistedAt returns whether the builder is blacklisted at the given time, i.e. it's blacklisted and the blacklist didn't expire yet
+func (b *BlockBuilderEntry) IsBlacklistedAt(t time.Time) bool {
+	return b.IsBlacklisted && (!b.BlacklistedUntil.Valid || b.BlacklistedUntil.Time.After(t))
+}
+
+// EffectiveAt returns a copy of the entry with the blacklist status at the given time, without an expired blacklist
+func (b *BlockBuilderEntry) EffectiveAt(t time.Time) *BlockBuilderEntry {
+	entry := *b
+	if !entry.IsBlacklistedAt(t) {
+		entry.IsBlacklisted = false
+		entry.BlacklistedUntil = sql.NullTime{} //nolint:exhaustruct
+	}
+	return &entry
+}
+
+// BlockBuilderUpdate is a change to the status and description of builders, nil fields are left unchanged
+type BlockBuilderUpdate struct {
+	IsHighPrio       *bool
+	IsBlacklisted    *bool
+	BlacklistedUntil sql.NullTime // expiry of the blacklist if IsBlacklisted is set to true, permanent if not set
+	IsOptimistic     *bool
+	Description      *string
+}
+
+// BuilderStatsEntry combines the block_builder counters with statistics over a recent slot window
+type BuilderStatsEntry struct {
+	ID            int64  `db:"id"`
+	BuilderPubkey string `db:"builder_pubkey"`
+	BuilderID     string `db:"builder_id"`
+	Description   string `db:"description"`
+
+	IsHighPrio    bool `db:"is_high_prio"`
+	IsBlacklisted bool `db:"is_blacklisted"`
+	IsOptimistic  bool `db:"is_optimistic"`
+
+	NumSubmissionsTotal    uint64 `db:"num_submissions_total"`
+	NumSubmissionsSimError uint64 `db:"num_submissions_simerror"`
+	NumSentGetPayload      uint64 `db:"num_sent_getpayload"`
+
+	// over the slot window
+	NumSlotsBid         uint64 `db:"num_slots_bid"`
+	NumSlotsDelivered   uint64 `db:"num_slots_delivered"`
+	ValueDelivered      string `db:"value_delivered"`
+	MedianTotalDuration uint64 `db:"median_total_duration"`
+}
+
 type BuilderDemotionEntry struct {
 	ID         int64     `db:"id"`
 	InsertedAt time.Time `db:"inserted_at"`
@@ -239,6 +333,31 @@ type BuilderDemotionEntry struct {
 	BlockHash string `db:"block_hash"`
 
 	SimError string `db:"sim_error"`
+
+	// Review of the demotion by an operator
+	ReviewStatus string       `db:"review_status"`
+	ReviewNotes  string       `db:"review_notes"`
+	ReviewedAt   sql.NullTime `db:"reviewed_at"`
+
+	// Helpers, only set by GetBuilderDemotions
+	WasDelivered bool `db:"was_delivered"`
+}
+
+// Review states of a demotion. A builder can only be made optimistic again once
+// none of its demotions are open, and never if one was permanently revoked.
+const (
+	DemotionReviewOpen       = "open"
+	DemotionReviewRefunded   = "refunded"
+	DemotionReviewReinstated = "reinstated"
+	DemotionReviewRevoked    = "revoked"
+)
+
+func IsValidDemotionReviewStatus(status string) bool {
+	switch status {
+	case DemotionReviewOpen, DemotionReviewRefunded, DemotionReviewReinstated, DemotionReviewRevoked:
+		return true
+	}
+	return false
 }
 
 type TooLateGetPayloadEntry struct {
@@ -255,3 +374,68 @@ type TooLateGetPayloadEntry struct {
 	BlockHash      string `db:"block_hash"`
 	MsIntoSlot     uint64 `db:"ms_into_slot"`
 }
+
+// SlotAuctionSummaryEntry is the auction summary of one slot, computed by the housekeeper after the slot ended
+type SlotAuctionSummaryEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+
+	Slot  uint64 `db:"slot"`
+	Epoch uint64 `db:"epoch"`
+
+	ParentHash     string `db:"parent_hash"`
+	ProposerPubkey string `db:"proposer_pubkey"`
+
+	NumBids        uint64 `db:"num_bids"`
+	NumBuilders    uint64 `db:"num_builders"`
+	TopBidTimeline string `db:"top_bid_timeline"` // JSON list of common.AuctionTopBidJSON
+	FloorValue     string `db:"floor_value"`
+
+	WinningBuilderPubkey  string `db:"winning_builder_pubkey"`
+	WinningBlockHash      string `db:"winning_block_hash"`
+	WinningValue          string `db:"winning_value"`
+	RunnerUpBuilderPubkey string `db:"runner_up_builder_pubkey"`
+	RunnerUpValue         string `db:"runner_up_value"`
+	Margin                string `db:"margin"`
+
+	GetPayloadCalled     bool   `db:"getpayload_called"`
+	GetPayloadTooLate    bool   `db:"getpayload_too_late"`
+	GetPayloadMsIntoSlot uint64 `db:"getpayload_ms_into_slot"`
+	PublishMs            uint64 `db:"publish_ms"`
+}
+
+// DataAPIKeyEntry is an API key for the data API. Only the SHA-256 hash of the key is stored.
+type DataAPIKeyEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+
+	KeyHash     string       `db:"key_hash"`
+	Description string       `db:"description"`
+	QuotaPerMin uint64       `db:"quota_per_min"` // 0 for the default quota of API keys
+	RevokedAt   sql.NullTime `db:"revoked_at"`
+}
+
+// InternalAPIAuditEntry records a change made through the internal API. Old and new values are JSON encoded.
+type InternalAPIAuditEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+
+	Actor      string `db:"actor"`       // name of the internal API token, empty if the internal API is unauthenticated
+	RemoteIP   string `db:"remote_ip"`   // client IP, as appended to X-Forwarded-For by the trusted proxies, or the remote address
+	RemoteAddr string `db:"remote_addr"` // address of the connection
+	Method     string `db:"method"`
+	Path       string `db:"path"`
+
+	Action   string `db:"action"`
+	Target   string `db:"target"`
+	OldValue string `db:"old_value"`
+	NewValue string `db:"new_value"`
+}
+
+type GetInternalAPIAuditFilters struct {
+	Actor  string
+	Action string
+	Target string
+	Cursor uint64 // inclusive upper bound on the entry id, for keyset pagination
+	Limit  uint64 // 0 means no limit
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"database/sql"
 	"encoding/json"
 
 	"github.com/flashbots/mev-boost-relay/common"
@@ -75,3 +76,165 @@ func BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(payload *BuilderBlockSu
 		},
 	}
 }
+
+func BuilderDemotionEntryToRefundJSON(entry *BuilderDemotionEntry) common.BuilderDemotionRefundJSON {
+	refundOwed := "0"
+	if entry.WasDelivered {
+		refundOwed = entry.Value
+	}
+
+	rawJSON := func(s sql.NullString) json.RawMessage {
+		if !s.Valid || s.String == "" || s.String == "null" {
+			return nil
+		}
+		return json.RawMessage(s.String)
+	}
+
+	return common.BuilderDemotionRefundJSON{
+		ID:                          entry.ID,
+		Slot:                        entry.Slot,
+		Epoch:                       entry.Epoch,
+		BuilderPubkey:               entry.BuilderPubkey,
+		ProposerPubkey:              entry.ProposerPubkey,
+		BlockHash:                   entry.BlockHash,
+		FeeRecipient:                entry.FeeRecipient,
+		Value:                       entry.Value,
+		SimError:                    entry.SimError,
+		DemotedAt:                   entry.InsertedAt.Unix(),
+		WasDelivered:                entry.WasDelivered,
+		RefundOwed:                  refundOwed,
+		ReviewStatus:                entry.ReviewStatus,
+		ReviewNotes:                 entry.ReviewNotes,
+		SubmitBlockRequest:          rawJSON(entry.SubmitBlockRequest),
+		SignedBeaconBlock:           rawJSON(entry.SignedBeaconBlock),
+		SignedValidatorRegistration: rawJSON(entry.SignedValidatorRegistration),
+	}
+}
+
+// BuilderStatsEntryToJSON adds the derived rates to the builder statistics
+func BuilderStatsEntryToJSON(entry *BuilderStatsEntry, windowSlotFrom uint64) common.BuilderStatsJSON {
+	simErrorRate := float64(0)
+	if entry.NumSubmissionsTotal > 0 {
+		simErrorRate = float64(entry.NumSubmissionsSimError) / float64(entry.NumSubmissionsTotal)
+	}
+	winRate := float64(0)
+	if entry.NumSlotsBid > 0 {
+		winRate = float64(entry.NumSlotsDelivered) / float64(entry.NumSlotsBid)
+	}
+
+	return common.BuilderStatsJSON{
+		BuilderPubkey:          entry.BuilderPubkey,
+		BuilderID:              entry.BuilderID,
+		Description:            entry.Description,
+		IsHighPrio:             entry.IsHighPrio,
+		IsBlacklisted:          entry.IsBlacklisted,
+		IsOptimistic:           entry.IsOptimistic,
+		NumSubmissionsTotal:    entry.NumSubmissionsTotal,
+		NumSubmissionsSimError: entry.NumSubmissionsSimError,
+		NumSentGetPayload:      entry.NumSentGetPayload,
+		SimErrorRate:           simErrorRate,
+		WindowSlotFrom:         windowSlotFrom,
+		NumSlotsBid:            entry.NumSlotsBid,
+		NumSlotsDelivered:      entry.NumSlotsDelivered,
+		WinRate:                winRate,
+		ValueDelivered:         entry.ValueDelivered,
+		MedianTotalDurationUs:  entry.MedianTotalDuration,
+	}
+}
+
+func SlotAuctionSummaryEntryToJSON(entry *SlotAuctionSummaryEntry) common.SlotAuctionSummaryJSON {
+	return common.SlotAuctionSummaryJSON{
+		Slot:                  entry.Slot,
+		Epoch:                 entry.Epoch,
+		ParentHash:            entry.ParentHash,
+		ProposerPubkey:        entry.ProposerPubkey,
+		NumBids:               entry.NumBids,
+		NumBuilders:           entry.NumBuilders,
+		TopBidTimeline:        json.RawMessage(entry.TopBidTimeline),
+		FloorValue:            entry.FloorValue,
+		WinningBuilderPubkey:  entry.WinningBuilderPubkey,
+		WinningBlockHash:      entry.WinningBlockHash,
+		WinningValue:          entry.WinningValue,
+		RunnerUpBuilderPubkey: entry.RunnerUpBuilderPubkey,
+		RunnerUpValue:         entry.RunnerUpValue,
+		Margin:                entry.Margin,
+		GetPayloadCalled:      entry.GetPayloadCalled,
+		GetPayloadTooLate:     entry.GetPayloadTooLate,
+		GetPayloadMsIntoSlot:  entry.GetPayloadMsIntoSlot,
+		PublishMs:             entry.PublishMs,
+	}
+}
+
+func DeliveredPayloadEntryToGetPayloadTimingJSON(payload *DeliveredPayloadEntry, genesisTime uint64) common.GetPayloadTimingJSON {
+	slotStartTimestamp := genesisTime + payload.Slot*common.SecondsPerSlot
+	timing := common.GetPayloadTimingJSON{
+		Slot:               payload.Slot,
+		ProposerPubkey:     payload.ProposerPubkey,
+		BlockHash:          payload.BlockHash,
+		Delivered:          true,
+		TooLate:            false,
+		SlotStartTimestamp: slotStartTimestamp,
+		DecodedAtMs:        0,
+		MsIntoSlot:         0,
+		PublishMs:          payload.PublishMs,
+	}
+	// signed_at is stored when the getPayload request was decoded
+	if payload.SignedAt.Valid {
+		timing.DecodedAtMs = uint64(payload.SignedAt.Time.UnixMilli())
+		if timing.DecodedAtMs > slotStartTimestamp*1000 {
+			timing.MsIntoSlot = timing.DecodedAtMs - slotStartTimestamp*1000
+		}
+	}
+	return timing
+}
+
+func TooLateGetPayloadEntryToGetPayloadTimingJSON(entry *TooLateGetPayloadEntry) common.GetPayloadTimingJSON {
+	return common.GetPayloadTimingJSON{
+		Slot:               entry.Slot,
+		ProposerPubkey:     entry.ProposerPubkey,
+		BlockHash:          entry.BlockHash,
+		Delivered:          false,
+		TooLate:            true,
+		SlotStartTimestamp: entry.SlotStartTimestamp,
+		DecodedAtMs:        entry.DecodeTimestamp,
+		MsIntoSlot:         entry.MsIntoSlot,
+		PublishMs:          0,
+	}
+}
+
+func DataAPIKeyEntryToJSON(entry *DataAPIKeyEntry) common.DataAPIKeyJSON {
+	ret := common.DataAPIKeyJSON{ //nolint:exhaustruct
+		ID:          entry.ID,
+		Description: entry.Description,
+		QuotaPerMin: entry.QuotaPerMin,
+		IssuedAt:    entry.InsertedAt.Unix(),
+		Revoked:     entry.RevokedAt.Valid,
+	}
+	if entry.RevokedAt.Valid {
+		ret.RevokedAt = entry.RevokedAt.Time.Unix()
+	}
+	return ret
+}
+
+func InternalAPIAuditEntryToJSON(entry *InternalAPIAuditEntry) common.InternalAPIAuditJSON {
+	return common.InternalAPIAuditJSON{
+		ID:         entry.ID,
+		Timestamp:  entry.InsertedAt.Unix(),
+		Actor:      entry.Actor,
+		RemoteIP:   entry.RemoteIP,
+		RemoteAddr: entry.RemoteAddr,
+		Method:     entry.Method,
+		Path:       entry.Path,
+		Action:     entry.Action,
+		Target:     entry.Target,
+		OldValue:   auditValueToJSON(entry.OldValue),
+		NewValue:   auditValueToJSON(entry.NewValue),
+	}
+}
+
+func auditValueToJSON(value string) json.RawMessage {
+	if value == "" || !json.Valid([]byte(value)) {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(value)
+}
@@ -15,4 +15,7 @@ var (
 	TableBuilderDemotions       = tableBase + "_builder_demotions"
 	TableBlockedValidator       = tableBase + "_blocked_validator"
 	TableTooLateGetPayload      = tableBase + "_too_late_get_payload"
+	TableSlotAuctionSummary     = tableBase + "_slot_auction_summary"
+	TableDataAPIKey             = tableBase + "_data_api_key"
+	TableInternalAPIAudit       = tableBase + "_internal_api_audit"
 )
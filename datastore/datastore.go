@@ -54,11 +54,53 @@ func NewDatastore(log *logrus.Entry, redisCache *RedisCache, memcached *Memcache
 	return ds, err
 }
 
+// KnownValidatorsDiff describes how the known validators changed with a refresh
+type KnownValidatorsDiff struct {
+	NumValidators int `json:"num_validators"`
+	NumAdded      int `json:"num_added"`
+	NumRemoved    int `json:"num_removed"`
+	NumChanged    int `json:"num_changed"` // validators with a different index
+}
+
 // RefreshKnownValidators loads known validators from Redis into memory
 func (ds *Datastore) RefreshKnownValidators() (cnt int, err error) {
+	knownValidatorsByIndex, err := ds.loadKnownValidators()
+	return len(knownValidatorsByIndex), err
+}
+
+// RefreshKnownValidatorsDiff loads known validators from Redis into memory, and returns how they changed
+func (ds *Datastore) RefreshKnownValidatorsDiff() (diff KnownValidatorsDiff, err error) {
+	ds.knownValidatorsLock.RLock()
+	prevByPubkey := ds.knownValidatorsByPubkey
+	ds.knownValidatorsLock.RUnlock()
+
+	knownValidatorsByIndex, err := ds.loadKnownValidators()
+	if err != nil {
+		return diff, err
+	}
+
+	diff.NumValidators = len(knownValidatorsByIndex)
+	seen := make(map[types.PubkeyHex]bool, len(knownValidatorsByIndex))
+	for index, pubkey := range knownValidatorsByIndex {
+		seen[pubkey] = true
+		if prevIndex, ok := prevByPubkey[pubkey]; !ok {
+			diff.NumAdded++
+		} else if prevIndex != index {
+			diff.NumChanged++
+		}
+	}
+	for pubkey := range prevByPubkey {
+		if !seen[pubkey] {
+			diff.NumRemoved++
+		}
+	}
+	return diff, nil
+}
+
+func (ds *Datastore) loadKnownValidators() (map[uint64]types.PubkeyHex, error) {
 	knownValidatorsByIndex, err := ds.redis.GetKnownValidators()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	knownValidatorsByPubkey := make(map[types.PubkeyHex]uint64)
@@ -70,7 +112,7 @@ func (ds *Datastore) RefreshKnownValidators() (cnt int, err error) {
 	defer ds.knownValidatorsLock.Unlock()
 	ds.knownValidatorsByPubkey = knownValidatorsByPubkey
 	ds.knownValidatorsByIndex = knownValidatorsByIndex
-	return len(knownValidatorsByIndex), nil
+	return knownValidatorsByIndex, nil
 }
 
 func (ds *Datastore) IsKnownValidator(pubkeyHex types.PubkeyHex) bool {
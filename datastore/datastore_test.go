@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"context"
 	"testing"
 
 	"github.com/alicebob/miniredis/v2"
@@ -57,3 +58,27 @@ func TestProdProposerValidatorRegistration(t *testing.T) {
 	err = copier.Copy(&reg2, &reg1)
 	require.NoError(t, err)
 }
+
+func TestRefreshKnownValidatorsDiff(t *testing.T) {
+	ds := setupTestDatastore(t)
+	pubkey1 := types.PubkeyHex("0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908")
+	pubkey2 := types.PubkeyHex("0xb67a5148a03229926e34b190af81a82a81c4df66831c98c03a139778418dd09a3b542ced0022620d19f35781ece6dc36")
+	pubkey3 := types.PubkeyHex("0xa1885d66bef164889a2e35845c3b626545d7b0e513efe335e97c3a45e534013fa3bc38c3b7e6143695aecc4872ac52c4")
+
+	err := ds.redis.SetMultiKnownValidator(map[uint64]types.PubkeyHex{1: pubkey1, 2: pubkey2})
+	require.NoError(t, err)
+	diff, err := ds.RefreshKnownValidatorsDiff()
+	require.NoError(t, err)
+	require.Equal(t, KnownValidatorsDiff{NumValidators: 2, NumAdded: 2, NumRemoved: 0, NumChanged: 0}, diff)
+
+	// pubkey2 is removed, pubkey1 moves to another index and pubkey3 is added
+	err = ds.redis.client.Del(context.Background(), ds.redis.keyKnownValidators).Err()
+	require.NoError(t, err)
+	err = ds.redis.SetMultiKnownValidator(map[uint64]types.PubkeyHex{3: pubkey1, 4: pubkey3})
+	require.NoError(t, err)
+	diff, err = ds.RefreshKnownValidatorsDiff()
+	require.NoError(t, err)
+	require.Equal(t, KnownValidatorsDiff{NumValidators: 2, NumAdded: 1, NumRemoved: 1, NumChanged: 1}, diff)
+	require.True(t, ds.IsKnownValidator(pubkey3))
+	require.False(t, ds.IsKnownValidator(pubkey2))
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +22,11 @@ var (
 
 	expiryBidCache = 45 * time.Second
 
+	// withdrawn block hashes need to be known until getPayload can't be called for the slot anymore
+	expiryWithdrawnBids = 5 * time.Minute
+
+	expiryBlockSimResult = time.Duration(cli.GetEnvInt("BLOCKSIM_CACHE_TTL_MS", 12000)) * time.Millisecond
+
 	RedisConfigFieldPubkey         = "pubkey"
 	RedisStatsFieldLatestSlot      = "latest-slot"
 	RedisStatsFieldValidatorsTotal = "validators-total"
@@ -28,6 +34,8 @@ var (
 	ErrFailedUpdatingTopBidNoBids            = errors.New("failed to update top bid because no bids were found")
 	ErrAnotherPayloadAlreadyDeliveredForSlot = errors.New("another payload block hash for slot was already delivered")
 	ErrPastSlotAlreadyDelivered              = errors.New("payload for past slot was already delivered")
+	ErrUnknownRuntimeConfigField             = errors.New("unknown runtime config field")
+	ErrNoBidToWithdraw                       = errors.New("builder has no bid for this slot, parent hash and proposer")
 
 	activeValidatorsHours  = cli.GetEnvInt("ACTIVE_VALIDATOR_HOURS", 3)
 	expiryActiveValidators = time.Duration(activeValidatorsHours) * time.Hour // careful with this setting - for each hour a hash set is created with each active proposer as field. for a lot of hours this can take a lot of space in redis.
@@ -91,20 +99,35 @@ type RedisCache struct {
 	prefixBlockBuilderLatestBids      string // latest bid for a given slot
 	prefixBlockBuilderLatestBidsValue string // value of latest bid for a given slot
 	prefixBlockBuilderLatestBidsTime  string // when the request was received, to avoid older requests overwriting newer ones after a slot validation
+	prefixBlockBuilderLatestBidsHash  string // block hash of latest bid for a given slot
 	prefixTopBidValue                 string
-	prefixFloorBid                    string
 	prefixFloorBidValue               string
+	prefixFloorBids                   string // highest non-cancellable bid of each builder for a given slot
+	prefixFloorBidValues              string
+	prefixFloorBidBlockHashes         string
+	prefixBuilderBlockHashes          string
+	prefixBlockSimResult              string
+	prefixOptimisticExposure          string
+	prefixDataAPIQuota                string
+	prefixWithdrawnBids               string
 
 	// keys
 	keyKnownValidators                string
 	keyValidatorRegistrationTimestamp string
 
 	keyRelayConfig        string
+	keyRuntimeConfig      string
 	keyStats              string
 	keyProposerDuties     string
 	keyBlockBuilderStatus string
 	keyLastSlotDelivered  string
 	keyLastHashDelivered  string
+
+	// pub/sub channels
+	channelBuilderStatusUpdates string
+	channelRuntimeConfigUpdates string
+	channelCacheRefreshRequests string
+	channelCacheRefreshResults  string
 }
 
 func NewRedisCache(prefix, redisURI, readonlyURI string) (*RedisCache, error) {
@@ -133,19 +156,33 @@ func NewRedisCache(prefix, redisURI, readonlyURI string) (*RedisCache, error) {
 		prefixBlockBuilderLatestBids:      fmt.Sprintf("%s/%s:block-builder-latest-bid", redisPrefix, prefix),       // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
 		prefixBlockBuilderLatestBidsValue: fmt.Sprintf("%s/%s:block-builder-latest-bid-value", redisPrefix, prefix), // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
 		prefixBlockBuilderLatestBidsTime:  fmt.Sprintf("%s/%s:block-builder-latest-bid-time", redisPrefix, prefix),  // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
+		prefixBlockBuilderLatestBidsHash:  fmt.Sprintf("%s/%s:block-builder-latest-bid-hash", redisPrefix, prefix),  // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
 		prefixTopBidValue:                 fmt.Sprintf("%s/%s:top-bid-value", redisPrefix, prefix),                  // prefix:slot_parentHash_proposerPubkey
-		prefixFloorBid:                    fmt.Sprintf("%s/%s:bid-floor", redisPrefix, prefix),                      // prefix:slot_parentHash_proposerPubkey
 		prefixFloorBidValue:               fmt.Sprintf("%s/%s:bid-floor-value", redisPrefix, prefix),                // prefix:slot_parentHash_proposerPubkey
+		prefixFloorBids:                   fmt.Sprintf("%s/%s:bid-floor-bids", redisPrefix, prefix),                 // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
+		prefixFloorBidBlockHashes:         fmt.Sprintf("%s/%s:bid-floor-block-hashes", redisPrefix, prefix),         // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
+		prefixFloorBidValues:              fmt.Sprintf("%s/%s:bid-floor-values", redisPrefix, prefix),               // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
+		prefixBuilderBlockHashes:          fmt.Sprintf("%s/%s:builder-block-hashes", redisPrefix, prefix),           // set of block hashes, prefix:slot_parentHash_proposerPubkey/builderPubkey
+		prefixBlockSimResult:              fmt.Sprintf("%s/%s:block-sim-result", redisPrefix, prefix),               // prefix:blockHash_feeRecipient_gasLimit_value
+		prefixOptimisticExposure:          fmt.Sprintf("%s/%s:optimistic-exposure", redisPrefix, prefix),            // prefix:slot_builder
+		prefixDataAPIQuota:                fmt.Sprintf("%s/%s:data-api-quota", redisPrefix, prefix),                 // prefix:subject_minute
+		prefixWithdrawnBids:               fmt.Sprintf("%s/%s:withdrawn-bids", redisPrefix, prefix),                 // set of block hashes, prefix:slot
 
 		keyKnownValidators:                fmt.Sprintf("%s/%s:known-validators", redisPrefix, prefix),
 		keyValidatorRegistrationTimestamp: fmt.Sprintf("%s/%s:validator-registration-timestamp", redisPrefix, prefix),
 		keyRelayConfig:                    fmt.Sprintf("%s/%s:relay-config", redisPrefix, prefix),
+		keyRuntimeConfig:                  fmt.Sprintf("%s/%s:runtime-config", redisPrefix, prefix), // hashmap with one field per setting
 
 		keyStats:              fmt.Sprintf("%s/%s:stats", redisPrefix, prefix),
 		keyProposerDuties:     fmt.Sprintf("%s/%s:proposer-duties", redisPrefix, prefix),
 		keyBlockBuilderStatus: fmt.Sprintf("%s/%s:block-builder-status", redisPrefix, prefix),
 		keyLastSlotDelivered:  fmt.Sprintf("%s/%s:last-slot-delivered", redisPrefix, prefix),
 		keyLastHashDelivered:  fmt.Sprintf("%s/%s:last-hash-delivered", redisPrefix, prefix),
+
+		channelBuilderStatusUpdates: fmt.Sprintf("%s/%s:builder-status-updates", redisPrefix, prefix),
+		channelRuntimeConfigUpdates: fmt.Sprintf("%s/%s:runtime-config-updates", redisPrefix, prefix),
+		channelCacheRefreshRequests: fmt.Sprintf("%s/%s:cache-refresh-requests", redisPrefix, prefix),
+		channelCacheRefreshResults:  fmt.Sprintf("%s/%s:cache-refresh-results", redisPrefix, prefix),
 	}, nil
 }
 
@@ -166,9 +203,14 @@ func (r *RedisCache) keyActiveValidators(t time.Time) string {
 	return fmt.Sprintf("%s:%s", r.prefixActiveValidators, t.UTC().Format("2006-01-02T15"))
 }
 
-// keyLatestBidByBuilder returns the key for the getHeader response the latest bid by a specific builder
-func (r *RedisCache) keyLatestBidByBuilder(slot uint64, parentHash, proposerPubkey, builderPubkey string) string {
-	return fmt.Sprintf("%s:%d_%s_%s/%s", r.prefixBlockBuilderLatestBids, slot, parentHash, proposerPubkey, builderPubkey)
+// keyBlockBuilderLatestBids returns the hashmap key for the getHeader response of the latest bid by a specific builder
+func (r *RedisCache) keyBlockBuilderLatestBids(slot uint64, parentHash, proposerPubkey string) string {
+	return fmt.Sprintf("%s:%d_%s_%s", r.prefixBlockBuilderLatestBids, slot, parentHash, proposerPubkey)
+}
+
+// keyBlockBuilderLatestBidsBlockHash returns the hashmap key for the block hash of the latest bid by a specific builder
+func (r *RedisCache) keyBlockBuilderLatestBidsBlockHash(slot uint64, parentHash, proposerPubkey string) string {
+	return fmt.Sprintf("%s:%d_%s_%s", r.prefixBlockBuilderLatestBidsHash, slot, parentHash, proposerPubkey)
 }
 
 // keyBlockBuilderLatestBidValue returns the hashmap key for the value of the latest bid by a specific builder
@@ -186,16 +228,51 @@ func (r *RedisCache) keyTopBidValue(slot uint64, parentHash, proposerPubkey stri
 	return fmt.Sprintf("%s:%d_%s_%s", r.prefixTopBidValue, slot, parentHash, proposerPubkey)
 }
 
-// keyFloorBid returns the key for the highest non-cancellable bid of a given slot+parentHash+proposerPubkey
-func (r *RedisCache) keyFloorBid(slot uint64, parentHash, proposerPubkey string) string {
-	return fmt.Sprintf("%s:%d_%s_%s", r.prefixFloorBid, slot, parentHash, proposerPubkey)
-}
-
 // keyFloorBidValue returns the key for the highest non-cancellable value of a given slot+parentHash+proposerPubkey
 func (r *RedisCache) keyFloorBidValue(slot uint64, parentHash, proposerPubkey string) string {
 	return fmt.Sprintf("%s:%d_%s_%s", r.prefixFloorBidValue, slot, parentHash, proposerPubkey)
 }
 
+// keyFloorBids returns the hashmap key for the highest non-cancellable bid of each builder
+func (r *RedisCache) keyFloorBids(slot uint64, parentHash, proposerPubkey string) string {
+	return fmt.Sprintf("%s:%d_%s_%s", r.prefixFloorBids, slot, parentHash, proposerPubkey)
+}
+
+// keyFloorBidBlockHashes returns the hashmap key for the block hash of the highest non-cancellable bid of each builder
+func (r *RedisCache) keyFloorBidBlockHashes(slot uint64, parentHash, proposerPubkey string) string {
+	return fmt.Sprintf("%s:%d_%s_%s", r.prefixFloorBidBlockHashes, slot, parentHash, proposerPubkey)
+}
+
+// keyFloorBidValues returns the hashmap key for the value of the highest non-cancellable bid of each builder
+func (r *RedisCache) keyFloorBidValues(slot uint64, parentHash, proposerPubkey string) string {
+	return fmt.Sprintf("%s:%d_%s_%s", r.prefixFloorBidValues, slot, parentHash, proposerPubkey)
+}
+
+// keyBuilderBlockHashes returns the key for the block hashes of all bids of a builder
+func (r *RedisCache) keyBuilderBlockHashes(slot uint64, parentHash, proposerPubkey, builderPubkey string) string {
+	return fmt.Sprintf("%s:%d_%s_%s/%s", r.prefixBuilderBlockHashes, slot, parentHash, proposerPubkey, builderPubkey)
+}
+
+// keyBlockSimResult returns the key for the cached simulation result of a block
+func (r *RedisCache) keyBlockSimResult(blockHash, feeRecipient string, registeredGasLimit uint64, value string) string {
+	return fmt.Sprintf("%s:%s_%s_%d_%s", r.prefixBlockSimResult, blockHash, strings.ToLower(feeRecipient), registeredGasLimit, value)
+}
+
+// keyOptimisticExposure returns the key for the unverified optimistic value (in gwei) of a builder in a slot
+func (r *RedisCache) keyOptimisticExposure(slot uint64, builder string) string {
+	return fmt.Sprintf("%s:%d_%s", r.prefixOptimisticExposure, slot, builder)
+}
+
+// keyWithdrawnBids returns the key for the block hashes withdrawn from the auction of a slot
+func (r *RedisCache) keyWithdrawnBids(slot uint64) string {
+	return fmt.Sprintf("%s:%d", r.prefixWithdrawnBids, slot)
+}
+
+// keyDataAPIQuota returns the key for the number of data API requests of an API key or IP in a minute
+func (r *RedisCache) keyDataAPIQuota(subject string, minute int64) string {
+	return fmt.Sprintf("%s:%s_%d", r.prefixDataAPIQuota, subject, minute)
+}
+
 func (r *RedisCache) GetObj(key string, obj any) (err error) {
 	value, err := r.client.Get(context.Background(), key).Result()
 	if err != nil {
@@ -457,144 +534,240 @@ func (r *RedisCache) GetBuilderLatestPayloadReceivedAt(slot uint64, builderPubke
 	return timestamp, err
 }
 
-// SaveBuilderBid saves the latest bid by a specific builder. TODO: use transaction to make these writes atomic
+// SaveBuilderBid saves the latest bid by a specific builder, without updating the top bid. Bids saved this way
+// can only be withdrawn by builder pubkey.
 func (r *RedisCache) SaveBuilderBid(slot uint64, parentHash, proposerPubkey, builderPubkey string, receivedAt time.Time, headerResp *common.GetHeaderResponse) (err error) {
-	// save the actual bid
-	keyLatestBid := r.keyLatestBidByBuilder(slot, parentHash, proposerPubkey, builderPubkey)
-	err = r.SetObj(keyLatestBid, headerResp, expiryBidCache)
+	bid, err := json.Marshal(headerResp)
 	if err != nil {
 		return err
 	}
 
-	// set the time of the request
+	keyLatestBids := r.keyBlockBuilderLatestBids(slot, parentHash, proposerPubkey)
 	keyLatestBidsTime := r.keyBlockBuilderLatestBidsTime(slot, parentHash, proposerPubkey)
-	err = r.client.HSet(context.Background(), keyLatestBidsTime, builderPubkey, receivedAt.UnixMilli()).Err()
-	if err != nil {
-		return err
-	}
-	err = r.client.Expire(context.Background(), keyLatestBidsTime, expiryBidCache).Err()
-	if err != nil {
-		return err
-	}
-
-	// set the value last, because that's iterated over when updating the best bid, and the payload has to be available
 	keyLatestBidsValue := r.keyBlockBuilderLatestBidsValue(slot, parentHash, proposerPubkey)
-	err = r.client.HSet(context.Background(), keyLatestBidsValue, builderPubkey, headerResp.Value().String()).Err()
-	if err != nil {
-		return err
-	}
-	return r.client.Expire(context.Background(), keyLatestBidsValue, expiryBidCache).Err()
+	_, err = r.client.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		// save the actual bid and the time of the request
+		pipe.HSet(context.Background(), keyLatestBids, builderPubkey, bid)
+		pipe.Expire(context.Background(), keyLatestBids, expiryBidCache)
+		pipe.HSet(context.Background(), keyLatestBidsTime, builderPubkey, receivedAt.UnixMilli())
+		pipe.Expire(context.Background(), keyLatestBidsTime, expiryBidCache)
+
+		// the block hash of a previous bid no longer belongs to the latest bid
+		pipe.HDel(context.Background(), r.keyBlockBuilderLatestBidsBlockHash(slot, parentHash, proposerPubkey), builderPubkey)
+
+		// set the value last, because that's iterated over when updating the best bid, and the payload has to be available
+		pipe.HSet(context.Background(), keyLatestBidsValue, builderPubkey, headerResp.Value().String())
+		pipe.Expire(context.Background(), keyLatestBidsValue, expiryBidCache)
+		return nil
+	})
+	return err
 }
 
 type SaveBidAndUpdateTopBidResponse struct {
 	WasBidSaved      bool // Whether this bid was saved
 	WasTopBidUpdated bool // Whether the top bid was updated
 	IsNewTopBid      bool // Whether the submitted bid became the new top bid
+	IsWithdrawn      bool // Whether the block was withdrawn from the auction, and the bid not saved
 
 	TopBidValue     *big.Int
 	PrevTopBidValue *big.Int
 }
 
-func (r *RedisCache) SaveBidAndUpdateTopBid(payload *common.BuilderSubmitBlockRequest, getPayloadResponse *common.GetPayloadResponse, getHeaderResponse *common.GetHeaderResponse, reqReceivedAt time.Time, isCancellationEnabled bool, floorValue *big.Int) (state SaveBidAndUpdateTopBidResponse, err error) {
-	// 1. Load latest bids for a given slot+parent+proposer
-	keyBidValues := r.keyBlockBuilderLatestBidsValue(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey())
-	bidValueMap, err := r.client.HGetAll(context.Background(), keyBidValues).Result()
-	if err != nil {
-		return state, err
-	}
-
-	if floorValue == nil {
-		floorValue, err = r.GetFloorBidValue(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey())
-		if err != nil {
-			return state, err
-		}
-	}
-
-	builderBids := NewBuilderBids(bidValueMap)
-	_, state.PrevTopBidValue = builderBids.getTopBid()
-	state.TopBidValue = state.PrevTopBidValue
-
-	// 2. Do we even need to continue / save the new payload and update the top bid?
-	// - In cancellation mode: always continue to saving latest bid
-	// - In non-cancellation mode: only save if current bid is higher value than floor value
-	if !isCancellationEnabled && payload.Value().Cmp(floorValue) < 1 {
-		return state, nil
-	}
-
-	// Time to save things in Redis
-	// 1. Save the execution payload
-	err = r.SaveExecutionPayload(payload.Slot(), payload.ProposerPubkey(), payload.BlockHash(), getPayloadResponse)
+// luaBidHelpers are the functions shared by the bid scripts. Bid values are compared as decimal strings,
+// because they don't fit into Lua numbers.
+const luaBidHelpers = `
+local function gt(a, b)
+	if #a ~= #b then
+		return #a > #b
+	end
+	return a > b
+end
+
+-- getTopBid returns the builder with the highest value of a HGETALL reply, and the value ("0" if empty)
+local function getTopBid(bidValues)
+	local topBuilder, topValue = "", "0"
+	for i = 1, #bidValues, 2 do
+		if gt(bidValues[i + 1], topValue) then
+			topBuilder, topValue = bidValues[i], bidValues[i + 1]
+		end
+	end
+	return topBuilder, topValue
+end
+
+-- hset sets a field of a hashmap, and renews the expiry of the hashmap
+local function hset(key, field, value, expiry)
+	redis.call("HSET", key, field, value)
+	redis.call("PEXPIRE", key, expiry)
+end
+
+-- toMap returns the fields and values of a HGETALL reply as a table
+local function toMap(reply)
+	local m = {}
+	for i = 1, #reply, 2 do
+		m[reply[i]] = reply[i + 1]
+	end
+	return m
+end
+`
+
+// saveBidAndUpdateTopBidScript saves a bid and updates the top bid and floor in one step, so concurrent
+// submissions (from any replica) can't interleave and publish a top bid that isn't the highest. The floor
+// is the highest of the non-cancellable bids, which are kept for each builder.
+//
+// All keys the script accesses are passed in KEYS: the bids of all builders are fields of hashmaps.
+//
+// KEYS: latest bid values, latest bid times, latest bids, latest bid block hashes, floor bid value,
+// top bid, top bid value, withdrawn bids, execution payload, floor bids, floor bid values, floor bid block
+// hashes, block hashes of this builder
+// ARGV: builder pubkey, bid value, block hash, bid, execution payload, received at (ms), cancellation
+// enabled ("1"), expiry (ms)
+//
+// Returns: was bid saved, was top bid updated, is new top bid, is withdrawn, top bid value, prev top bid value
+var saveBidAndUpdateTopBidScript = redis.NewScript(luaBidHelpers + `
+local keyBidValues, keyBidTimes, keyLatestBids, keyLatestBlockHashes, keyFloorBidValue, keyTopBid, keyTopBidValue, keyWithdrawnBids, keyPayload, keyFloorBids, keyFloorBidValues, keyFloorBlockHashes, keyBuilderBlockHashes = unpack(KEYS)
+local builder, value, blockHash, bid, payload, receivedAt, isCancellationEnabled, expiry = unpack(ARGV)
+
+-- 1. Load latest bids and the floor for a given slot+parent+proposer
+local bidValues = redis.call("HGETALL", keyBidValues)
+local floorBuilder, floorValue = getTopBid(redis.call("HGETALL", keyFloorBidValues))
+local _, prevTopBidValue = getTopBid(bidValues)
+
+-- 2. Do we even need to continue / save the new payload and update the top bid?
+-- - A withdrawn block can't be submitted again
+-- - In cancellation mode: always continue to saving latest bid
+-- - In non-cancellation mode: only save if current bid is higher value than floor value
+if redis.call("SISMEMBER", keyWithdrawnBids, blockHash) == 1 then
+	return {0, 0, 0, 1, prevTopBidValue, prevTopBidValue}
+end
+if isCancellationEnabled ~= "1" and not gt(value, floorValue) then
+	return {0, 0, 0, 0, prevTopBidValue, prevTopBidValue}
+end
+
+-- 3. Save the execution payload and the latest bid for this builder, and remember the block hash to
+-- be able to withdraw all its bids
+redis.call("SET", keyPayload, payload, "PX", expiry)
+hset(keyLatestBids, builder, bid, expiry)
+hset(keyLatestBlockHashes, builder, blockHash, expiry)
+hset(keyBidTimes, builder, receivedAt, expiry)
+hset(keyBidValues, builder, value, expiry)
+redis.call("SADD", keyBuilderBlockHashes, blockHash)
+redis.call("PEXPIRE", keyBuilderBlockHashes, expiry)
+
+-- 4. Update this builders latest bid, and only proceed to update the top bid if it changed
+local found = false
+for i = 1, #bidValues, 2 do
+	if bidValues[i] == builder then
+		bidValues[i + 1] = value
+		found = true
+	end
+end
+if not found then
+	table.insert(bidValues, builder)
+	table.insert(bidValues, value)
+end
+local topBidBuilder, topBidValue = getTopBid(bidValues)
+if topBidValue == prevTopBidValue then
+	return {1, 0, 0, 0, topBidValue, prevTopBidValue}
+end
+
+-- If floor value is higher than this bid, use floor bid instead
+local topBid
+if gt(floorValue, topBidValue) then
+	topBidValue = floorValue
+	topBid = redis.call("HGET", keyFloorBids, floorBuilder)
+else
+	topBid = redis.call("HGET", keyLatestBids, topBidBuilder)
+end
+if not topBid then
+	return redis.error_reply("missing bid of builder " .. topBidBuilder)
+end
+
+-- 5. Save the winning bid as top bid, and update the global top bid value
+redis.call("SET", keyTopBid, topBid, "PX", expiry)
+redis.call("SET", keyTopBidValue, topBidValue, "PX", expiry)
+
+-- 6. If non-cancelling, perhaps set a new bid floor. The floor bid of each builder is kept as well,
+-- so the floor can be recomputed when bids are withdrawn.
+if isCancellationEnabled ~= "1" and gt(value, floorValue) then
+	hset(keyFloorBids, builder, bid, expiry)
+	hset(keyFloorBlockHashes, builder, blockHash, expiry)
+	hset(keyFloorBidValues, builder, value, expiry)
+	redis.call("SET", keyFloorBidValue, value, "PX", expiry)
+end
+
+local wasTopBidUpdated, isNewTopBid = 0, 0
+if topBidValue ~= prevTopBidValue then
+	wasTopBidUpdated = 1
+end
+if value == topBidValue then
+	isNewTopBid = 1
+end
+return {1, wasTopBidUpdated, isNewTopBid, 0, topBidValue, prevTopBidValue}
+`)
+
+// SaveBidAndUpdateTopBid saves the execution payload and the bid of a builder, and updates the top bid and
+// the floor (the highest non-cancellable bid) for the slot+parent+proposer. This is done atomically, in a
+// single Lua script, which also loads the floor value.
+func (r *RedisCache) SaveBidAndUpdateTopBid(payload *common.BuilderSubmitBlockRequest, getPayloadResponse *common.GetPayloadResponse, getHeaderResponse *common.GetHeaderResponse, reqReceivedAt time.Time, isCancellationEnabled bool) (state SaveBidAndUpdateTopBidResponse, err error) {
+	slot, parentHash, proposerPubkey := payload.Slot(), payload.ParentHash(), payload.ProposerPubkey()
+	builderPubkey := payload.BuilderPubkey().String()
+
+	bid, err := json.Marshal(getHeaderResponse)
 	if err != nil {
 		return state, err
 	}
-
-	// 2. Save latest bid for this builder
-	err = r.SaveBuilderBid(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey(), payload.BuilderPubkey().String(), reqReceivedAt, getHeaderResponse)
+	executionPayload, err := json.Marshal(getPayloadResponse)
 	if err != nil {
 		return state, err
 	}
-	state.WasBidSaved = true
-
-	// 3. Update this builders latest bid in local cache
-	builderBids.bidValues[payload.BuilderPubkey().String()] = payload.Value()
-	topBidBuilder := ""
-	topBidBuilder, state.TopBidValue = builderBids.getTopBid()
-	keyBidSource := r.keyLatestBidByBuilder(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey(), topBidBuilder)
 
-	// 4. Only proceed to update top bid in redis if it changed in local cache
-	if state.TopBidValue.Cmp(state.PrevTopBidValue) == 0 {
-		return state, nil
+	keys := []string{
+		r.keyBlockBuilderLatestBidsValue(slot, parentHash, proposerPubkey),
+		r.keyBlockBuilderLatestBidsTime(slot, parentHash, proposerPubkey),
+		r.keyBlockBuilderLatestBids(slot, parentHash, proposerPubkey),
+		r.keyBlockBuilderLatestBidsBlockHash(slot, parentHash, proposerPubkey),
+		r.keyFloorBidValue(slot, parentHash, proposerPubkey),
+		r.keyCacheGetHeaderResponse(slot, parentHash, proposerPubkey),
+		r.keyTopBidValue(slot, parentHash, proposerPubkey),
+		r.keyWithdrawnBids(slot),
+		r.keyCacheGetPayloadResponse(slot, proposerPubkey, payload.BlockHash()),
+		r.keyFloorBids(slot, parentHash, proposerPubkey),
+		r.keyFloorBidValues(slot, parentHash, proposerPubkey),
+		r.keyFloorBidBlockHashes(slot, parentHash, proposerPubkey),
+		r.keyBuilderBlockHashes(slot, parentHash, proposerPubkey, builderPubkey),
 	}
-
-	// If floor value is higher than this bid, use floor bid instead
-	if floorValue.Cmp(state.TopBidValue) == 1 {
-		state.TopBidValue = floorValue
-		keyBidSource = r.keyFloorBid(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey())
+	cancellationArg := "0"
+	if isCancellationEnabled {
+		cancellationArg = "1"
 	}
-
-	// 5. Copy winning bid to top bid cache
-	keyTopBid := r.keyCacheGetHeaderResponse(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey())
-	wasCopied, err := r.client.Copy(context.Background(), keyBidSource, keyTopBid, 0, true).Result()
-	if err != nil {
-		return state, err
-	} else if wasCopied == 0 {
-		return state, fmt.Errorf("could not copy %s to %s", keyBidSource, keyTopBid) //nolint:goerr113
-	}
-	err = r.client.Expire(context.Background(), keyTopBid, expiryBidCache).Err()
-	if err != nil {
-		return state, err
+	args := []any{
+		builderPubkey,
+		payload.Value().String(),
+		strings.ToLower(payload.BlockHash()),
+		bid,
+		executionPayload,
+		reqReceivedAt.UnixMilli(),
+		cancellationArg,
+		expiryBidCache.Milliseconds(),
 	}
 
-	state.WasTopBidUpdated = state.PrevTopBidValue.Cmp(state.TopBidValue) != 0
-	state.IsNewTopBid = payload.Value().Cmp(state.TopBidValue) == 0
-
-	// 6. Finally, update the global top bid value
-	keyTopBidValue := r.keyTopBidValue(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey())
-	err = r.client.Set(context.Background(), keyTopBidValue, state.TopBidValue.String(), expiryBidCache).Err()
+	res, err := saveBidAndUpdateTopBidScript.Run(context.Background(), r.client, keys, args...).Slice()
 	if err != nil {
 		return state, err
+	} else if len(res) != 6 {
+		return state, fmt.Errorf("unexpected response from save bid script: %v", res) //nolint:goerr113
 	}
 
-	// 7. If non-cancelling, perhaps set a new bid floor
-	if !isCancellationEnabled && payload.Value().Cmp(floorValue) == 1 {
-		keyBidSource := r.keyLatestBidByBuilder(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey(), payload.BuilderPubkey().String())
-		keyFloorBid := r.keyFloorBid(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey())
-		wasCopied, copyErr := r.client.Copy(context.Background(), keyBidSource, keyFloorBid, 0, true).Result()
-		if copyErr != nil {
-			return state, copyErr
-		} else if wasCopied == 0 {
-			return state, fmt.Errorf("could not copy %s to %s", keyBidSource, keyTopBid) //nolint:goerr113
-		}
-		err = r.client.Expire(context.Background(), keyFloorBid, expiryBidCache).Err()
-		if err != nil {
-			return state, err
-		}
-
-		keyFloorBidValue := r.keyFloorBidValue(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey())
-		err = r.client.Set(context.Background(), keyFloorBidValue, payload.Value().String(), expiryBidCache).Err()
+	state.WasBidSaved = res[0] == int64(1)
+	state.WasTopBidUpdated = res[1] == int64(1)
+	state.IsNewTopBid = res[2] == int64(1)
+	state.IsWithdrawn = res[3] == int64(1)
+	state.TopBidValue, _ = new(big.Int).SetString(fmt.Sprint(res[4]), 10)
+	state.PrevTopBidValue, _ = new(big.Int).SetString(fmt.Sprint(res[5]), 10)
+	if state.TopBidValue == nil || state.PrevTopBidValue == nil {
+		return state, fmt.Errorf("invalid bid values from save bid script: %v", res) //nolint:goerr113
 	}
-
-	return state, err
+	return state, nil
 }
 
 // GetTopBidValue gets the top bid value for a given slot+parent+proposer combination
@@ -641,3 +814,440 @@ func (r *RedisCache) GetFloorBidValue(slot uint64, parentHash, proposerPubkey st
 	floorValue.SetString(topBidValueStr, 10)
 	return floorValue, nil
 }
+
+type WithdrawBidResponse struct {
+	WithdrawnBuilders    []string // Builders whose latest or floor bid was removed
+	WithdrawnBlockHashes []string // Block hashes that getPayload is refused for
+	WasFloorBidRemoved   bool     // Whether the floor bid was withdrawn
+
+	TopBidValue     *big.Int
+	PrevTopBidValue *big.Int
+}
+
+// withdrawBidScript removes bids from the auction and recomputes the floor and the top bid in one step,
+// so it can't interleave with the submissions of any replica. Like saveBidAndUpdateTopBidScript, all
+// keys the script accesses are passed in KEYS.
+//
+// KEYS: latest bid values, latest bid times, latest bids, latest bid block hashes, floor bid value, top bid,
+// top bid value, withdrawn bids, floor bids, floor bid values, floor bid block hashes, block hashes of the builder
+// ARGV: builder pubkey ("" to withdraw by block hash), block hash, expiry (ms), withdrawn bids expiry (ms)
+//
+// Returns: was a bid found, was floor bid removed, top bid value, prev top bid value, withdrawn builders,
+// withdrawn block hashes
+var withdrawBidScript = redis.NewScript(luaBidHelpers + `
+local keyBidValues, keyBidTimes, keyLatestBids, keyLatestBlockHashes, keyFloorBidValue, keyTopBid, keyTopBidValue, keyWithdrawnBids, keyFloorBids, keyFloorBidValues, keyFloorBlockHashes, keyBuilderBlockHashes = unpack(KEYS)
+local builder, blockHash, expiry, expiryWithdrawnBids = unpack(ARGV)
+
+local prevTopBidValue = redis.call("GET", keyTopBidValue) or "0"
+local bidValues = toMap(redis.call("HGETALL", keyBidValues))
+local floorValues = toMap(redis.call("HGETALL", keyFloorBidValues))
+local latestBlockHashes = toMap(redis.call("HGETALL", keyLatestBlockHashes))
+local floorBlockHashes = toMap(redis.call("HGETALL", keyFloorBlockHashes))
+local floorBuilder = getTopBid(redis.call("HGETALL", keyFloorBidValues))
+
+-- 1. Find the bids to withdraw: all bids of the builder, or the latest and floor bids with the block hash
+local withdrawnLatestBids, withdrawnFloorBids, withdrawnBlockHashes = {}, {}, {}
+if builder ~= "" then
+	withdrawnBlockHashes = redis.call("SMEMBERS", keyBuilderBlockHashes)
+	if not bidValues[builder] and not floorValues[builder] and #withdrawnBlockHashes == 0 then
+		return {0, 0, prevTopBidValue, prevTopBidValue, {}, {}}
+	end
+	withdrawnLatestBids[builder] = bidValues[builder] ~= nil
+	withdrawnFloorBids[builder] = floorValues[builder] ~= nil
+else
+	withdrawnBlockHashes = {blockHash}
+	for b, h in pairs(latestBlockHashes) do
+		withdrawnLatestBids[b] = h == blockHash
+	end
+	for b, h in pairs(floorBlockHashes) do
+		withdrawnFloorBids[b] = h == blockHash
+	end
+end
+
+local withdrawnBuilders, isWithdrawnBuilder, wasFloorBidRemoved = {}, {}, 0
+for _, withdrawn in ipairs({withdrawnLatestBids, withdrawnFloorBids}) do
+	for b, isWithdrawn in pairs(withdrawn) do
+		if isWithdrawn and not isWithdrawnBuilder[b] then
+			isWithdrawnBuilder[b] = true
+			table.insert(withdrawnBuilders, b)
+		end
+	end
+end
+for _, h in ipairs(withdrawnBlockHashes) do
+	if floorBuilder ~= "" and floorBlockHashes[floorBuilder] == h then
+		wasFloorBidRemoved = 1
+	end
+end
+
+-- 2. Record the withdrawn block hashes, so they can't be submitted again and getPayload refuses them
+if #withdrawnBlockHashes > 0 then
+	redis.call("SADD", keyWithdrawnBids, unpack(withdrawnBlockHashes))
+	redis.call("PEXPIRE", keyWithdrawnBids, expiryWithdrawnBids)
+end
+
+-- 3. Remove the bids
+for b, isWithdrawn in pairs(withdrawnLatestBids) do
+	if isWithdrawn then
+		for _, key in ipairs({keyBidValues, keyBidTimes, keyLatestBids, keyLatestBlockHashes}) do
+			redis.call("HDEL", key, b)
+		end
+	end
+end
+for b, isWithdrawn in pairs(withdrawnFloorBids) do
+	if isWithdrawn then
+		for _, key in ipairs({keyFloorBids, keyFloorBidValues, keyFloorBlockHashes}) do
+			redis.call("HDEL", key, b)
+		end
+	end
+end
+if builder ~= "" then
+	redis.call("DEL", keyBuilderBlockHashes)
+end
+
+-- 4. Recompute the floor from the floor bids of the remaining builders
+local floorValue
+floorBuilder, floorValue = getTopBid(redis.call("HGETALL", keyFloorBidValues))
+if floorValue == "0" then
+	redis.call("DEL", keyFloorBidValue)
+else
+	redis.call("SET", keyFloorBidValue, floorValue, "PX", expiry)
+end
+
+-- 5. Recompute the top bid from the latest bids of the remaining builders and the floor
+local topBidBuilder, topBidValue = getTopBid(redis.call("HGETALL", keyBidValues))
+local keyTopBidSource, topBidSourceBuilder = keyLatestBids, topBidBuilder
+if gt(floorValue, topBidValue) then
+	topBidValue = floorValue
+	keyTopBidSource, topBidSourceBuilder = keyFloorBids, floorBuilder
+end
+if topBidValue == "0" then
+	-- No bids left
+	redis.call("DEL", keyTopBid, keyTopBidValue)
+else
+	local topBid = redis.call("HGET", keyTopBidSource, topBidSourceBuilder)
+	if not topBid then
+		return redis.error_reply("missing bid of builder " .. topBidSourceBuilder)
+	end
+	redis.call("SET", keyTopBid, topBid, "PX", expiry)
+	redis.call("SET", keyTopBidValue, topBidValue, "PX", expiry)
+end
+return {1, wasFloorBidRemoved, topBidValue, prevTopBidValue, withdrawnBuilders, withdrawnBlockHashes}
+`)
+
+// WithdrawBid removes all bids of a builder, or the bids with a block hash, from the auction for a
+// slot+parent+proposer. Exactly one of builderPubkey and blockHash is used. The floor is then recomputed
+// from the highest non-cancellable bids of the remaining builders, and the top bid from their latest bids
+// and the floor. This is done atomically, in a single Lua script.
+//
+// The withdrawn block hashes are recorded for the slot, so they can't be submitted again and getPayload
+// can refuse them. Withdrawing by builder pubkey withdraws every block the builder submitted for the
+// slot+parent+proposer, including earlier bids which may have been returned by getHeader before.
+func (r *RedisCache) WithdrawBid(slot uint64, parentHash, proposerPubkey, builderPubkey, blockHash string) (state WithdrawBidResponse, err error) {
+	builderPubkey = strings.ToLower(builderPubkey)
+	blockHash = strings.ToLower(blockHash)
+
+	keys := []string{
+		r.keyBlockBuilderLatestBidsValue(slot, parentHash, proposerPubkey),
+		r.keyBlockBuilderLatestBidsTime(slot, parentHash, proposerPubkey),
+		r.keyBlockBuilderLatestBids(slot, parentHash, proposerPubkey),
+		r.keyBlockBuilderLatestBidsBlockHash(slot, parentHash, proposerPubkey),
+		r.keyFloorBidValue(slot, parentHash, proposerPubkey),
+		r.keyCacheGetHeaderResponse(slot, parentHash, proposerPubkey),
+		r.keyTopBidValue(slot, parentHash, proposerPubkey),
+		r.keyWithdrawnBids(slot),
+		r.keyFloorBids(slot, parentHash, proposerPubkey),
+		r.keyFloorBidValues(slot, parentHash, proposerPubkey),
+		r.keyFloorBidBlockHashes(slot, parentHash, proposerPubkey),
+		r.keyBuilderBlockHashes(slot, parentHash, proposerPubkey, builderPubkey),
+	}
+	args := []any{
+		builderPubkey,
+		blockHash,
+		expiryBidCache.Milliseconds(),
+		expiryWithdrawnBids.Milliseconds(),
+	}
+
+	res, err := withdrawBidScript.Run(context.Background(), r.client, keys, args...).Slice()
+	if err != nil {
+		return state, err
+	} else if len(res) != 6 {
+		return state, fmt.Errorf("unexpected response from withdraw bid script: %v", res) //nolint:goerr113
+	} else if res[0] != int64(1) {
+		return state, ErrNoBidToWithdraw
+	}
+
+	state.WasFloorBidRemoved = res[1] == int64(1)
+	state.TopBidValue, _ = new(big.Int).SetString(fmt.Sprint(res[2]), 10)
+	state.PrevTopBidValue, _ = new(big.Int).SetString(fmt.Sprint(res[3]), 10)
+	if state.TopBidValue == nil || state.PrevTopBidValue == nil {
+		return state, fmt.Errorf("invalid bid values from withdraw bid script: %v", res) //nolint:goerr113
+	}
+	state.WithdrawnBuilders = luaStrings(res[4])
+	state.WithdrawnBlockHashes = luaStrings(res[5])
+	sort.Strings(state.WithdrawnBuilders)
+	sort.Strings(state.WithdrawnBlockHashes)
+	return state, nil
+}
+
+// luaStrings returns the strings of an array in a Lua script reply
+func luaStrings(reply any) []string {
+	ret := []string{}
+	values, _ := reply.([]any)
+	for _, value := range values {
+		ret = append(ret, fmt.Sprint(value))
+	}
+	return ret
+}
+
+// IsBidWithdrawn returns whether a block hash was withdrawn from the auction for a slot
+func (r *RedisCache) IsBidWithdrawn(slot uint64, blockHash string) (bool, error) {
+	return r.client.SIsMember(context.Background(), r.keyWithdrawnBids(slot), strings.ToLower(blockHash)).Result()
+}
+
+// BlockSimResult is the cached verdict of the block simulator. Error is empty if the block is valid.
+type BlockSimResult struct {
+	Error string `json:"error"`
+}
+
+// GetBlockSimResult returns the cached simulation result for a block, or nil if there is none
+func (r *RedisCache) GetBlockSimResult(blockHash, feeRecipient string, registeredGasLimit uint64, value string) (*BlockSimResult, error) {
+	if expiryBlockSimResult <= 0 {
+		return nil, nil
+	}
+	result := new(BlockSimResult)
+	err := r.GetObj(r.keyBlockSimResult(blockHash, feeRecipient, registeredGasLimit, value), result)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return result, err
+}
+
+// SaveBlockSimResult caches a simulation result for a short time, so identical submissions don't need to be simulated again
+func (r *RedisCache) SaveBlockSimResult(blockHash, feeRecipient string, registeredGasLimit uint64, value string, result *BlockSimResult) error {
+	if expiryBlockSimResult <= 0 {
+		return nil
+	}
+	return r.SetObj(r.keyBlockSimResult(blockHash, feeRecipient, registeredGasLimit, value), result, expiryBlockSimResult)
+}
+
+// Kinds of builder status updates
+const (
+	BuilderStatusUpdateStatus     = "status"     // high-prio, blacklisted and optimistic flags of one pubkey
+	BuilderStatusUpdateOptimistic = "optimistic" // optimistic flag of all pubkeys with the builder id (i.e. demotions)
+	BuilderStatusUpdateCollateral = "collateral" // builder id and collateral of one pubkey
+)
+
+// BuilderStatusUpdate is published to all API replicas when the status of a builder changes,
+// so they can update their builder cache without waiting for the next slot
+type BuilderStatusUpdate struct {
+	Kind          string `json:"kind"`
+	BuilderPubkey string `json:"builder_pubkey"`
+	BuilderID     string `json:"builder_id"`
+	IsHighPrio    bool   `json:"is_high_prio"`
+	IsBlacklisted bool   `json:"is_blacklisted"`
+	IsOptimistic  bool   `json:"is_optimistic"`
+	Collateral    string `json:"collateral"`
+}
+
+func (r *RedisCache) PublishBuilderStatusUpdate(update *BuilderStatusUpdate) error {
+	msg, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(context.Background(), r.channelBuilderStatusUpdates, msg).Err()
+}
+
+// SubscribeBuilderStatusUpdates subscribes to the builder status updates of all replicas.
+// The subscription reconnects automatically, and is closed when the context is done.
+func (r *RedisCache) SubscribeBuilderStatusUpdates(ctx context.Context) *redis.PubSub {
+	pubsub := r.client.Subscribe(ctx, r.channelBuilderStatusUpdates)
+	go func() {
+		<-ctx.Done()
+		_ = pubsub.Close()
+	}()
+	return pubsub
+}
+
+// CacheRefreshRequest asks all API replicas to reload one of their in-memory caches
+type CacheRefreshRequest struct {
+	ID    string `json:"id"`
+	Cache string `json:"cache"`
+}
+
+// CacheRefreshResult is published by each replica after it handled a cache refresh request
+type CacheRefreshResult struct {
+	ID      string          `json:"id"`
+	Replica string          `json:"replica"`
+	Diff    json.RawMessage `json:"diff,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// PublishCacheRefreshRequest sends the request to all replicas, and returns how many received it
+func (r *RedisCache) PublishCacheRefreshRequest(req *CacheRefreshRequest) (numReplicas int64, err error) {
+	msg, err := json.Marshal(req)
+	if err != nil {
+		return 0, err
+	}
+	return r.client.Publish(context.Background(), r.channelCacheRefreshRequests, msg).Result()
+}
+
+// SubscribeCacheRefreshRequests subscribes to the cache refresh requests. The subscription is closed when the context is done.
+func (r *RedisCache) SubscribeCacheRefreshRequests(ctx context.Context) *redis.PubSub {
+	pubsub := r.client.Subscribe(ctx, r.channelCacheRefreshRequests)
+	go func() {
+		<-ctx.Done()
+		_ = pubsub.Close()
+	}()
+	return pubsub
+}
+
+func (r *RedisCache) PublishCacheRefreshResult(result *CacheRefreshResult) error {
+	msg, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(context.Background(), r.channelCacheRefreshResults, msg).Err()
+}
+
+// SubscribeCacheRefreshResults subscribes to the results of the cache refresh requests. The subscription
+// is closed when the context is done.
+func (r *RedisCache) SubscribeCacheRefreshResults(ctx context.Context) *redis.PubSub {
+	pubsub := r.client.Subscribe(ctx, r.channelCacheRefreshResults)
+	go func() {
+		<-ctx.Done()
+		_ = pubsub.Close()
+	}()
+	return pubsub
+}
+
+// RuntimeConfig are the relay settings that can be changed without a redeploy. They are shared by all
+// replicas through Redis, with one hash field per setting (the JSON field name and value).
+type RuntimeConfig struct {
+	StopServingBids            bool `json:"stop_serving_bids"` // kill switch, getHeader always returns 204
+	DisableLowPrioBuilders     bool `json:"disable_lowprio_builders"`
+	DisablePayloadDBStorage    bool `json:"disable_payload_database_storage"`
+	LogInvalidSignaturePayload bool `json:"log_invalid_getpayload_signature"`
+	EnableBuilderCancellations bool `json:"enable_builder_cancellations"`
+	RegValContinueOnInvalidSig bool `json:"register_validator_continue_on_invalid_sig"`
+	IgnorableValidationErrors  bool `json:"enable_ignorable_validation_errors"`
+
+	GetPayloadRetryTimeoutMs  int `json:"getpayload_retry_timeout_ms"`
+	GetPayloadRequestCutoffMs int `json:"getpayload_request_cutoff_ms"` // 0 to disable
+	GetPayloadResponseDelayMs int `json:"getpayload_response_delay_ms"`
+}
+
+// Fields returns the settings by their field name in Redis
+func (c *RuntimeConfig) Fields() (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	err = json.Unmarshal(encoded, &fields)
+	return fields, err
+}
+
+// WithFields returns a copy of the config with the given settings changed
+func (c *RuntimeConfig) WithFields(fields map[string]json.RawMessage) (*RuntimeConfig, error) {
+	merged, err := c.Fields()
+	if err != nil {
+		return nil, err
+	}
+	for field, value := range fields {
+		if _, ok := merged[field]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRuntimeConfigField, field)
+		}
+		merged[field] = value
+	}
+	encoded, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(RuntimeConfig)
+	err = json.Unmarshal(encoded, ret)
+	return ret, err
+}
+
+// InitRuntimeConfig stores the defaults for all settings that aren't in Redis yet
+func (r *RedisCache) InitRuntimeConfig(defaults *RuntimeConfig) error {
+	fields, err := defaults.Fields()
+	if err != nil {
+		return err
+	}
+	_, err = r.client.Pipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		for field, value := range fields {
+			pipe.HSetNX(context.Background(), r.keyRuntimeConfig, field, string(value))
+		}
+		return nil
+	})
+	return err
+}
+
+// GetRuntimeConfig returns the runtime config from Redis. Settings that aren't in Redis keep the default,
+// and unknown fields (i.e. from a newer version) are ignored.
+func (r *RedisCache) GetRuntimeConfig(defaults *RuntimeConfig) (*RuntimeConfig, error) {
+	known, err := defaults.Fields()
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.client.HGetAll(context.Background(), r.keyRuntimeConfig).Result()
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	for field, value := range res {
+		if _, ok := known[field]; ok {
+			fields[field] = json.RawMessage(value)
+		}
+	}
+	return defaults.WithFields(fields)
+}
+
+// SetRuntimeConfig changes the given settings, and lets all replicas know about it
+func (r *RedisCache) SetRuntimeConfig(fields map[string]json.RawMessage) error {
+	_, err := r.client.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		for field, value := range fields {
+			pipe.HSet(context.Background(), r.keyRuntimeConfig, field, string(value))
+		}
+		pipe.Publish(context.Background(), r.channelRuntimeConfigUpdates, "")
+		return nil
+	})
+	return err
+}
+
+// SubscribeRuntimeConfigUpdates subscribes to runtime config changes. The messages carry no data, the
+// config needs to be loaded again. The subscription is closed when the context is done.
+func (r *RedisCache) SubscribeRuntimeConfigUpdates(ctx context.Context) *redis.PubSub {
+	pubsub := r.client.Subscribe(ctx, r.channelRuntimeConfigUpdates)
+	go func() {
+		<-ctx.Done()
+		_ = pubsub.Close()
+	}()
+	return pubsub
+}
+
+// IncrOptimisticExposure adds deltaGwei (which can be negative) to the unverified optimistic
+// value of a builder in a slot, and returns the new total across all replicas
+func (r *RedisCache) IncrOptimisticExposure(slot uint64, builder string, deltaGwei int64) (totalGwei int64, err error) {
+	key := r.keyOptimisticExposure(slot, builder)
+	pipe := r.client.TxPipeline()
+	incr := pipe.IncrBy(context.Background(), key, deltaGwei)
+	pipe.Expire(context.Background(), key, expiryBidCache)
+	if _, err := pipe.Exec(context.Background()); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// IncrDataAPIRequests counts a data API request of an API key or IP in the current minute, and returns
+// the number of requests in this minute across all replicas
+func (r *RedisCache) IncrDataAPIRequests(subject string, now time.Time) (count int64, err error) {
+	key := r.keyDataAPIQuota(subject, now.Unix()/60)
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(context.Background(), key)
+	pipe.Expire(context.Background(), key, 2*time.Minute)
+	if _, err := pipe.Exec(context.Background()); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
@@ -2,6 +2,7 @@ package datastore
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"math/big"
 	"sync"
@@ -12,6 +13,8 @@ import (
 	"github.com/attestantio/go-builder-client/api/capella"
 	"github.com/attestantio/go-builder-client/spec"
 	consensusspec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
 	"github.com/flashbots/go-boost-utils/types"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/go-redis/redis/v9"
@@ -245,7 +248,7 @@ func TestBuilderBids(t *testing.T) {
 
 	// submit ba1=10
 	payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, bApubkey, big.NewInt(10), &opts)
-	resp, err := cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+	resp, err := cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), false)
 	require.NoError(t, err)
 	require.True(t, resp.WasBidSaved, resp)
 	require.True(t, resp.WasTopBidUpdated)
@@ -256,7 +259,7 @@ func TestBuilderBids(t *testing.T) {
 
 	// submit ba2=5 (should not update)
 	payload, getPayloadResp, getHeaderResp = common.CreateTestBlockSubmission(t, bApubkey, big.NewInt(5), &opts)
-	resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+	resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), false)
 	require.NoError(t, err)
 	require.False(t, resp.WasBidSaved, resp)
 	require.False(t, resp.WasTopBidUpdated)
@@ -267,7 +270,7 @@ func TestBuilderBids(t *testing.T) {
 
 	// submit ba3c=5 (should not update, because floor is 10)
 	payload, getPayloadResp, getHeaderResp = common.CreateTestBlockSubmission(t, bApubkey, big.NewInt(5), &opts)
-	resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), true, nil)
+	resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), true)
 	require.NoError(t, err)
 	require.True(t, resp.WasBidSaved)
 	require.False(t, resp.WasTopBidUpdated)
@@ -279,7 +282,7 @@ func TestBuilderBids(t *testing.T) {
 
 	// submit bb1=20
 	payload, getPayloadResp, getHeaderResp = common.CreateTestBlockSubmission(t, bBpubkey, big.NewInt(20), &opts)
-	resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+	resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), false)
 	require.NoError(t, err)
 	require.True(t, resp.WasBidSaved)
 	require.True(t, resp.WasTopBidUpdated)
@@ -290,7 +293,7 @@ func TestBuilderBids(t *testing.T) {
 
 	// submit bb2c=22
 	payload, getPayloadResp, getHeaderResp = common.CreateTestBlockSubmission(t, bBpubkey, big.NewInt(22), &opts)
-	resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), true, nil)
+	resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), true)
 	require.NoError(t, err)
 	require.True(t, resp.WasBidSaved)
 	require.True(t, resp.WasTopBidUpdated)
@@ -301,7 +304,7 @@ func TestBuilderBids(t *testing.T) {
 
 	// submit bb3c=12 (should update top bid, using floor at 20)
 	payload, getPayloadResp, getHeaderResp = common.CreateTestBlockSubmission(t, bBpubkey, big.NewInt(12), &opts)
-	resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), true, nil)
+	resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), true)
 	require.NoError(t, err)
 	require.True(t, resp.WasBidSaved)
 	require.True(t, resp.WasTopBidUpdated)
@@ -492,3 +495,401 @@ func TestGetBuilderLatestValue(t *testing.T) {
 	require.NoError(t, err)
 	require.Zero(t, v.Cmp(newVal.ToBig()))
 }
+
+func TestBlockSimResult(t *testing.T) {
+	cache := setupTestRedis(t)
+
+	blockHash := "0xa645370cc112c2e8e3cce121416c7dc849e773506d4b6fb9b752ada711355369"
+	feeRecipient := "0xFFbb8996515293fcd87ca09b5c6ffe5c17f043c6"
+
+	// No cached result yet
+	result, err := cache.GetBlockSimResult(blockHash, feeRecipient, 30000000, "1")
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	err = cache.SaveBlockSimResult(blockHash, feeRecipient, 30000000, "1", &BlockSimResult{Error: "invalid block"})
+	require.NoError(t, err)
+
+	result, err = cache.GetBlockSimResult(blockHash, feeRecipient, 30000000, "1")
+	require.NoError(t, err)
+	require.Equal(t, "invalid block", result.Error)
+
+	// Different registered gas limit or value is a different simulation
+	result, err = cache.GetBlockSimResult(blockHash, feeRecipient, 30000001, "1")
+	require.NoError(t, err)
+	require.Nil(t, result)
+	result, err = cache.GetBlockSimResult(blockHash, feeRecipient, 30000000, "2")
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestBuilderStatusUpdates(t *testing.T) {
+	cache := setupTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pubsub := cache.SubscribeBuilderStatusUpdates(ctx)
+	_, err := pubsub.Receive(ctx) // wait for the subscription confirmation
+	require.NoError(t, err)
+
+	update := &BuilderStatusUpdate{
+		Kind:          BuilderStatusUpdateOptimistic,
+		BuilderPubkey: "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83",
+		BuilderID:     "builder0x69",
+		IsOptimistic:  false,
+	}
+	err = cache.PublishBuilderStatusUpdate(update)
+	require.NoError(t, err)
+
+	select {
+	case msg := <-pubsub.Channel():
+		received := new(BuilderStatusUpdate)
+		err = json.Unmarshal([]byte(msg.Payload), received)
+		require.NoError(t, err)
+		require.Equal(t, update, received)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for builder status update")
+	}
+}
+
+func TestSaveBidAndUpdateTopBidConcurrent(t *testing.T) {
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	opts := common.CreateTestBlockSubmissionOpts{
+		Slot:           slot,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey,
+	}
+	numBuilders := 16
+	numBids := 8
+
+	builderPubkeys := make([]string, numBuilders)
+	for i := range builderPubkeys {
+		sk, _, err := bls.GenerateNewKeypair()
+		require.NoError(t, err)
+		pk, err := bls.PublicKeyFromSecretKey(sk)
+		require.NoError(t, err)
+		builderPubkeys[i] = types.PublicKey(pk.Bytes()).String()
+	}
+
+	// Every builder submits its bids in order, all builders at the same time
+	submitAll := func(cache *RedisCache, isCancellationEnabled bool, value func(builder, bid int) int64) {
+		type submission struct {
+			payload        *common.BuilderSubmitBlockRequest
+			getPayloadResp *common.GetPayloadResponse
+			getHeaderResp  *common.GetHeaderResponse
+		}
+		submissions := make([][]submission, numBuilders)
+		for i, builderPubkey := range builderPubkeys {
+			for j := 0; j < numBids; j++ {
+				payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(value(i, j)), &opts)
+				submissions[i] = append(submissions[i], submission{payload, getPayloadResp, getHeaderResp})
+			}
+		}
+
+		var wg sync.WaitGroup
+		for i := range submissions {
+			wg.Add(1)
+			go func(submissions []submission) {
+				defer wg.Done()
+				for _, s := range submissions {
+					_, err := cache.SaveBidAndUpdateTopBid(s.payload, s.getPayloadResp, s.getHeaderResp, time.Now(), isCancellationEnabled)
+					require.NoError(t, err)
+				}
+			}(submissions[i])
+		}
+		wg.Wait()
+	}
+	ensureTopBid := func(cache *RedisCache, expectedValue int64) {
+		topBidValue, err := cache.GetTopBidValue(slot, parentHash, proposerPubkey)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(expectedValue), topBidValue)
+		bestBid, err := cache.GetBestBid(slot, parentHash, proposerPubkey)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(expectedValue), bestBid.Value())
+	}
+
+	t.Run("non-cancellable bids", func(t *testing.T) {
+		cache := setupTestRedis(t)
+		submitAll(cache, false, func(builder, bid int) int64 {
+			return int64(1 + bid*numBuilders + (builder*7)%numBuilders)
+		})
+		maxValue := int64(numBids * numBuilders)
+		ensureTopBid(cache, maxValue)
+		floorValue, err := cache.GetFloorBidValue(slot, parentHash, proposerPubkey)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(maxValue), floorValue)
+	})
+
+	t.Run("cancellable bids", func(t *testing.T) {
+		// Bids go down, so the top bid is the highest of the last bids
+		cache := setupTestRedis(t)
+		submitAll(cache, true, func(builder, bid int) int64 {
+			return int64(1000 - bid*100 + builder)
+		})
+		ensureTopBid(cache, int64(1000-(numBids-1)*100+numBuilders-1))
+		for i, builderPubkey := range builderPubkeys {
+			latestValue, err := cache.GetBuilderLatestValue(slot, parentHash, proposerPubkey, builderPubkey)
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(int64(1000-(numBids-1)*100+i)), latestValue)
+		}
+	})
+}
+
+func TestSaveBidAndUpdateTopBidLargeValues(t *testing.T) {
+	cache := setupTestRedis(t)
+	bApubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	bBpubkey := "0x2e02be2c9f9eccf9856478fdb7876598fed2da09f45c233969ba647a250231150ecf38bce5771adb6171c86b79a92f16"
+
+	// Values that can't be compared as Lua numbers
+	valueA, _ := new(big.Int).SetString("100000000000000000001", 10)
+	valueB, _ := new(big.Int).SetString("100000000000000000002", 10)
+	valueC, _ := new(big.Int).SetString("99999999999999999999", 10)
+
+	payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, bApubkey, valueB, nil)
+	resp, err := cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), true)
+	require.NoError(t, err)
+	require.True(t, resp.IsNewTopBid)
+
+	for _, value := range []*big.Int{valueA, valueC} {
+		payload, getPayloadResp, getHeaderResp = common.CreateTestBlockSubmission(t, bBpubkey, value, nil)
+		resp, err = cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), true)
+		require.NoError(t, err)
+		require.True(t, resp.WasBidSaved)
+		require.False(t, resp.IsNewTopBid)
+		require.Equal(t, valueB, resp.TopBidValue)
+	}
+}
+
+func TestWithdrawBid(t *testing.T) {
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	opts := common.CreateTestBlockSubmissionOpts{
+		Slot:           slot,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey,
+	}
+	bApubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	bBpubkey := "0x2e02be2c9f9eccf9856478fdb7876598fed2da09f45c233969ba647a250231150ecf38bce5771adb6171c86b79a92f16"
+	bCpubkey := "0xb67a5148a03229926e34b190af81a82a81c4df66831c98c03a139778418dd09a3b542ced0022620d19f35781ece6dc36"
+
+	cache := setupTestRedis(t)
+
+	submit := func(builderPubkey string, value int64, blockHash byte, isCancellationEnabled bool) (*common.BuilderSubmitBlockRequest, SaveBidAndUpdateTopBidResponse) {
+		payload, _, _ := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(value), &opts)
+		payload.Capella.Message.BlockHash = phase0.Hash32{blockHash}
+		payload.Capella.ExecutionPayload.BlockHash = phase0.Hash32{blockHash}
+		getHeaderResp, err := common.BuildGetHeaderResponse(payload, &bls.SecretKey{}, &types.PublicKey{}, types.Domain{})
+		require.NoError(t, err)
+		getPayloadResp, err := common.BuildGetPayloadResponse(payload)
+		require.NoError(t, err)
+		resp, err := cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), isCancellationEnabled)
+		require.NoError(t, err)
+		return payload, resp
+	}
+	ensureBestBid := func(expectedValue int64, expectedBlockHash byte) {
+		bestBid, err := cache.GetBestBid(slot, parentHash, proposerPubkey)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(expectedValue), bestBid.Value())
+		require.Equal(t, phase0.Hash32{expectedBlockHash}, bestBid.BlockHash())
+		topBidValue, err := cache.GetTopBidValue(slot, parentHash, proposerPubkey)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(expectedValue), topBidValue)
+	}
+
+	// ba=10, bb=20 (floor), bc=30c
+	submit(bApubkey, 10, 0xaa, false)
+	payloadB, _ := submit(bBpubkey, 20, 0xbb, false)
+	submit(bCpubkey, 30, 0xcc, true)
+	ensureBestBid(30, 0xcc)
+
+	// withdraw builder C, bb becomes the top bid again
+	state, err := cache.WithdrawBid(slot, parentHash, proposerPubkey, bCpubkey, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{bCpubkey}, state.WithdrawnBuilders)
+	require.Equal(t, []string{phase0.Hash32{0xcc}.String()}, state.WithdrawnBlockHashes)
+	require.False(t, state.WasFloorBidRemoved)
+	require.Equal(t, big.NewInt(30), state.PrevTopBidValue)
+	require.Equal(t, big.NewInt(20), state.TopBidValue)
+	ensureBestBid(20, 0xbb)
+
+	_, err = cache.WithdrawBid(slot, parentHash, proposerPubkey, bCpubkey, "")
+	require.ErrorIs(t, err, ErrNoBidToWithdraw)
+
+	// withdraw the floor bid by block hash, ba is left and becomes the floor
+	state, err = cache.WithdrawBid(slot, parentHash, proposerPubkey, "", payloadB.BlockHash())
+	require.NoError(t, err)
+	require.Equal(t, []string{bBpubkey}, state.WithdrawnBuilders)
+	require.True(t, state.WasFloorBidRemoved)
+	require.Equal(t, big.NewInt(10), state.TopBidValue)
+	ensureBestBid(10, 0xaa)
+	floorValue, err := cache.GetFloorBidValue(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(10), floorValue)
+
+	// withdrawn blocks can't be submitted again
+	isWithdrawn, err := cache.IsBidWithdrawn(slot, payloadB.BlockHash())
+	require.NoError(t, err)
+	require.True(t, isWithdrawn)
+	_, resp := submit(bBpubkey, 20, 0xbb, false)
+	require.True(t, resp.IsWithdrawn)
+	require.False(t, resp.WasBidSaved)
+	ensureBestBid(10, 0xaa)
+
+	// other blocks of the builder can
+	_, resp = submit(bBpubkey, 15, 0xbc, false)
+	require.True(t, resp.WasBidSaved)
+	ensureBestBid(15, 0xbc)
+
+	// withdraw everything, no bid is left
+	for _, builderPubkey := range []string{bApubkey, bBpubkey} {
+		_, err = cache.WithdrawBid(slot, parentHash, proposerPubkey, builderPubkey, "")
+		require.NoError(t, err)
+	}
+	bestBid, err := cache.GetBestBid(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Nil(t, bestBid)
+	floorValue, err = cache.GetFloorBidValue(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(0), floorValue)
+}
+
+func TestWithdrawBidOfFloorBuilder(t *testing.T) {
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	opts := common.CreateTestBlockSubmissionOpts{
+		Slot:           slot,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey,
+	}
+	bApubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	bBpubkey := "0x2e02be2c9f9eccf9856478fdb7876598fed2da09f45c233969ba647a250231150ecf38bce5771adb6171c86b79a92f16"
+
+	cache := setupTestRedis(t)
+
+	submit := func(builderPubkey string, value int64, blockHash byte, isCancellationEnabled bool) {
+		payload, _, _ := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(value), &opts)
+		payload.Capella.Message.BlockHash = phase0.Hash32{blockHash}
+		payload.Capella.ExecutionPayload.BlockHash = phase0.Hash32{blockHash}
+		getHeaderResp, err := common.BuildGetHeaderResponse(payload, &bls.SecretKey{}, &types.PublicKey{}, types.Domain{})
+		require.NoError(t, err)
+		getPayloadResp, err := common.BuildGetPayloadResponse(payload)
+		require.NoError(t, err)
+		resp, err := cache.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), isCancellationEnabled)
+		require.NoError(t, err)
+		require.True(t, resp.WasBidSaved)
+	}
+
+	// ba=10, bb=30 (floor), then bb cancels down to 5: the floor of bb stays the top bid
+	submit(bApubkey, 10, 0xaa, false)
+	submit(bBpubkey, 30, 0xb1, false)
+	submit(bBpubkey, 5, 0xb2, true)
+	topBidValue, err := cache.GetTopBidValue(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(30), topBidValue)
+
+	// withdrawing bb removes its floor bid too, the floor is recomputed from ba
+	state, err := cache.WithdrawBid(slot, parentHash, proposerPubkey, bBpubkey, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{bBpubkey}, state.WithdrawnBuilders)
+	require.Equal(t, []string{phase0.Hash32{0xb1}.String(), phase0.Hash32{0xb2}.String()}, state.WithdrawnBlockHashes)
+	require.True(t, state.WasFloorBidRemoved)
+	require.Equal(t, big.NewInt(30), state.PrevTopBidValue)
+	require.Equal(t, big.NewInt(10), state.TopBidValue)
+
+	bestBid, err := cache.GetBestBid(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, phase0.Hash32{0xaa}, bestBid.BlockHash())
+	floorValue, err := cache.GetFloorBidValue(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(10), floorValue)
+
+	// all blocks of bb are refused
+	for _, blockHash := range []byte{0xb1, 0xb2} {
+		isWithdrawn, err := cache.IsBidWithdrawn(slot, phase0.Hash32{blockHash}.String())
+		require.NoError(t, err)
+		require.True(t, isWithdrawn)
+	}
+}
+
+func TestRuntimeConfig(t *testing.T) {
+	cache := setupTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defaults := &RuntimeConfig{GetPayloadRequestCutoffMs: 4000} //nolint:exhaustruct
+
+	// Defaults are only stored for settings that aren't in Redis yet
+	require.NoError(t, cache.client.HSet(ctx, cache.keyRuntimeConfig, "disable_lowprio_builders", "true").Err())
+	require.NoError(t, cache.InitRuntimeConfig(defaults))
+	cfg, err := cache.GetRuntimeConfig(&RuntimeConfig{}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Equal(t, RuntimeConfig{DisableLowPrioBuilders: true, GetPayloadRequestCutoffMs: 4000}, *cfg) //nolint:exhaustruct
+
+	pubsub := cache.SubscribeRuntimeConfigUpdates(ctx)
+	_, err = pubsub.Receive(ctx) // wait for the subscription confirmation
+	require.NoError(t, err)
+
+	err = cache.SetRuntimeConfig(map[string]json.RawMessage{"stop_serving_bids": json.RawMessage("true")})
+	require.NoError(t, err)
+	select {
+	case <-pubsub.Channel():
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for runtime config update")
+	}
+
+	// Unknown fields, i.e. from a newer version, are ignored
+	require.NoError(t, cache.client.HSet(ctx, cache.keyRuntimeConfig, "foo", "1").Err())
+	cfg, err = cache.GetRuntimeConfig(defaults)
+	require.NoError(t, err)
+	require.Equal(t, RuntimeConfig{StopServingBids: true, DisableLowPrioBuilders: true, GetPayloadRequestCutoffMs: 4000}, *cfg) //nolint:exhaustruct
+
+	_, err = cfg.WithFields(map[string]json.RawMessage{"foo": json.RawMessage("1")})
+	require.ErrorIs(t, err, ErrUnknownRuntimeConfigField)
+	_, err = cfg.WithFields(map[string]json.RawMessage{"stop_serving_bids": json.RawMessage(`"yes"`)})
+	require.Error(t, err)
+}
+
+func TestIncrOptimisticExposure(t *testing.T) {
+	cache := setupTestRedis(t)
+	builder := "builder0x69"
+
+	total, err := cache.IncrOptimisticExposure(1, builder, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), total)
+	total, err = cache.IncrOptimisticExposure(1, builder, 5)
+	require.NoError(t, err)
+	require.Equal(t, int64(15), total)
+
+	// Other slots are tracked separately
+	total, err = cache.IncrOptimisticExposure(2, builder, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+
+	total, err = cache.IncrOptimisticExposure(1, builder, -10)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), total)
+}
+
+func TestIncrDataAPIRequests(t *testing.T) {
+	cache := setupTestRedis(t)
+	now := time.Unix(1700000000, 0)
+
+	count, err := cache.IncrDataAPIRequests("key1", now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+	count, err = cache.IncrDataAPIRequests("key1", now.Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	// Other subjects and minutes are counted separately
+	count, err = cache.IncrDataAPIRequests("1.2.3.4", now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+	count, err = cache.IncrDataAPIRequests("key1", now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
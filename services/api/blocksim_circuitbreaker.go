@@ -0,0 +1,251 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrBlockSimCircuitOpen = errors.New("block simulator unavailable (circuit breaker open), try again later")
+
+	simBreakerWindow           = cli.GetEnvInt("BLOCKSIM_BREAKER_WINDOW", 100)
+	simBreakerMinRequests      = cli.GetEnvInt("BLOCKSIM_BREAKER_MIN_REQUESTS", 20)
+	simBreakerErrorRatePct     = cli.GetEnvInt("BLOCKSIM_BREAKER_ERROR_RATE_PCT", 50)   // 0 to disable
+	simBreakerTimeoutRatePct   = cli.GetEnvInt("BLOCKSIM_BREAKER_TIMEOUT_RATE_PCT", 25) // 0 to disable
+	simBreakerCooldown         = time.Duration(cli.GetEnvInt("BLOCKSIM_BREAKER_COOLDOWN_MS", 12000)) * time.Millisecond
+	simBreakerHalfOpenRequired = cli.GetEnvInt("BLOCKSIM_BREAKER_HALFOPEN_SUCCESSES", 3)
+)
+
+type simBreakerState int
+
+const (
+	simBreakerClosed simBreakerState = iota
+	simBreakerOpen
+	simBreakerHalfOpen
+)
+
+func (s simBreakerState) String() string {
+	switch s {
+	case simBreakerClosed:
+		return "closed"
+	case simBreakerOpen:
+		return "open"
+	case simBreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type simOutcome uint8
+
+const (
+	simOutcomeSuccess simOutcome = iota
+	simOutcomeError
+	simOutcomeTimeout
+)
+
+type blockSimCircuitBreakerOpts struct {
+	window           int
+	minRequests      int
+	errorRatePct     int
+	timeoutRatePct   int
+	cooldown         time.Duration
+	halfOpenRequired int
+}
+
+func defaultBlockSimCircuitBreakerOpts() blockSimCircuitBreakerOpts {
+	return blockSimCircuitBreakerOpts{
+		window:           simBreakerWindow,
+		minRequests:      simBreakerMinRequests,
+		errorRatePct:     simBreakerErrorRatePct,
+		timeoutRatePct:   simBreakerTimeoutRatePct,
+		cooldown:         simBreakerCooldown,
+		halfOpenRequired: simBreakerHalfOpenRequired,
+	}
+}
+
+// blockSimCircuitBreaker keeps track of the recent simulator request outcomes
+// and trips when too many of them are request errors or timeouts. While open,
+// low-prio submissions are rejected without hitting the simulator, optimistic
+// builders are simulated before their blocks are accepted, and optimistic blocks
+// already accepted are retried once before the builder is demoted for a request
+// error. After the cooldown, the breaker becomes half-open and lets a few
+// low-prio probe requests through. It closes again once enough simulations succeed.
+type blockSimCircuitBreaker struct {
+	log  *logrus.Entry
+	opts blockSimCircuitBreakerOpts
+
+	mu                sync.Mutex
+	state             simBreakerState
+	openedAt          time.Time
+	halfOpenAt        time.Time
+	halfOpenProbes    int          // low-prio requests let through since becoming half-open
+	outcomes          []simOutcome // ring buffer of the latest outcomes
+	next              int
+	filled            int
+	halfOpenSuccesses int
+}
+
+func newBlockSimCircuitBreaker(log *logrus.Entry, opts blockSimCircuitBreakerOpts) *blockSimCircuitBreaker {
+	if opts.window < 1 {
+		opts.window = 1
+	}
+	return &blockSimCircuitBreaker{ //nolint:exhaustruct
+		log:      log.WithField("component", "blockSimCircuitBreaker"),
+		opts:     opts,
+		state:    simBreakerClosed,
+		outcomes: make([]simOutcome, opts.window),
+	}
+}
+
+func (b *blockSimCircuitBreaker) enabled() bool {
+	return b.opts.errorRatePct > 0 || b.opts.timeoutRatePct > 0
+}
+
+// State returns the current state, moving from open to half-open once the cooldown has passed.
+func (b *blockSimCircuitBreaker) State() simBreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.checkCooldown()
+	return b.state
+}
+
+// IsOpen returns true unless the breaker is closed, i.e. as long as the simulator is considered unhealthy.
+func (b *blockSimCircuitBreaker) IsOpen() bool {
+	return b.State() != simBreakerClosed
+}
+
+// Allow returns whether a simulation request should be sent. High-prio
+// requests are always sent. Low-prio requests are sent if the breaker is closed,
+// and while half-open only up to halfOpenRequired of them are let through as probes.
+func (b *blockSimCircuitBreaker) Allow(isHighPrio bool) bool {
+	if isHighPrio {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.checkCooldown()
+
+	switch b.state {
+	case simBreakerOpen:
+		return false
+	case simBreakerHalfOpen:
+		if b.halfOpenProbes >= b.opts.halfOpenRequired {
+			return false
+		}
+		b.halfOpenProbes++
+	case simBreakerClosed:
+	}
+	return true
+}
+
+// Record registers the outcome of a simulation request. Validation errors are
+// the builder's fault and count as a successful simulator request.
+func (b *blockSimCircuitBreaker) Record(requestErr error) {
+	if !b.enabled() || errors.Is(requestErr, ErrRequestClosed) || errors.Is(requestErr, ErrBlockSimCircuitOpen) {
+		return
+	}
+
+	outcome := simOutcomeSuccess
+	if requestErr != nil {
+		outcome = simOutcomeError
+		if os.IsTimeout(requestErr) || errors.Is(requestErr, context.DeadlineExceeded) {
+			outcome = simOutcomeTimeout
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.checkCooldown()
+
+	switch b.state {
+	case simBreakerOpen:
+		// requests that were allowed through while open don't change the state
+		return
+	case simBreakerHalfOpen:
+		if outcome != simOutcomeSuccess {
+			b.transition(simBreakerOpen, "simulation request failed while half-open")
+			return
+		}
+		b.halfOpenSuccesses++
+		if b.halfOpenSuccesses >= b.opts.halfOpenRequired {
+			b.transition(simBreakerClosed, "simulator recovered")
+		}
+		return
+	case simBreakerClosed:
+	}
+
+	b.outcomes[b.next] = outcome
+	b.next = (b.next + 1) % len(b.outcomes)
+	if b.filled < len(b.outcomes) {
+		b.filled++
+	}
+	if b.filled < b.opts.minRequests {
+		return
+	}
+
+	numErrors, numTimeouts := 0, 0
+	for i := 0; i < b.filled; i++ {
+		switch b.outcomes[i] {
+		case simOutcomeTimeout:
+			numTimeouts++
+			numErrors++
+		case simOutcomeError:
+			numErrors++
+		case simOutcomeSuccess:
+		}
+	}
+
+	if b.opts.timeoutRatePct > 0 && numTimeouts*100 >= b.opts.timeoutRatePct*b.filled {
+		b.transition(simBreakerOpen, "timeout rate exceeded")
+	} else if b.opts.errorRatePct > 0 && numErrors*100 >= b.opts.errorRatePct*b.filled {
+		b.transition(simBreakerOpen, "error rate exceeded")
+	}
+}
+
+// checkCooldown must be called with the lock held
+func (b *blockSimCircuitBreaker) checkCooldown() {
+	if b.state == simBreakerOpen && time.Since(b.openedAt) >= b.opts.cooldown {
+		b.transition(simBreakerHalfOpen, "cooldown elapsed")
+	} else if b.state == simBreakerHalfOpen && time.Since(b.halfOpenAt) >= b.opts.cooldown {
+		// Probes whose outcome was never recorded (i.e. closed requests) would keep the breaker half-open forever
+		b.halfOpenAt = time.Now()
+		b.halfOpenProbes = 0
+	}
+}
+
+// transition must be called with the lock held
+func (b *blockSimCircuitBreaker) transition(to simBreakerState, reason string) {
+	from := b.state
+	b.state = to
+	b.halfOpenSuccesses = 0
+	b.halfOpenProbes = 0
+	switch to {
+	case simBreakerOpen:
+		b.openedAt = time.Now()
+	case simBreakerClosed:
+		b.next, b.filled = 0, 0
+	case simBreakerHalfOpen:
+		b.halfOpenAt = time.Now()
+	}
+
+	log := b.log.WithFields(logrus.Fields{
+		"event":     "blocksim_circuit_breaker_transition",
+		"fromState": from.String(),
+		"toState":   to.String(),
+		"reason":    reason,
+	})
+	if to == simBreakerOpen {
+		log.Error("block simulation circuit breaker opened")
+	} else {
+		log.Warn("block simulation circuit breaker state changed")
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func testBreakerOpts() blockSimCircuitBreakerOpts {
+	return blockSimCircuitBreakerOpts{
+		window:           10,
+		minRequests:      4,
+		errorRatePct:     50,
+		timeoutRatePct:   25,
+		cooldown:         time.Hour,
+		halfOpenRequired: 2,
+	}
+}
+
+func TestBlockSimCircuitBreaker(t *testing.T) {
+	t.Run("opens on error rate", func(t *testing.T) {
+		b := newBlockSimCircuitBreaker(common.TestLog, testBreakerOpts())
+		b.Record(nil)
+		b.Record(errFake)
+		b.Record(nil)
+		require.Equal(t, simBreakerClosed, b.State())
+		b.Record(errFake)
+		require.Equal(t, simBreakerOpen, b.State())
+		require.False(t, b.Allow(false))
+		require.True(t, b.Allow(true))
+	})
+
+	t.Run("opens on timeout rate", func(t *testing.T) {
+		b := newBlockSimCircuitBreaker(common.TestLog, testBreakerOpts())
+		b.Record(nil)
+		b.Record(nil)
+		b.Record(nil)
+		b.Record(context.DeadlineExceeded)
+		require.Equal(t, simBreakerOpen, b.State())
+	})
+
+	t.Run("ignores closed requests", func(t *testing.T) {
+		b := newBlockSimCircuitBreaker(common.TestLog, testBreakerOpts())
+		for i := 0; i < 10; i++ {
+			b.Record(fmt.Errorf("%w, %w", ErrRequestClosed, context.Canceled))
+		}
+		require.Equal(t, simBreakerClosed, b.State())
+	})
+
+	t.Run("half-open and recovery", func(t *testing.T) {
+		opts := testBreakerOpts()
+		opts.cooldown = 0
+		b := newBlockSimCircuitBreaker(common.TestLog, opts)
+		for i := 0; i < 4; i++ {
+			b.Record(errFake)
+		}
+		require.Equal(t, simBreakerHalfOpen, b.State())
+		require.True(t, b.IsOpen())
+		require.True(t, b.Allow(false))
+
+		// failure while half-open opens again
+		b.Record(errFake)
+		require.Equal(t, simBreakerHalfOpen, b.State()) // zero cooldown
+
+		b.Record(nil)
+		require.Equal(t, simBreakerHalfOpen, b.State())
+		b.Record(nil)
+		require.Equal(t, simBreakerClosed, b.State())
+		require.False(t, b.IsOpen())
+	})
+
+	t.Run("half-open probes", func(t *testing.T) {
+		b := newBlockSimCircuitBreaker(common.TestLog, testBreakerOpts())
+		b.mu.Lock()
+		b.transition(simBreakerHalfOpen, "test")
+		b.mu.Unlock()
+
+		// Only halfOpenRequired low-prio requests are let through
+		require.True(t, b.Allow(false))
+		require.True(t, b.Allow(false))
+		require.False(t, b.Allow(false))
+		require.True(t, b.Allow(true))
+
+		b.Record(nil)
+		b.Record(nil)
+		require.Equal(t, simBreakerClosed, b.State())
+		require.True(t, b.Allow(false))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		opts := testBreakerOpts()
+		opts.errorRatePct = 0
+		opts.timeoutRatePct = 0
+		b := newBlockSimCircuitBreaker(common.TestLog, opts)
+		for i := 0; i < 10; i++ {
+			b.Record(errFake)
+		}
+		require.Equal(t, simBreakerClosed, b.State())
+	})
+}
+
+func TestSimulateBlockCircuitOpen(t *testing.T) {
+	pubkey, secretkey, backend := startTestBackend(t)
+	pkStr := pubkey.String()
+	backend.relay.blockSimBreaker = newBlockSimCircuitBreaker(common.TestLog, testBreakerOpts())
+	backend.relay.blockSimRateLimiter = &MockBlockSimulationRateLimiter{
+		requestError: errFake,
+	}
+
+	opts := blockSimOptions{
+		isHighPrio: false,
+		log:        backend.relay.log,
+		builder: &blockBuilderCacheEntry{
+			status: common.BuilderStatus{
+				IsOptimistic: true,
+			},
+		},
+		req: &common.BuilderBlockValidationRequest{
+			BuilderSubmitBlockRequest: common.TestBuilderSubmitBlockRequest(
+				secretkey, getTestBidTrace(*pubkey, collateral)),
+		},
+	}
+
+	// Trip the breaker, low-prio submissions then fail fast
+	for i := 0; i < 4; i++ {
+		reqErr, _, _ := backend.relay.simulateBlock(context.Background(), opts)
+		require.ErrorIs(t, reqErr, errFake)
+	}
+	require.True(t, backend.relay.blockSimBreaker.IsOpen())
+	reqErr, _, _ := backend.relay.simulateBlock(context.Background(), opts)
+	require.ErrorIs(t, reqErr, ErrBlockSimCircuitOpen)
+
+	// Request errors of accepted optimistic blocks don't demote the builder while the breaker is open
+	opts.isHighPrio = true
+	simResultC := make(chan *blockSimResult, 1)
+	backend.relay.processOptimisticBlock(opts, simResultC)
+	simResult := <-simResultC
+	require.ErrorIs(t, simResult.requestErr, errFake)
+	require.True(t, backend.relay.blockBuildersCache[pkStr].status.IsOptimistic)
+	builder, err := backend.relay.db.GetBlockBuilderByPubkey(pkStr)
+	require.NoError(t, err)
+	require.True(t, builder.IsOptimistic)
+	mockDB, ok := backend.relay.db.(*database.MockDB)
+	require.True(t, ok)
+	require.False(t, mockDB.Demotions[pkStr])
+}
@@ -32,21 +32,22 @@ type IBlockSimRateLimiter interface {
 }
 
 type BlockSimulationRateLimiter struct {
-	cv          *sync.Cond
-	counter     int64
-	blockSimURL string
-	client      http.Client
+	cv        *sync.Cond
+	counter   int64
+	transport IBlockSimTransport
 }
 
-func NewBlockSimulationRateLimiter(blockSimURL string) *BlockSimulationRateLimiter {
-	return &BlockSimulationRateLimiter{
-		cv:          sync.NewCond(&sync.Mutex{}),
-		counter:     0,
-		blockSimURL: blockSimURL,
-		client: http.Client{ //nolint:exhaustruct
-			Timeout: simRequestTimeout,
-		},
+// NewBlockSimulationRateLimiter creates a rate limiter with the transport matching the URL scheme (see NewBlockSimTransport)
+func NewBlockSimulationRateLimiter(blockSimURL string) (*BlockSimulationRateLimiter, error) {
+	transport, err := NewBlockSimTransport(blockSimURL)
+	if err != nil {
+		return nil, err
 	}
+	return &BlockSimulationRateLimiter{
+		cv:        sync.NewCond(&sync.Mutex{}),
+		counter:   0,
+		transport: transport,
+	}, nil
 }
 
 func (b *BlockSimulationRateLimiter) Send(context context.Context, payload *common.BuilderBlockValidationRequest, isHighPrio, fastTrack bool) (requestErr, validationErr error) {
@@ -74,7 +75,7 @@ func (b *BlockSimulationRateLimiter) Send(context context.Context, payload *comm
 	} else if payload.Bellatrix != nil {
 		simReq = jsonrpc.NewJSONRPCRequest("1", "flashbots_validateBuilderSubmissionV1", payload)
 	}
-	_, requestErr, validationErr = SendJSONRPCRequest(&b.client, *simReq, b.blockSimURL, isHighPrio, fastTrack)
+	_, requestErr, validationErr = b.transport.SendJSONRPCRequest(context, *simReq, isHighPrio, fastTrack)
 	return requestErr, validationErr
 }
 
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flashbots/go-utils/jsonrpc"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	ErrUnsupportedBlockSimScheme = errors.New("unsupported block simulator URL scheme")
+	ErrBlockSimConnectionClosed  = errors.New("block simulator connection closed")
+)
+
+// IBlockSimTransport delivers a single JSON-RPC request to the block simulator and
+// returns the response, a request error, or a validation error (the JSON-RPC error).
+type IBlockSimTransport interface {
+	SendJSONRPCRequest(ctx context.Context, req jsonrpc.JSONRPCRequest, isHighPrio, fastTrack bool) (res *jsonrpc.JSONRPCResponse, requestErr, validationErr error)
+	Close() error
+}
+
+// NewBlockSimTransport picks the transport based on the URL scheme:
+//
+//   - http:// and https:// send a new HTTP POST request for every simulation
+//   - ws:// and wss:// use a single persistent WebSocket connection
+//   - ipc:///path/to/socket uses a single persistent Unix socket connection
+//
+// The persistent transports multiplex concurrent requests over one connection
+// and reconnect lazily if it breaks. They can't send the priority headers.
+func NewBlockSimTransport(blockSimURL string) (IBlockSimTransport, error) {
+	u, err := url.Parse(blockSimURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "", "http", "https":
+		return &httpBlockSimTransport{
+			url: blockSimURL,
+			client: http.Client{ //nolint:exhaustruct
+				Timeout: simRequestTimeout,
+			},
+		}, nil
+	case "ws", "wss":
+		return newPersistentBlockSimTransport(func(ctx context.Context) (rpcCodec, error) {
+			dialer := websocket.Dialer{HandshakeTimeout: simRequestTimeout} //nolint:exhaustruct
+			conn, resp, err := dialer.DialContext(ctx, blockSimURL, nil)
+			if err != nil {
+				return nil, err
+			}
+			_ = resp.Body.Close()
+			return &wsCodec{conn: conn}, nil
+		}), nil
+	case "ipc":
+		path := u.Host + u.Path
+		return newPersistentBlockSimTransport(func(ctx context.Context) (rpcCodec, error) {
+			dialer := net.Dialer{Timeout: simRequestTimeout} //nolint:exhaustruct
+			conn, err := dialer.DialContext(ctx, "unix", path)
+			if err != nil {
+				return nil, err
+			}
+			return &streamCodec{conn: conn, enc: json.NewEncoder(conn), dec: json.NewDecoder(conn)}, nil
+		}), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedBlockSimScheme, u.Scheme)
+	}
+}
+
+// httpBlockSimTransport sends every request as a separate HTTP POST
+type httpBlockSimTransport struct {
+	url    string
+	client http.Client
+}
+
+func (t *httpBlockSimTransport) SendJSONRPCRequest(ctx context.Context, req jsonrpc.JSONRPCRequest, isHighPrio, fastTrack bool) (res *jsonrpc.JSONRPCResponse, requestErr, validationErr error) {
+	return SendJSONRPCRequest(&t.client, req, t.url, isHighPrio, fastTrack)
+}
+
+func (t *httpBlockSimTransport) Close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// rpcCodec reads and writes JSON-RPC messages on a persistent connection
+type rpcCodec interface {
+	WriteJSON(v any) error
+	ReadJSON(v any) error
+	SetWriteDeadline(t time.Time) error
+	Close() error
+}
+
+type wsCodec struct {
+	conn *websocket.Conn
+}
+
+func (c *wsCodec) WriteJSON(v any) error              { return c.conn.WriteJSON(v) }
+func (c *wsCodec) ReadJSON(v any) error               { return c.conn.ReadJSON(v) }
+func (c *wsCodec) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
+func (c *wsCodec) Close() error                       { return c.conn.Close() }
+
+type streamCodec struct {
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+func (c *streamCodec) WriteJSON(v any) error              { return c.enc.Encode(v) }
+func (c *streamCodec) ReadJSON(v any) error               { return c.dec.Decode(v) }
+func (c *streamCodec) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
+func (c *streamCodec) Close() error                       { return c.conn.Close() }
+
+type rpcResult struct {
+	res *jsonrpc.JSONRPCResponse
+	err error
+}
+
+// persistentBlockSimTransport keeps one connection open and matches responses to requests by id
+type persistentBlockSimTransport struct {
+	dial func(ctx context.Context) (rpcCodec, error)
+
+	connLock sync.Mutex // protects codec and pending
+	codec    rpcCodec
+	pending  map[string]chan rpcResult
+
+	writeLock sync.Mutex
+	nextID    uint64
+}
+
+func newPersistentBlockSimTransport(dial func(ctx context.Context) (rpcCodec, error)) *persistentBlockSimTransport {
+	return &persistentBlockSimTransport{ //nolint:exhaustruct
+		dial:    dial,
+		pending: make(map[string]chan rpcResult),
+	}
+}
+
+func (t *persistentBlockSimTransport) SendJSONRPCRequest(ctx context.Context, req jsonrpc.JSONRPCRequest, isHighPrio, fastTrack bool) (res *jsonrpc.JSONRPCResponse, requestErr, validationErr error) {
+	ctx, cancel := context.WithTimeout(ctx, simRequestTimeout)
+	defer cancel()
+
+	// use a unique id per connection, the caller's id is restored on the response
+	origID := req.ID
+	id := strconv.FormatUint(atomic.AddUint64(&t.nextID, 1), 10)
+	req.ID = id
+	resC := make(chan rpcResult, 1)
+
+	codec, err := t.getCodec(ctx)
+	if err != nil {
+		return nil, err, nil
+	}
+	t.connLock.Lock()
+	t.pending[id] = resC
+	t.connLock.Unlock()
+
+	defer func() {
+		t.connLock.Lock()
+		delete(t.pending, id)
+		t.connLock.Unlock()
+	}()
+
+	// A stalled connection must not block later requests, so every write has a deadline
+	deadline, _ := ctx.Deadline()
+	t.writeLock.Lock()
+	err = codec.SetWriteDeadline(deadline)
+	if err == nil {
+		err = codec.WriteJSON(req)
+	}
+	t.writeLock.Unlock()
+	if err != nil {
+		t.closeCodec(codec, err)
+		return nil, err, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err(), nil
+	case result := <-resC:
+		if result.err != nil {
+			return nil, result.err, nil
+		}
+		res = result.res
+		res.ID = origID
+		if res.Error != nil {
+			return res, nil, fmt.Errorf("%w: %s", ErrSimulationFailed, res.Error.Message)
+		}
+		return res, nil, nil
+	}
+}
+
+// getCodec returns the current connection, or dials a new one. Dialing happens
+// without holding the lock, so a slow dial doesn't block requests on a working connection.
+func (t *persistentBlockSimTransport) getCodec(ctx context.Context) (rpcCodec, error) {
+	t.connLock.Lock()
+	codec := t.codec
+	t.connLock.Unlock()
+	if codec != nil {
+		return codec, nil
+	}
+
+	codec, err := t.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+	if t.codec != nil {
+		// another request connected in the meantime
+		_ = codec.Close()
+		return t.codec, nil
+	}
+	t.codec = codec
+	go t.readLoop(codec)
+	return codec, nil
+}
+
+func (t *persistentBlockSimTransport) readLoop(codec rpcCodec) {
+	for {
+		res := new(jsonrpc.JSONRPCResponse)
+		if err := codec.ReadJSON(res); err != nil {
+			t.closeCodec(codec, err)
+			return
+		}
+
+		id := fmt.Sprint(res.ID)
+		t.connLock.Lock()
+		resC, ok := t.pending[id]
+		delete(t.pending, id)
+		t.connLock.Unlock()
+		if ok {
+			resC <- rpcResult{res: res, err: nil}
+		}
+	}
+}
+
+// closeCodec closes a broken connection and fails all requests waiting on it
+func (t *persistentBlockSimTransport) closeCodec(codec rpcCodec, cause error) {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+	if t.codec != codec {
+		return
+	}
+	_ = codec.Close()
+	t.codec = nil
+	for id, resC := range t.pending {
+		resC <- rpcResult{res: nil, err: fmt.Errorf("%w: %w", ErrBlockSimConnectionClosed, cause)}
+		delete(t.pending, id)
+	}
+}
+
+func (t *persistentBlockSimTransport) Close() error {
+	t.connLock.Lock()
+	codec := t.codec
+	t.connLock.Unlock()
+	if codec != nil {
+		t.closeCodec(codec, net.ErrClosed)
+	}
+	return nil
+}
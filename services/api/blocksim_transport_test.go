@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flashbots/go-utils/jsonrpc"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// testSimHandler answers flashbots_validateBuilderSubmissionV2 requests, failing when the param is "fail".
+// Responses to "slow" requests are delayed, so that responses arrive out of order.
+func testSimHandler(req *jsonrpc.JSONRPCRequest) *jsonrpc.JSONRPCResponse {
+	res := &jsonrpc.JSONRPCResponse{ID: req.ID, Version: "2.0"} //nolint:exhaustruct
+	if len(req.Params) > 0 && req.Params[0] == "slow" {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(req.Params) > 0 && req.Params[0] == "fail" {
+		res.Error = &jsonrpc.JSONRPCError{Code: -32000, Message: "invalid block"} //nolint:exhaustruct
+	} else {
+		res.Result = json.RawMessage("null")
+	}
+	return res
+}
+
+func testTransport(t *testing.T, transport IBlockSimTransport) {
+	t.Helper()
+	defer transport.Close()
+
+	type result struct {
+		param         string
+		res           *jsonrpc.JSONRPCResponse
+		requestErr    error
+		validationErr error
+	}
+	params := []string{"slow", "ok", "fail", "ok"}
+	resultC := make(chan result, len(params))
+	for _, param := range params {
+		go func(param string) {
+			req := jsonrpc.NewJSONRPCRequest("1", "flashbots_validateBuilderSubmissionV2", param)
+			res, requestErr, validationErr := transport.SendJSONRPCRequest(context.Background(), *req, false, false)
+			resultC <- result{param, res, requestErr, validationErr}
+		}(param)
+	}
+
+	for range params {
+		r := <-resultC
+		require.NoError(t, r.requestErr)
+		require.Equal(t, "1", r.res.ID)
+		if r.param == "fail" {
+			require.ErrorIs(t, r.validationErr, ErrSimulationFailed)
+			require.Contains(t, r.validationErr.Error(), "invalid block")
+		} else {
+			require.NoError(t, r.validationErr)
+		}
+	}
+}
+
+func TestBlockSimTransportWebSocket(t *testing.T) {
+	upgrader := websocket.Upgrader{} //nolint:exhaustruct
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var writeLock sync.Mutex
+		for {
+			req := new(jsonrpc.JSONRPCRequest)
+			if err := conn.ReadJSON(req); err != nil {
+				return
+			}
+			go func() {
+				res := testSimHandler(req)
+				writeLock.Lock()
+				defer writeLock.Unlock()
+				_ = conn.WriteJSON(res)
+			}()
+		}
+	}))
+	defer srv.Close()
+
+	transport, err := NewBlockSimTransport(strings.Replace(srv.URL, "http://", "ws://", 1))
+	require.NoError(t, err)
+	require.IsType(t, &persistentBlockSimTransport{}, transport) //nolint:exhaustruct
+	testTransport(t, transport)
+}
+
+func TestBlockSimTransportIPC(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "sim.ipc")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				var writeLock sync.Mutex
+				dec := json.NewDecoder(conn)
+				enc := json.NewEncoder(conn)
+				for {
+					req := new(jsonrpc.JSONRPCRequest)
+					if err := dec.Decode(req); err != nil {
+						return
+					}
+					go func() {
+						res := testSimHandler(req)
+						writeLock.Lock()
+						defer writeLock.Unlock()
+						_ = enc.Encode(res)
+					}()
+				}
+			}()
+		}
+	}()
+
+	transport, err := NewBlockSimTransport("ipc://" + socketPath)
+	require.NoError(t, err)
+	testTransport(t, transport)
+
+	// Requests fail cleanly once the simulator is gone, and reconnect afterwards
+	listener.Close()
+	transport, err = NewBlockSimTransport("ipc://" + socketPath)
+	require.NoError(t, err)
+	req := jsonrpc.NewJSONRPCRequest("1", "flashbots_validateBuilderSubmissionV2", "ok")
+	_, requestErr, _ := transport.SendJSONRPCRequest(context.Background(), *req, false, false)
+	require.Error(t, requestErr)
+}
+
+func TestBlockSimTransportStalledConnection(t *testing.T) {
+	// The simulator never reads, so writes on the unbuffered pipe block until the deadline
+	var connsLock sync.Mutex
+	var conns []net.Conn
+	transport := newPersistentBlockSimTransport(func(ctx context.Context) (rpcCodec, error) {
+		conn, peer := net.Pipe()
+		connsLock.Lock()
+		conns = append(conns, peer)
+		connsLock.Unlock()
+		return &streamCodec{conn: conn, enc: json.NewEncoder(conn), dec: json.NewDecoder(conn)}, nil
+	})
+	defer func() {
+		transport.Close()
+		connsLock.Lock()
+		defer connsLock.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	errC := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			req := jsonrpc.NewJSONRPCRequest(fmt.Sprint(i), "flashbots_validateBuilderSubmissionV2", "ok")
+			_, requestErr, _ := transport.SendJSONRPCRequest(ctx, *req, false, false)
+			errC <- requestErr
+		}(i)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-errC:
+			require.Error(t, err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("request blocked on a stalled connection")
+		}
+	}
+}
+
+func TestBlockSimTransportHTTP(t *testing.T) {
+	srv := jsonrpc.NewMockJSONRPCServer()
+	srv.SetHandler("flashbots_validateBuilderSubmissionV2", func(req *jsonrpc.JSONRPCRequest) (interface{}, error) {
+		return nil, nil
+	})
+
+	transport, err := NewBlockSimTransport(srv.URL)
+	require.NoError(t, err)
+	require.IsType(t, &httpBlockSimTransport{}, transport) //nolint:exhaustruct
+	req := jsonrpc.NewJSONRPCRequest("1", "flashbots_validateBuilderSubmissionV2", "ok")
+	_, requestErr, validationErr := transport.SendJSONRPCRequest(context.Background(), *req, true, false)
+	require.NoError(t, requestErr)
+	require.NoError(t, validationErr)
+}
+
+func TestBlockSimTransportUnsupportedScheme(t *testing.T) {
+	_, err := NewBlockSimTransport("ftp://localhost:8545")
+	require.ErrorIs(t, err, ErrUnsupportedBlockSimScheme)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// publishBuilderStatusUpdate lets all API replicas know about a builder status change. Failures are only
+// logged, since the database is already updated and replicas reload it with the next slot anyway.
+func (api *RelayAPI) publishBuilderStatusUpdate(update *datastore.BuilderStatusUpdate) {
+	if err := api.redis.PublishBuilderStatusUpdate(update); err != nil {
+		api.log.WithError(err).WithField("update", update).Error("failed to publish builder status update")
+	}
+}
+
+// startBuilderStatusUpdatesSubscription applies the builder status updates published by all replicas
+// (including this one) to the builder cache, resubscribing if the subscription ends.
+func (api *RelayAPI) startBuilderStatusUpdatesSubscription(ctx context.Context) {
+	for {
+		pubsub := api.redis.SubscribeBuilderStatusUpdates(ctx)
+		for msg := range pubsub.Channel() {
+			update := new(datastore.BuilderStatusUpdate)
+			if err := json.Unmarshal([]byte(msg.Payload), update); err != nil {
+				api.log.WithError(err).Error("failed to decode builder status update")
+				continue
+			}
+			api.applyBuilderStatusUpdate(update)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		api.log.Warn("builder status updates subscription ended, resubscribing")
+		time.Sleep(time.Second)
+	}
+}
+
+func (api *RelayAPI) applyBuilderStatusUpdate(update *datastore.BuilderStatusUpdate) {
+	log := api.log.WithFields(logrus.Fields{
+		"kind":          update.Kind,
+		"builderPubkey": update.BuilderPubkey,
+		"builderID":     update.BuilderID,
+	})
+
+	api.blockBuildersCacheLock.Lock()
+	defer api.blockBuildersCacheLock.Unlock()
+
+	// The entries are used without holding the lock, so they're never changed in place: changed
+	// entries are replaced by copies, and the cache is swapped like in loadBlockBuilders.
+	newCache := make(map[string]*blockBuilderCacheEntry, len(api.blockBuildersCache)+1)
+	for k, v := range api.blockBuildersCache {
+		newCache[k] = v
+	}
+	copyEntry := func(pubkey string) *blockBuilderCacheEntry {
+		entry := &blockBuilderCacheEntry{ //nolint:exhaustruct
+			collateral: big.NewInt(0),
+		}
+		if prev, ok := newCache[pubkey]; ok {
+			*entry = *prev
+		}
+		newCache[pubkey] = entry
+		return entry
+	}
+
+	switch update.Kind {
+	case datastore.BuilderStatusUpdateStatus:
+		entry := copyEntry(update.BuilderPubkey)
+		entry.status = common.BuilderStatus{
+			IsHighPrio:    update.IsHighPrio,
+			IsBlacklisted: update.IsBlacklisted,
+			IsOptimistic:  update.IsOptimistic,
+		}
+	case datastore.BuilderStatusUpdateOptimistic:
+		for pubkey, entry := range api.blockBuildersCache {
+			if pubkey == update.BuilderPubkey || (update.BuilderID != "" && entry.builderID == update.BuilderID) {
+				copyEntry(pubkey).status.IsOptimistic = update.IsOptimistic
+			}
+		}
+	case datastore.BuilderStatusUpdateCollateral:
+		collateral, ok := big.NewInt(0).SetString(update.Collateral, 10)
+		if !ok {
+			log.Errorf("could not parse builder collateral string %s", update.Collateral)
+			return
+		}
+		entry := copyEntry(update.BuilderPubkey)
+		entry.builderID = update.BuilderID
+		entry.collateral = collateral
+	default:
+		log.Warn("unknown builder status update")
+		return
+	}
+	api.blockBuildersCache = newCache
+	log.WithField("update", update).Info("applied builder status update")
+}
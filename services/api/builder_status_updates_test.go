@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyBuilderStatusUpdate(t *testing.T) {
+	pubkey, _, backend := startTestBackend(t)
+	pkStr := pubkey.String()
+	otherPkStr := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	newPkStr := "0xb67a5148a03229926e34b190af81a82a81c4df66831c98c03a139778418dd09a3b542ced0022620d19f35781ece6dc36"
+
+	backend.relay.blockBuildersCache[pkStr].builderID = builderID
+	backend.relay.blockBuildersCache[otherPkStr] = &blockBuilderCacheEntry{
+		status:     common.BuilderStatus{IsOptimistic: true},
+		collateral: big.NewInt(int64(collateral)),
+		builderID:  builderID,
+	}
+
+	// Demotions apply to all pubkeys of the builder id
+	prevEntry := backend.relay.blockBuildersCache[pkStr]
+	require.True(t, prevEntry.status.IsOptimistic)
+	backend.relay.applyBuilderStatusUpdate(&datastore.BuilderStatusUpdate{
+		Kind:          datastore.BuilderStatusUpdateOptimistic,
+		BuilderPubkey: pkStr,
+		BuilderID:     builderID,
+		IsOptimistic:  false,
+	})
+	require.False(t, backend.relay.blockBuildersCache[pkStr].status.IsOptimistic)
+	require.True(t, backend.relay.blockBuildersCache[pkStr].status.IsHighPrio)
+	require.False(t, backend.relay.blockBuildersCache[otherPkStr].status.IsOptimistic)
+
+	// Entries already read by a submission aren't changed in place
+	require.True(t, prevEntry.status.IsOptimistic)
+
+	// Unknown builders are added to the cache
+	backend.relay.applyBuilderStatusUpdate(&datastore.BuilderStatusUpdate{
+		Kind:          datastore.BuilderStatusUpdateStatus,
+		BuilderPubkey: newPkStr,
+		IsBlacklisted: true,
+	})
+	require.True(t, backend.relay.blockBuildersCache[newPkStr].status.IsBlacklisted)
+
+	backend.relay.applyBuilderStatusUpdate(&datastore.BuilderStatusUpdate{
+		Kind:          datastore.BuilderStatusUpdateCollateral,
+		BuilderPubkey: newPkStr,
+		BuilderID:     "builder0x70",
+		Collateral:    "12345",
+	})
+	require.Equal(t, "builder0x70", backend.relay.blockBuildersCache[newPkStr].builderID)
+	require.Equal(t, "12345", backend.relay.blockBuildersCache[newPkStr].collateral.String())
+	require.True(t, backend.relay.blockBuildersCache[newPkStr].status.IsBlacklisted)
+}
+
+func TestBuilderStatusUpdatesAcrossReplicas(t *testing.T) {
+	pubkey, secretkey, backend := startTestBackend(t)
+	pkStr := pubkey.String()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A second replica sharing the same redis
+	replica := &RelayAPI{ //nolint:exhaustruct
+		log:   backend.relay.log,
+		redis: backend.relay.redis,
+		blockBuildersCache: map[string]*blockBuilderCacheEntry{
+			pkStr: {
+				status:     common.BuilderStatus{IsOptimistic: true},
+				collateral: big.NewInt(int64(collateral)),
+			},
+		},
+	}
+	go replica.startBuilderStatusUpdatesSubscription(ctx)
+
+	isOptimistic := func() bool {
+		replica.blockBuildersCacheLock.Lock()
+		defer replica.blockBuildersCacheLock.Unlock()
+		return replica.blockBuildersCache[pkStr].status.IsOptimistic
+	}
+
+	// Publish until the subscription is set up
+	req := common.TestBuilderSubmitBlockRequest(secretkey, getTestBidTrace(*pubkey, collateral))
+	require.Eventually(t, func() bool {
+		backend.relay.demoteBuilder(pkStr, &req, errFake)
+		return !isOptimistic()
+	}, time.Second, 10*time.Millisecond)
+}
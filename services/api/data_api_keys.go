@@ -0,0 +1,304 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const headerDataAPIKey = "X-API-Key"
+
+var (
+	dataAPIQuotaAnonymous = uint64(cli.GetEnvInt("DATA_API_QUOTA_ANONYMOUS_PER_MIN", 300)) // per IP, 0 for no quota
+	dataAPIQuotaKey       = uint64(cli.GetEnvInt("DATA_API_QUOTA_KEY_PER_MIN", 3000))      // per API key, unless set for the key. 0 for no quota
+	dataAPIKeyMaxLimit    = uint64(cli.GetEnvInt("DATA_API_KEY_MAX_LIMIT", 1000))
+
+	// number of proxies in front of the relay which append the client IP to X-Forwarded-For, 0 to use the remote address.
+	// X-Forwarded-For is set by the client without proxies, so it must only be trusted as far as the proxies go.
+	dataAPITrustedProxyHops = cli.GetEnvInt("DATA_API_TRUSTED_PROXY_HOPS", 0)
+
+	// how long API key lookups are cached, revoked keys stay usable on other replicas for up to this long
+	dataAPIKeyCacheTTL = time.Minute
+
+	// maximum number of cached lookups, unknown keys aren't cached beyond it
+	dataAPIKeyCacheMaxEntries = 10000
+)
+
+type dataAPIKeyContextKey struct{}
+
+type dataAPIKeyCacheEntry struct {
+	entry     *database.DataAPIKeyEntry // nil if the key doesn't exist
+	fetchedAt time.Time
+}
+
+// dataAPIKeyCache caches API key lookups by key hash, so authenticated requests don't need a database query
+type dataAPIKeyCache struct {
+	mu      sync.Mutex
+	entries map[string]dataAPIKeyCacheEntry
+}
+
+func newDataAPIKeyCache() *dataAPIKeyCache {
+	return &dataAPIKeyCache{ //nolint:exhaustruct
+		entries: make(map[string]dataAPIKeyCacheEntry),
+	}
+}
+
+func (c *dataAPIKeyCache) Get(keyHash string) (entry *database.DataAPIKeyEntry, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[keyHash]
+	if !ok || time.Since(cached.fetchedAt) > dataAPIKeyCacheTTL {
+		return nil, false
+	}
+	return cached.entry, true
+}
+
+func (c *dataAPIKeyCache) Set(keyHash string, entry *database.DataAPIKeyEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, cached := range c.entries {
+		if time.Since(cached.fetchedAt) > dataAPIKeyCacheTTL {
+			delete(c.entries, k)
+		}
+	}
+	if entry == nil && len(c.entries) >= dataAPIKeyCacheMaxEntries {
+		return
+	}
+	c.entries[keyHash] = dataAPIKeyCacheEntry{entry: entry, fetchedAt: time.Now()}
+}
+
+func (c *dataAPIKeyCache) Delete(keyHash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, keyHash)
+}
+
+func hashDataAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// generateDataAPIKey returns a new random API key
+func generateDataAPIKey() (string, error) {
+	key := make([]byte, 24)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// getDataAPIKey returns the API key of a data API request, nil for anonymous requests
+func getDataAPIKey(req *http.Request) *database.DataAPIKeyEntry {
+	entry, _ := req.Context().Value(dataAPIKeyContextKey{}).(*database.DataAPIKeyEntry)
+	return entry
+}
+
+// dataAPIMaxLimit returns the maximum limit argument of a data API request, which is higher for authenticated requests
+func dataAPIMaxLimit(req *http.Request, maxLimit uint64) uint64 {
+	if getDataAPIKey(req) != nil && dataAPIKeyMaxLimit > maxLimit {
+		return dataAPIKeyMaxLimit
+	}
+	return maxLimit
+}
+
+// lookupDataAPIKey returns the entry of a valid API key from the database, or nil if it doesn't exist
+func (api *RelayAPI) lookupDataAPIKey(keyHash string) (*database.DataAPIKeyEntry, error) {
+	entry, err := api.db.GetDataAPIKeyByHash(keyHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		entry = nil
+	} else if err != nil {
+		return nil, err
+	}
+	api.dataAPIKeys.Set(keyHash, entry)
+	return entry, nil
+}
+
+// withDataAPIQuota authenticates optional API keys, and enforces the requests per minute of API keys and anonymous
+// IPs with counters in Redis. Requests are let through if Redis is unavailable.
+func (api *RelayAPI) withDataAPIQuota(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ipSubject := "ip-" + dataAPIClientIP(req)
+
+		key := req.Header.Get(headerDataAPIKey)
+		if key == "" {
+			if api.checkDataAPIQuota(w, ipSubject, dataAPIQuotaAnonymous) {
+				handler(w, req)
+			}
+			return
+		}
+
+		keyHash := hashDataAPIKey(key)
+		entry, ok := api.dataAPIKeys.Get(keyHash)
+		if !ok {
+			// Keys which aren't cached need a database lookup, which is charged to the IP like an anonymous request
+			if !api.checkDataAPIQuota(w, ipSubject, dataAPIQuotaAnonymous) {
+				return
+			}
+			var err error
+			entry, err = api.lookupDataAPIKey(keyHash)
+			if err != nil {
+				api.log.WithError(err).Error("failed to get data API key")
+				api.RespondError(w, http.StatusInternalServerError, "failed to check api key")
+				return
+			}
+		}
+		if entry == nil || entry.RevokedAt.Valid {
+			api.RespondError(w, http.StatusUnauthorized, "invalid api key")
+			return
+		}
+
+		quota := dataAPIQuotaKey
+		if entry.QuotaPerMin > 0 {
+			quota = entry.QuotaPerMin
+		}
+		if api.checkDataAPIQuota(w, "key-"+strconv.FormatInt(entry.ID, 10), quota) {
+			handler(w, req.WithContext(context.WithValue(req.Context(), dataAPIKeyContextKey{}, entry)))
+		}
+	}
+}
+
+// checkDataAPIQuota counts a request of the subject, and responds with 429 if it's over the quota (0 for no quota)
+func (api *RelayAPI) checkDataAPIQuota(w http.ResponseWriter, subject string, quota uint64) bool {
+	if quota == 0 {
+		return true
+	}
+
+	now := time.Now()
+	count, err := api.redis.IncrDataAPIRequests(subject, now)
+	if err != nil {
+		api.log.WithError(err).Error("failed to count data API request")
+		return true
+	}
+
+	remaining := uint64(0)
+	if uint64(count) < quota {
+		remaining = quota - uint64(count)
+	}
+	w.Header().Set("X-RateLimit-Limit", strconv.FormatUint(quota, 10))
+	w.Header().Set("X-RateLimit-Remaining", strconv.FormatUint(remaining, 10))
+	if uint64(count) > quota {
+		w.Header().Set("Retry-After", strconv.FormatInt(60-now.Unix()%60, 10))
+		api.RespondError(w, http.StatusTooManyRequests, "data API quota exceeded")
+		return false
+	}
+	return true
+}
+
+// dataAPIClientIP returns the IP of the client as seen by the trusted proxies in front of the relay. They append to
+// X-Forwarded-For, so the entries before theirs are set by the client and can't be used to identify it.
+func dataAPIClientIP(req *http.Request) string {
+	ip := req.RemoteAddr
+	if dataAPITrustedProxyHops > 0 {
+		var forwarded []string
+		for _, header := range req.Header.Values("X-Forwarded-For") {
+			forwarded = append(forwarded, strings.Split(header, ",")...)
+		}
+		if len(forwarded) >= dataAPITrustedProxyHops {
+			ip = strings.TrimSpace(forwarded[len(forwarded)-dataAPITrustedProxyHops])
+		}
+	}
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		return host
+	}
+	return ip
+}
+
+// handleInternalDataAPIKeys lists the data API keys, or issues a new one. The key is only returned when it's issued.
+func (api *RelayAPI) handleInternalDataAPIKeys(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodGet {
+		entries, err := api.db.GetDataAPIKeys()
+		if err != nil {
+			api.log.WithError(err).Error("could not get data API keys")
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response := make([]common.DataAPIKeyJSON, len(entries))
+		for i, entry := range entries {
+			response[i] = database.DataAPIKeyEntryToJSON(entry)
+		}
+		api.RespondOK(w, response)
+		return
+	}
+
+	args := req.URL.Query()
+	description := args.Get("description")
+	if description == "" {
+		api.RespondError(w, http.StatusBadRequest, "description argument is required")
+		return
+	}
+	quotaPerMin := uint64(0)
+	if args.Get("quota_per_min") != "" {
+		var err error
+		quotaPerMin, err = strconv.ParseUint(args.Get("quota_per_min"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid quota_per_min argument")
+			return
+		}
+	}
+
+	key, err := generateDataAPIKey()
+	if err != nil {
+		api.log.WithError(err).Error("could not generate data API key")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	entry := &database.DataAPIKeyEntry{ //nolint:exhaustruct
+		KeyHash:     hashDataAPIKey(key),
+		Description: description,
+		QuotaPerMin: quotaPerMin,
+	}
+	if err := api.db.InsertDataAPIKey(entry); err != nil {
+		api.log.WithError(err).Error("could not save data API key")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.log.WithFields(logrus.Fields{
+		"id":          entry.ID,
+		"description": description,
+		"quotaPerMin": quotaPerMin,
+	}).Info("issued data API key")
+	api.auditInternalAPI(req, auditActionDataAPIKeyIssue, strconv.FormatInt(entry.ID, 10), nil, database.DataAPIKeyEntryToJSON(entry))
+	response := database.DataAPIKeyEntryToJSON(entry)
+	response.Key = key
+	api.RespondOK(w, response)
+}
+
+// handleInternalDataAPIKey revokes a data API key. Other replicas may still accept it while it's in their key cache.
+func (api *RelayAPI) handleInternalDataAPIKey(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(req)["id"], 10, 64)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid api key id")
+		return
+	}
+
+	entry, err := api.db.RevokeDataAPIKey(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		api.RespondError(w, http.StatusNotFound, "api key not found")
+		return
+	} else if err != nil {
+		api.log.WithError(err).Error("could not revoke data API key")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.dataAPIKeys.Delete(entry.KeyHash)
+	api.auditInternalAPI(req, auditActionDataAPIKeyRevoke, strconv.FormatInt(id, 10), nil, database.DataAPIKeyEntryToJSON(entry))
+	api.log.WithField("id", id).Info("revoked data API key")
+	api.RespondOK(w, database.DataAPIKeyEntryToJSON(entry))
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataApiKeys(t *testing.T) {
+	pathKeys := "/internal/v1/data_api_keys"
+	pathPayloads := "/relay/v1/data/bidtraces/proposer_payload_delivered"
+
+	backend := newTestBackend(t, 1)
+	backend.relay.db = database.MockDB{DataAPIKeys: make(map[string]*database.DataAPIKeyEntry)} //nolint:exhaustruct
+
+	rr := backend.request(http.MethodPost, pathKeys, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = backend.request(http.MethodPost, pathKeys+"?description=test&quota_per_min=abc", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Issue a key
+	rr = backend.request(http.MethodPost, pathKeys+"?description=test&quota_per_min=2", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	issued := new(common.DataAPIKeyJSON)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), issued))
+	require.NotEmpty(t, issued.Key)
+	require.Equal(t, uint64(2), issued.QuotaPerMin)
+
+	// The key itself is not listed
+	rr = backend.request(http.MethodGet, pathKeys, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	keys := []common.DataAPIKeyJSON{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &keys))
+	require.Len(t, keys, 1)
+	require.Equal(t, issued.ID, keys[0].ID)
+	require.Empty(t, keys[0].Key)
+
+	t.Run("authenticated requests can use a higher limit", func(t *testing.T) {
+		rr := backend.request(http.MethodGet, pathPayloads+"?limit=201", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "maximum limit is 200")
+
+		rr = backend.requestBytes(http.MethodGet, pathPayloads+"?limit=201", nil, map[string]string{headerDataAPIKey: issued.Key})
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "2", rr.Header().Get("X-RateLimit-Limit"))
+		require.Equal(t, "1", rr.Header().Get("X-RateLimit-Remaining"))
+
+		// The cached authenticated response isn't served to anonymous requests
+		rr = backend.request(http.MethodGet, pathPayloads+"?limit=201", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("key quota", func(t *testing.T) {
+		rr := backend.requestBytes(http.MethodGet, pathPayloads, nil, map[string]string{headerDataAPIKey: issued.Key})
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = backend.requestBytes(http.MethodGet, pathPayloads, nil, map[string]string{headerDataAPIKey: issued.Key})
+		require.Equal(t, http.StatusTooManyRequests, rr.Code)
+		require.NotEmpty(t, rr.Header().Get("Retry-After"))
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		rr := backend.requestBytes(http.MethodGet, pathPayloads, nil, map[string]string{headerDataAPIKey: "abc"})
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		rr := backend.request(http.MethodDelete, pathKeys+"/123", nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = backend.request(http.MethodDelete, pathKeys+"/"+strconv.FormatInt(issued.ID, 10), nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		revoked := new(common.DataAPIKeyJSON)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), revoked))
+		require.True(t, revoked.Revoked)
+
+		rr = backend.requestBytes(http.MethodGet, pathPayloads, nil, map[string]string{headerDataAPIKey: issued.Key})
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestDataApiAnonymousQuota(t *testing.T) {
+	path := "/relay/v1/data/bidtraces/proposer_payload_delivered"
+	backend := newTestBackend(t, 1)
+
+	prevQuota := dataAPIQuotaAnonymous
+	dataAPIQuotaAnonymous = 2
+	defer func() { dataAPIQuotaAnonymous = prevQuota }()
+
+	// Without trusted proxies, X-Forwarded-For is set by the client and ignored
+	request := func(remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rr := httptest.NewRecorder()
+		backend.relay.getRouter().ServeHTTP(rr, req)
+		return rr
+	}
+	for i := 0; i < 2; i++ {
+		rr := request("192.0.2.1:1234", "10.0.0."+strconv.Itoa(i))
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+	rr := request("192.0.2.1:4321", "10.0.0.2")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	rr = request("192.0.2.2:1234", "10.0.0.2")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Behind a proxy, the IP it appends is used
+	prevHops := dataAPITrustedProxyHops
+	dataAPITrustedProxyHops = 1
+	defer func() { dataAPITrustedProxyHops = prevHops }()
+
+	for i := 0; i < 2; i++ {
+		rr := backend.requestBytes(http.MethodGet, path, nil, map[string]string{"X-Forwarded-For": "1.2.3.4"})
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+	rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"X-Forwarded-For": "1.2.3.4"})
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	// Entries added by the client before the one of the proxy don't matter
+	rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"X-Forwarded-For": "9.9.9.9, 1.2.3.4"})
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	// Other IPs have their own quota
+	rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"X-Forwarded-For": "5.6.7.8"})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Looking up unknown keys is charged to the IP
+	backend.relay.db = database.MockDB{DataAPIKeys: make(map[string]*database.DataAPIKeyEntry)} //nolint:exhaustruct
+	for i := 0; i < 2; i++ {
+		rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"X-Forwarded-For": "10.0.0.1", headerDataAPIKey: strconv.Itoa(i)})
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	}
+	rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"X-Forwarded-For": "10.0.0.1", headerDataAPIKey: "2"})
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+}
+
+func TestDataAPIKeyCacheSize(t *testing.T) {
+	prevMaxEntries := dataAPIKeyCacheMaxEntries
+	dataAPIKeyCacheMaxEntries = 2
+	defer func() { dataAPIKeyCacheMaxEntries = prevMaxEntries }()
+
+	cache := newDataAPIKeyCache()
+	cache.Set("a", nil)
+	cache.Set("b", nil)
+	cache.Set("c", nil)
+	_, ok := cache.Get("c")
+	require.False(t, ok)
+
+	// Valid keys are always cached
+	cache.Set("d", &database.DataAPIKeyEntry{ID: 1}) //nolint:exhaustruct
+	entry, ok := cache.Get("d")
+	require.True(t, ok)
+	require.Equal(t, int64(1), entry.ID)
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/go-redis/redis/v9"
+)
+
+var (
+	dataCacheMaxBytes       = cli.GetEnvInt("DATA_API_CACHE_SIZE_MB", 128) * 1024 * 1024 // 0 to disable
+	dataCacheImmutableSlots = uint64(cli.GetEnvInt("DATA_API_CACHE_IMMUTABLE_SLOTS", 32))
+	dataCacheMaxAgeSec      = cli.GetEnvInt("DATA_API_CACHE_MAX_AGE_SEC", 86400)
+
+	// mutable entries are also dropped after a slot, as the payload is stored in the database after the delivery is recorded
+	dataCacheMutableTTL = time.Duration(common.SecondsPerSlot) * time.Second
+)
+
+// dataCachePolicy says which responses of a data API endpoint may be cached
+type dataCachePolicy int
+
+const (
+	// only cache queries with an upper slot bound which is far enough in the past
+	dataCacheImmutableOnly dataCachePolicy = iota
+	// also cache queries that include the head, until the next delivered payload
+	dataCacheUntilDelivery
+)
+
+type dataCacheEntry struct {
+	header    http.Header
+	body      []byte
+	etag      string
+	immutable bool
+
+	// for mutable entries: the last delivered slot when the entry was stored, and when it expires
+	lastSlotDelivered uint64
+	expiresAt         time.Time
+}
+
+// dataResponseCache stores serialized data API responses, keyed by path,
+// normalized query parameters and response format. The size is bounded, and
+// arbitrary entries are evicted when it's full.
+type dataResponseCache struct {
+	mu       sync.RWMutex
+	entries  map[string]*dataCacheEntry
+	size     int
+	maxBytes int
+}
+
+func newDataResponseCache(maxBytes int) *dataResponseCache {
+	return &dataResponseCache{ //nolint:exhaustruct
+		entries:  make(map[string]*dataCacheEntry),
+		maxBytes: maxBytes,
+	}
+}
+
+func (c *dataResponseCache) Get(key string) *dataCacheEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.entries[key]
+}
+
+func (c *dataResponseCache) Set(key string, entry *dataCacheEntry) {
+	if len(entry.body) > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+	for k := range c.entries {
+		if c.size+len(entry.body) <= c.maxBytes {
+			break
+		}
+		c.remove(k)
+	}
+	c.entries[key] = entry
+	c.size += len(entry.body)
+}
+
+// Len returns the number of cached responses
+func (c *dataResponseCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+func (c *dataResponseCache) remove(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.size -= len(entry.body)
+		delete(c.entries, key)
+	}
+}
+
+// dataCacheKey normalizes the query parameters, so the order in which they are given doesn't matter.
+// Authenticated requests are cached separately, as they are allowed higher limits.
+func dataCacheKey(req *http.Request) string {
+	key := req.URL.Path + "?" + req.URL.Query().Encode()
+	if getDataAPIKey(req) != nil {
+		key += "#authenticated"
+	}
+	return key
+}
+
+// dataQueryMaxSlot returns the highest slot a data API query can return, if it's bounded by slot, slot_to or epoch
+func dataQueryMaxSlot(req *http.Request) (maxSlot uint64, ok bool) {
+	args := req.URL.Query()
+	if args.Get("slot") != "" {
+		slot, err := strconv.ParseUint(args.Get("slot"), 10, 64)
+		return slot, err == nil
+	} else if args.Get("slot_to") != "" {
+		slotTo, err := strconv.ParseUint(args.Get("slot_to"), 10, 64)
+		return slotTo, err == nil
+	} else if args.Get("epoch") != "" {
+		epoch, err := strconv.ParseUint(args.Get("epoch"), 10, 64)
+		return (epoch+1)*common.SlotsPerEpoch - 1, err == nil
+	}
+	return 0, false
+}
+
+// withDataCache serves successful responses of a data API endpoint from the response cache.
+//
+// Queries for slots that are fully in the past are cached until evicted, and served with a long max-age. With the
+// dataCacheUntilDelivery policy, queries that include the head are cached until the relay (any replica) delivers the
+// next payload, and clients need to revalidate them with the ETag.
+//
+// Only JSON responses are cached: CSV and NDJSON responses are streamed to the client, which the buffering needed
+// for the cache would prevent.
+func (api *RelayAPI) withDataCache(policy dataCachePolicy, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if api.dataCache == nil || req.Method != http.MethodGet || dataResponseContentType(req) != contentTypeJSON {
+			handler(w, req)
+			return
+		}
+
+		headSlot := api.headSlot.Load()
+		maxSlot, bounded := dataQueryMaxSlot(req)
+		immutable := bounded && headSlot > 0 && maxSlot+dataCacheImmutableSlots < headSlot
+
+		var lastSlotDelivered uint64
+		if !immutable {
+			if policy != dataCacheUntilDelivery {
+				handler(w, req)
+				return
+			}
+
+			var err error
+			lastSlotDelivered, err = api.redis.GetLastSlotDelivered()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				api.log.WithError(err).Debug("data cache: failed to get last delivered slot")
+				handler(w, req)
+				return
+			}
+		}
+
+		key := dataCacheKey(req)
+		entry := api.dataCache.Get(key)
+		if entry != nil && (entry.immutable || (!immutable && entry.lastSlotDelivered == lastSlotDelivered && time.Now().Before(entry.expiresAt))) {
+			respondDataCacheEntry(w, req, entry)
+			return
+		}
+
+		rec := newDataCacheRecorder()
+		handler(rec, req)
+		if rec.status != http.StatusOK {
+			rec.writeTo(w)
+			return
+		}
+
+		hash := sha256.Sum256(rec.body.Bytes())
+		entry = &dataCacheEntry{
+			header:            rec.header,
+			body:              rec.body.Bytes(),
+			etag:              `"` + hex.EncodeToString(hash[:16]) + `"`,
+			immutable:         immutable,
+			lastSlotDelivered: lastSlotDelivered,
+			expiresAt:         time.Now().Add(dataCacheMutableTTL),
+		}
+		api.dataCache.Set(key, entry)
+		respondDataCacheEntry(w, req, entry)
+	}
+}
+
+func respondDataCacheEntry(w http.ResponseWriter, req *http.Request, entry *dataCacheEntry) {
+	for k, v := range entry.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("ETag", entry.etag)
+	w.Header().Set("Vary", "Accept")
+	if entry.immutable {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", dataCacheMaxAgeSec))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	if req.Header.Get("If-None-Match") == entry.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(entry.body)
+}
+
+// dataCacheRecorder buffers a response so it can be stored in the data cache
+type dataCacheRecorder struct {
+	header http.Header
+	body   *bytes.Buffer
+	status int
+}
+
+func newDataCacheRecorder() *dataCacheRecorder {
+	return &dataCacheRecorder{
+		header: make(http.Header),
+		body:   new(bytes.Buffer),
+		status: http.StatusOK,
+	}
+}
+
+func (r *dataCacheRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *dataCacheRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *dataCacheRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *dataCacheRecorder) writeTo(w http.ResponseWriter) {
+	for k, v := range r.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(r.status)
+	_, _ = w.Write(r.body.Bytes())
+}
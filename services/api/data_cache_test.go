@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataApiCache(t *testing.T) {
+	pathPayloads := "/relay/v1/data/bidtraces/proposer_payload_delivered"
+	pathBids := "/relay/v1/data/bidtraces/builder_blocks_received"
+
+	backend := newTestBackend(t, 1)
+	backend.relay.headSlot.Store(100)
+	setDB := func(slots ...uint64) {
+		mockDB := database.MockDB{}
+		for _, slot := range slots {
+			mockDB.DeliveredPayloads = append(mockDB.DeliveredPayloads, &database.DeliveredPayloadEntry{Slot: slot, Value: "1"})              //nolint:exhaustruct
+			mockDB.BuilderSubmissions = append(mockDB.BuilderSubmissions, &database.BuilderBlockSubmissionEntry{ID: int64(slot), Slot: slot}) //nolint:exhaustruct
+		}
+		backend.relay.db = mockDB
+	}
+	numResults := func(rr *httptest.ResponseRecorder) int {
+		resp := []common.BidTraceV2JSON{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return len(resp)
+	}
+
+	t.Run("queries in the past are cached with a long max-age", func(t *testing.T) {
+		setDB(10)
+		rr := backend.request(http.MethodGet, pathPayloads+"?slot=10", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 1, numResults(rr))
+		require.Equal(t, "public, max-age=86400", rr.Header().Get("Cache-Control"))
+		etag := rr.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		setDB()
+		rr = backend.request(http.MethodGet, pathPayloads+"?slot=10", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 1, numResults(rr))
+		require.Equal(t, etag, rr.Header().Get("ETag"))
+
+		rr = backend.requestBytes(http.MethodGet, pathPayloads+"?slot=10", nil, map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusNotModified, rr.Code)
+		require.Empty(t, rr.Body.Bytes())
+	})
+
+	t.Run("query parameters are normalized", func(t *testing.T) {
+		setDB(20, 21)
+		rr := backend.request(http.MethodGet, pathBids+"?slot_from=20&slot_to=21", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 2, numResults(rr))
+
+		setDB()
+		rr = backend.request(http.MethodGet, pathBids+"?slot_to=21&slot_from=20", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 2, numResults(rr))
+
+		// the CSV response is streamed past the cache
+		setDB(20)
+		rr = backend.requestBytes(http.MethodGet, pathBids+"?slot_to=21&slot_from=20", nil, map[string]string{"Accept": "text/csv"})
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, contentTypeCSV, rr.Header().Get("Content-Type"))
+		require.Equal(t, "Accept", rr.Header().Get("Vary"))
+		require.Empty(t, rr.Header().Get("ETag"))
+		records, err := csv.NewReader(rr.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+	})
+
+	t.Run("recent bids are not cached", func(t *testing.T) {
+		setDB(90)
+		rr := backend.request(http.MethodGet, pathBids+"?slot=90", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 1, numResults(rr))
+		require.Empty(t, rr.Header().Get("ETag"))
+
+		setDB()
+		rr = backend.request(http.MethodGet, pathBids+"?slot=90", nil)
+		require.Equal(t, 0, numResults(rr))
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		rr := backend.request(http.MethodGet, pathBids+"?slot=10&block_number=abc", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Empty(t, rr.Header().Get("ETag"))
+	})
+
+	t.Run("recent deliveries are cached until the next delivery", func(t *testing.T) {
+		setDB(98)
+		rr := backend.request(http.MethodGet, pathPayloads, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 1, numResults(rr))
+		require.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+		etag := rr.Header().Get("ETag")
+
+		setDB(99, 98)
+		rr = backend.requestBytes(http.MethodGet, pathPayloads, nil, map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusNotModified, rr.Code)
+
+		err := backend.redis.CheckAndSetLastSlotAndHashDelivered(99, "0x01")
+		require.NoError(t, err)
+		rr = backend.requestBytes(http.MethodGet, pathPayloads, nil, map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 2, numResults(rr))
+		require.NotEqual(t, etag, rr.Header().Get("ETag"))
+	})
+}
+
+func TestDataResponseCacheEviction(t *testing.T) {
+	cache := newDataResponseCache(10)
+	cache.Set("a", &dataCacheEntry{body: []byte("12345")}) //nolint:exhaustruct
+	cache.Set("b", &dataCacheEntry{body: []byte("12345")}) //nolint:exhaustruct
+	require.Equal(t, 2, cache.Len())
+
+	// replacing an entry doesn't evict others
+	cache.Set("b", &dataCacheEntry{body: []byte("1234")}) //nolint:exhaustruct
+	require.Equal(t, 2, cache.Len())
+
+	cache.Set("c", &dataCacheEntry{body: []byte("12345")}) //nolint:exhaustruct
+	require.NotNil(t, cache.Get("c"))
+	require.LessOrEqual(t, cache.size, 10)
+
+	// too large to be cached
+	cache.Set("d", &dataCacheEntry{body: []byte("12345678901")}) //nolint:exhaustruct
+	require.Nil(t, cache.Get("d"))
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+)
+
+const (
+	contentTypeJSON   = "application/json"
+	contentTypeCSV    = "text/csv"
+	contentTypeNDJSON = "application/x-ndjson"
+
+	// number of rows written between flushes of CSV and NDJSON responses
+	dataStreamFlushRows = 100
+)
+
+// csvRow is implemented by pointers to data API response types that can be encoded as CSV
+type csvRow[T any] interface {
+	*T
+	CSVHeader() []string
+	ToCSVRecord() []string
+}
+
+type responseFlusherContextKey struct{}
+
+// withResponseFlusher makes the flusher of the response available to the handlers, as the writer they get from
+// the request logger doesn't implement http.Flusher
+func withResponseFlusher(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if flusher, ok := w.(http.Flusher); ok {
+			req = req.WithContext(context.WithValue(req.Context(), responseFlusherContextKey{}, flusher))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// getResponseFlusher returns the flusher of a response, nil if it can't be flushed
+func getResponseFlusher(w http.ResponseWriter, req *http.Request) http.Flusher {
+	if flusher, ok := w.(http.Flusher); ok {
+		return flusher
+	}
+	flusher, _ := req.Context().Value(responseFlusherContextKey{}).(http.Flusher)
+	return flusher
+}
+
+// dataResponseContentType returns the response format requested in the Accept header, defaulting to JSON
+func dataResponseContentType(req *http.Request) string {
+	accept := req.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, contentTypeCSV):
+		return contentTypeCSV
+	case strings.Contains(accept, contentTypeNDJSON):
+		return contentTypeNDJSON
+	default:
+		return contentTypeJSON
+	}
+}
+
+// respondDataRows responds with a JSON array, or with CSV or NDJSON if requested in the Accept header.
+// CSV and NDJSON are streamed: rows are encoded one by one, and flushed to the client as they're written.
+func respondDataRows[T any, PT csvRow[T]](api *RelayAPI, w http.ResponseWriter, req *http.Request, rows []T) {
+	w.Header().Set("Vary", "Accept")
+	contentType := dataResponseContentType(req)
+	if contentType == contentTypeJSON {
+		api.RespondOK(w, rows)
+		return
+	}
+
+	log := api.log.WithField("contentType", contentType)
+	flusher := getResponseFlusher(w, req)
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+
+	if contentType == contentTypeNDJSON {
+		encoder := json.NewEncoder(w)
+		for i := range rows {
+			if err := encoder.Encode(&rows[i]); err != nil {
+				log.WithError(err).Error("error writing ndjson response")
+				return
+			}
+			if flusher != nil && (i+1)%dataStreamFlushRows == 0 {
+				flusher.Flush()
+			}
+		}
+		return
+	}
+
+	csvWriter := csv.NewWriter(w)
+	defer csvWriter.Flush()
+	if err := csvWriter.Write(PT(new(T)).CSVHeader()); err != nil {
+		log.WithError(err).Error("error writing csv response")
+		return
+	}
+	for i := range rows {
+		if err := csvWriter.Write(PT(&rows[i]).ToCSVRecord()); err != nil {
+			log.WithError(err).Error("error writing csv response")
+			return
+		}
+		if flusher != nil && (i+1)%dataStreamFlushRows == 0 {
+			csvWriter.Flush()
+			flusher.Flush()
+		}
+	}
+}
+
+// validatorRegistrationRow adds CSV encoding to the validator registration data API response
+type validatorRegistrationRow struct {
+	*boostTypes.SignedValidatorRegistration
+}
+
+func (r *validatorRegistrationRow) CSVHeader() []string {
+	return []string{
+		"pubkey",
+		"fee_recipient",
+		"gas_limit",
+		"timestamp",
+		"signature",
+	}
+}
+
+func (r *validatorRegistrationRow) ToCSVRecord() []string {
+	return []string{
+		r.Message.Pubkey.String(),
+		r.Message.FeeRecipient.String(),
+		fmt.Sprint(r.Message.GasLimit),
+		fmt.Sprint(r.Message.Timestamp),
+		r.Signature.String(),
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataApiResponseFormats(t *testing.T) {
+	path := "/relay/v1/data/bidtraces/proposer_payload_delivered"
+	backend := newTestBackend(t, 1)
+	mockDB := database.MockDB{}
+	for slot := uint64(10); slot > 7; slot-- {
+		mockDB.DeliveredPayloads = append(mockDB.DeliveredPayloads, &database.DeliveredPayloadEntry{Slot: slot, Value: "123"}) //nolint:exhaustruct
+	}
+	backend.relay.db = mockDB
+	prevDB := backend.relay.db
+
+	t.Run("JSON by default", func(t *testing.T) {
+		rr := backend.requestBytes(http.MethodGet, path, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, contentTypeJSON, rr.Header().Get("Content-Type"))
+		resp := []common.BidTraceV2JSON{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp, 3)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		rr := backend.requestBytes(http.MethodGet, path, nil, map[string]string{"Accept": "text/csv"})
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, contentTypeCSV, rr.Header().Get("Content-Type"))
+		records, err := csv.NewReader(rr.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		require.Equal(t, (&common.BidTraceV2JSON{}).CSVHeader(), records[0]) //nolint:exhaustruct
+		require.Equal(t, "10", records[1][0])
+		require.Equal(t, "123", records[1][8])
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		rr := backend.requestBytes(http.MethodGet, path, nil, map[string]string{"Accept": "application/x-ndjson"})
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, contentTypeNDJSON, rr.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+		require.Len(t, lines, 3)
+		row := new(common.BidTraceV2JSON)
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), row))
+		require.Equal(t, uint64(8), row.Slot)
+	})
+
+	t.Run("CSV and NDJSON are flushed while they're written", func(t *testing.T) {
+		mockDB := database.MockDB{}
+		for slot := uint64(1); slot <= dataStreamFlushRows+1; slot++ {
+			mockDB.DeliveredPayloads = append(mockDB.DeliveredPayloads, &database.DeliveredPayloadEntry{Slot: slot, Value: "123"}) //nolint:exhaustruct
+		}
+		backend.relay.db = mockDB
+		defer func() { backend.relay.db = prevDB }()
+
+		for _, accept := range []string{contentTypeCSV, contentTypeNDJSON} {
+			rr := backend.requestBytes(http.MethodGet, path+"?slot_from=1&slot_to=200&limit=200", nil, map[string]string{"Accept": accept})
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.True(t, rr.Flushed, accept)
+			require.Empty(t, rr.Header().Get("ETag"), accept)
+		}
+	})
+
+	t.Run("errors stay JSON", func(t *testing.T) {
+		rr := backend.requestBytes(http.MethodGet, path+"?slot=abc", nil, map[string]string{"Accept": "text/csv"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, contentTypeJSON, rr.Header().Get("Content-Type"))
+	})
+}
+
+func TestDataResponseContentType(t *testing.T) {
+	testCases := map[string]string{
+		"":                                  contentTypeJSON,
+		"*/*":                               contentTypeJSON,
+		"application/json":                  contentTypeJSON,
+		"text/csv":                          contentTypeCSV,
+		"text/csv; charset=utf-8":           contentTypeCSV,
+		"application/x-ndjson":              contentTypeNDJSON,
+		"application/x-ndjson, */*;q=0.8":   contentTypeNDJSON,
+		"text/html,application/xhtml+xml,*": contentTypeJSON,
+	}
+	for accept, expected := range testCases {
+		req, err := http.NewRequest(http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+		require.Equal(t, expected, dataResponseContentType(req), accept)
+	}
+}
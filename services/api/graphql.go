@@ -0,0 +1,295 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	uberatomic "go.uber.org/atomic"
+)
+
+var (
+	ErrGraphQLInvalidQuery    = errors.New("invalid graphql query")
+	ErrGraphQLInvalidArgument = errors.New("invalid argument")
+
+	dataAPIGraphQLMaxCost  = uint64(cli.GetEnvInt("DATA_API_GRAPHQL_MAX_COST", 10000))
+	dataAPIGraphQLMaxDepth = cli.GetEnvInt("DATA_API_GRAPHQL_MAX_DEPTH", 5)
+
+	// maximum size of a graphql request body
+	dataAPIGraphQLMaxBodyBytes = int64(64 * 1024)
+)
+
+type gqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type gqlStateKey struct{}
+
+// gqlRequestState is shared by the resolvers of a request. It keeps track of the query cost, and loads
+// the builders and their demotions once for all the fields that need them.
+type gqlRequestState struct {
+	api  *RelayAPI
+	cost uberatomic.Uint64
+
+	buildersOnce sync.Once
+	builders     map[string]*database.BlockBuilderEntry
+	buildersErr  error
+
+	demotionsLock sync.Mutex
+	demotions     map[[2]uint64]*gqlDemotionsLoad // by slot range
+}
+
+type gqlDemotionsLoad struct {
+	once      sync.Once
+	byBuilder map[string][]*database.BuilderDemotionEntry
+	err       error
+}
+
+func gqlState(ctx context.Context) *gqlRequestState {
+	return ctx.Value(gqlStateKey{}).(*gqlRequestState)
+}
+
+// gqlLogger logs the panics during the execution of a query, which also happen for integer literals
+// that are out of range, without a stack trace
+type gqlLogger struct{}
+
+func (gqlLogger) LogPanic(ctx context.Context, value any) {
+	gqlState(ctx).api.log.WithField("panic", value).Warn("panic while executing graphql query")
+}
+
+// charge adds to the cost of the query before the database is queried, and fails once the maximum
+// cost is exceeded. A database query costs one, plus the maximum number of rows it returns.
+func (s *gqlRequestState) charge(cost uint64) error {
+	if s.cost.Add(cost) > dataAPIGraphQLMaxCost {
+		return fmt.Errorf("%w: query cost exceeds the maximum of %d, request fewer results", ErrGraphQLInvalidQuery, dataAPIGraphQLMaxCost)
+	}
+	return nil
+}
+
+// builder returns a block builder by pubkey, or nil if it's unknown. All builders are loaded with the first lookup.
+func (s *gqlRequestState) builder(pubkey string) (*database.BlockBuilderEntry, error) {
+	s.buildersOnce.Do(func() {
+		if s.buildersErr = s.charge(1); s.buildersErr != nil {
+			return
+		}
+		entries, err := s.api.db.GetBlockBuilders()
+		if err != nil {
+			s.buildersErr = err
+			return
+		}
+		s.builders = make(map[string]*database.BlockBuilderEntry, len(entries))
+		for _, entry := range entries {
+			s.builders[entry.BuilderPubkey] = entry
+		}
+	})
+	return s.builders[pubkey], s.buildersErr
+}
+
+// builderDemotions returns the demotions of a builder in the slot range. The demotions of all builders in
+// the range are loaded with the first lookup, and are charged by their number.
+func (s *gqlRequestState) builderDemotions(slotFrom, slotTo uint64, pubkey string) ([]*database.BuilderDemotionEntry, error) {
+	s.demotionsLock.Lock()
+	load, ok := s.demotions[[2]uint64{slotFrom, slotTo}]
+	if !ok {
+		load = new(gqlDemotionsLoad)
+		s.demotions[[2]uint64{slotFrom, slotTo}] = load
+	}
+	s.demotionsLock.Unlock()
+
+	load.once.Do(func() {
+		if load.err = s.charge(1); load.err != nil {
+			return
+		}
+		entries, err := s.api.db.GetBuilderDemotions(database.GetBuilderDemotionsFilters{SlotFrom: slotFrom, SlotTo: slotTo}) //nolint:exhaustruct
+		if err != nil {
+			load.err = err
+			return
+		}
+		if load.err = s.charge(uint64(len(entries))); load.err != nil {
+			return
+		}
+		load.byBuilder = make(map[string][]*database.BuilderDemotionEntry)
+		for _, entry := range entries {
+			load.byBuilder[entry.BuilderPubkey] = append(load.byBuilder[entry.BuilderPubkey], entry)
+		}
+	})
+	return load.byBuilder[pubkey], load.err
+}
+
+// handleDataGraphQL executes a read-only GraphQL query, given in the query argument or as a JSON POST body.
+// The resolvers charge each database query to the cost of the query, which fails once the maximum cost is
+// exceeded. Queries which are nested too deeply are rejected.
+func (api *RelayAPI) handleDataGraphQL(w http.ResponseWriter, req *http.Request) {
+	gqlReq := new(gqlRequest)
+	if req.Method == http.MethodPost {
+		if err := json.NewDecoder(io.LimitReader(req.Body, dataAPIGraphQLMaxBodyBytes)).Decode(gqlReq); err != nil {
+			api.respondGraphQLError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	} else {
+		args := req.URL.Query()
+		gqlReq.Query = args.Get("query")
+		gqlReq.OperationName = args.Get("operationName")
+		if args.Get("variables") != "" {
+			if err := json.Unmarshal([]byte(args.Get("variables")), &gqlReq.Variables); err != nil {
+				api.respondGraphQLError(w, http.StatusBadRequest, "invalid variables argument")
+				return
+			}
+		}
+	}
+	if gqlReq.Query == "" {
+		api.respondGraphQLError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	state := &gqlRequestState{api: api, demotions: make(map[[2]uint64]*gqlDemotionsLoad)} //nolint:exhaustruct
+	ctx := context.WithValue(req.Context(), gqlStateKey{}, state)
+	resp := dataGraphQLSchema.Exec(ctx, gqlReq.Query, gqlReq.OperationName, gqlReq.Variables)
+	if len(resp.Errors) == 0 {
+		api.RespondOK(w, resp)
+		return
+	}
+
+	// a query with errors fails as a whole. Errors without a resolver error are syntax and validation errors.
+	code := http.StatusBadRequest
+	for _, qErr := range resp.Errors {
+		if qErr.ResolverError != nil && !errors.Is(qErr.ResolverError, ErrGraphQLInvalidQuery) && !errors.Is(qErr.ResolverError, ErrGraphQLInvalidArgument) {
+			api.log.WithError(qErr.ResolverError).Error("error executing graphql query")
+			code = http.StatusInternalServerError
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(graphql.Response{Errors: resp.Errors}); err != nil { //nolint:exhaustruct
+		api.log.WithError(err).Error("couldn't write graphql error response")
+	}
+}
+
+func (api *RelayAPI) respondGraphQLError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(graphql.Response{Errors: []*gqlerrors.QueryError{{Message: message}}}); err != nil { //nolint:exhaustruct
+		api.log.WithError(err).Error("couldn't write graphql error response")
+	}
+}
+
+// gqlUint64 is the Uint64 scalar. Like the integers of the REST data API it's encoded as a string, and
+// it can be given as a string or as an integer.
+type gqlUint64 uint64
+
+func (gqlUint64) ImplementsGraphQLType(name string) bool {
+	return name == "Uint64"
+}
+
+func (n *gqlUint64) UnmarshalGraphQL(input any) error {
+	switch v := input.(type) {
+	case string:
+		value, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid Uint64 %q", ErrGraphQLInvalidArgument, v)
+		}
+		*n = gqlUint64(value)
+		return nil
+	case int32:
+		if v >= 0 {
+			*n = gqlUint64(v)
+			return nil
+		}
+	case float64:
+		// larger integers in the JSON variables need to be given as strings
+		if v >= 0 && v <= 1<<53 && v == math.Trunc(v) {
+			*n = gqlUint64(v)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: expected a non-negative integer", ErrGraphQLInvalidArgument)
+}
+
+func (n gqlUint64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(n), 10))
+}
+
+// uint64 returns the value of an optional argument, 0 if it's not given
+func (n *gqlUint64) uint64() uint64 {
+	if n == nil {
+		return 0
+	}
+	return uint64(*n)
+}
+
+// gqlString returns the value of an optional argument, "" if it's not given
+func gqlString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// gqlSlotRange checks the slot or inclusive slot range arguments, with the same restrictions as the REST data API
+func gqlSlotRange(slot, slotFrom, slotTo *gqlUint64) error {
+	if (slotFrom == nil) != (slotTo == nil) {
+		return fmt.Errorf("%w: slotFrom and slotTo need to be given together", ErrGraphQLInvalidArgument)
+	} else if slot != nil && slotFrom != nil {
+		return fmt.Errorf("%w: cannot specify both slot and slot range", ErrGraphQLInvalidArgument)
+	} else if slotFrom != nil && *slotFrom > *slotTo {
+		return fmt.Errorf("%w: slotFrom is after slotTo", ErrGraphQLInvalidArgument)
+	} else if slotFrom != nil && uint64(*slotTo-*slotFrom) >= dataAPIMaxSlotRange {
+		return fmt.Errorf("%w: maximum slot range is %d", ErrGraphQLInvalidArgument, dataAPIMaxSlotRange)
+	}
+	return nil
+}
+
+// gqlCheckPubkey checks that a pubkey argument is a valid BLS public key, if it's given
+func gqlCheckPubkey(name string, pubkey *string) error {
+	if pubkey != nil && checkBLSPublicKeyHex(*pubkey) != nil {
+		return fmt.Errorf("%w: invalid %s", ErrGraphQLInvalidArgument, name)
+	}
+	return nil
+}
+
+// limit checks the limit argument of a list field, and charges the database query which returns at most
+// limit rows
+func (s *gqlRequestState) limit(field string, limit, maxLimit int32) (uint64, error) {
+	if limit < 1 || limit > maxLimit {
+		return 0, fmt.Errorf("%w: limit on field %s needs to be between 1 and %d", ErrGraphQLInvalidArgument, field, maxLimit)
+	}
+	return uint64(limit), s.charge(1 + uint64(limit))
+}
+
+// gqlCursor returns the opaque cursor of a list entry, which is its position in the order of the list
+func gqlCursor(key string, id int64) string {
+	return key + ":" + strconv.FormatInt(id, 10)
+}
+
+// gqlParseCursor returns the parts of the cursor in the after argument of a list field
+func gqlParseCursor(after string) (key string, id int64, err error) {
+	key, idStr, ok := strings.Cut(after, ":")
+	id, err = strconv.ParseInt(idStr, 10, 64)
+	if !ok || err != nil || id <= 0 {
+		return "", 0, fmt.Errorf("%w: invalid cursor", ErrGraphQLInvalidArgument)
+	}
+	return key, id, nil
+}
+
+// gqlParseNumericCursor returns the parts of a cursor whose key is a decimal number
+func gqlParseNumericCursor(after string) (key string, id int64, err error) {
+	key, id, err = gqlParseCursor(after)
+	if _, ok := new(big.Int).SetString(key, 10); err == nil && (!ok || strings.HasPrefix(key, "-")) {
+		return "", 0, fmt.Errorf("%w: invalid cursor", ErrGraphQLInvalidArgument)
+	}
+	return key, id, err
+}
@@ -0,0 +1,627 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// dataGraphQLSchema is the schema of the data API GraphQL endpoint. Its types have the fields of the
+// corresponding REST data API responses.
+var dataGraphQLSchema = graphql.MustParseSchema(dataGraphQLSchemaString, &gqlQuery{}, graphql.MaxDepth(dataAPIGraphQLMaxDepth), graphql.Logger(gqlLogger{}))
+
+const dataGraphQLSchemaString = `
+schema {
+	query: Query
+}
+
+# An unsigned 64-bit integer, which is encoded as a string. Arguments can also be given as integers.
+scalar Uint64
+
+type Query {
+	# Payloads delivered to proposers, like /relay/v1/data/bidtraces/proposer_payload_delivered
+	deliveredPayloads(slot: Uint64, slotFrom: Uint64, slotTo: Uint64, blockHash: String, blockNumber: Uint64, proposerPubkey: String, builderPubkey: String, limit: Int = 100): [DeliveredPayload!]!
+	# Blocks received from builders, like /relay/v1/data/bidtraces/builder_blocks_received
+	builderSubmissions(slot: Uint64, slotFrom: Uint64, slotTo: Uint64, blockHash: String, blockNumber: Uint64, builderPubkey: String, limit: Int = 100): [BuilderSubmission!]!
+	# Builders ordered by pubkey, after the cursor of a previous page
+	builders(builderId: String, isOptimistic: Boolean, after: String, limit: Int = 100): [Builder!]!
+	builder(pubkey: String!): Builder
+	# Builder statistics over a window of slots before the head slot, like /relay/v1/data/builders
+	builderStats(builderId: String, window: Uint64, after: String, limit: Int = 100): [BuilderStats!]!
+	# The latest registrations of validators, like /relay/v1/data/validator_registrations
+	validatorRegistrations(pubkeys: [String!]!): [ValidatorRegistration!]!
+	validatorRegistrationHistory(pubkey: String!, limit: Int = 50): [ValidatorRegistration!]!
+	# Demotions in a slot range, ordered by slot, after the cursor of a previous page
+	demotions(slotFrom: Uint64!, slotTo: Uint64!, builderPubkey: String, after: String, limit: Int = 100): [Demotion!]!
+}
+
+type DeliveredPayload {
+	slot: Uint64!
+	parentHash: String!
+	blockHash: String!
+	builderPubkey: String!
+	proposerPubkey: String!
+	proposerFeeRecipient: String!
+	gasLimit: Uint64!
+	gasUsed: Uint64!
+	value: String!
+	numTx: Uint64!
+	blockNumber: Uint64!
+	builder: Builder
+	# The bids of the delivered auction
+	bids(builderPubkey: String, limit: Int = 100): [BuilderSubmission!]!
+	registration: ValidatorRegistration
+}
+
+type BuilderSubmission {
+	slot: Uint64!
+	parentHash: String!
+	blockHash: String!
+	builderPubkey: String!
+	proposerPubkey: String!
+	proposerFeeRecipient: String!
+	gasLimit: Uint64!
+	gasUsed: Uint64!
+	value: String!
+	numTx: Uint64!
+	blockNumber: Uint64!
+	timestamp: Uint64!
+	timestampMs: Uint64!
+	optimisticSubmission: Boolean!
+	builder: Builder
+}
+
+type Builder {
+	builderPubkey: String!
+	builderId: String!
+	isHighPrio: Boolean!
+	isOptimistic: Boolean!
+	cursor: String!
+	submissions(slot: Uint64, slotFrom: Uint64, slotTo: Uint64, limit: Int = 50): [BuilderSubmission!]!
+	# The demotions in a slot range, by default in the last slot range before the head slot
+	demotions(slotFrom: Uint64, slotTo: Uint64, limit: Int = 50): [Demotion!]!
+}
+
+type BuilderStats {
+	builderPubkey: String!
+	builderId: String!
+	isHighPrio: Boolean!
+	isOptimistic: Boolean!
+	numSubmissionsTotal: Uint64!
+	numSubmissionsSimError: Uint64!
+	numSentGetPayload: Uint64!
+	simErrorRate: Float!
+	windowSlotFrom: Uint64!
+	numSlotsBid: Uint64!
+	numSlotsDelivered: Uint64!
+	winRate: Float!
+	valueDelivered: String!
+	medianTotalDurationUs: Uint64!
+	cursor: String!
+}
+
+type ValidatorRegistration {
+	pubkey: String!
+	feeRecipient: String!
+	gasLimit: Uint64!
+	timestamp: Uint64!
+	signature: String!
+}
+
+type Demotion {
+	id: Uint64!
+	slot: Uint64!
+	epoch: Uint64!
+	builderPubkey: String!
+	proposerPubkey: String!
+	blockHash: String!
+	feeRecipient: String!
+	value: String!
+	simError: String!
+	demotedAt: Uint64!
+	wasDelivered: Boolean!
+	refundOwed: String!
+	cursor: String!
+	builder: Builder
+}
+`
+
+type gqlQuery struct{}
+
+func (q *gqlQuery) DeliveredPayloads(ctx context.Context, args struct {
+	Slot, SlotFrom, SlotTo *gqlUint64
+	BlockHash              *string
+	BlockNumber            *gqlUint64
+	ProposerPubkey         *string
+	BuilderPubkey          *string
+	Limit                  int32
+},
+) ([]*gqlDeliveredPayload, error) {
+	state := gqlState(ctx)
+	if err := gqlSlotRange(args.Slot, args.SlotFrom, args.SlotTo); err != nil {
+		return nil, err
+	} else if err := gqlCheckPubkey("proposerPubkey", args.ProposerPubkey); err != nil {
+		return nil, err
+	} else if err := gqlCheckPubkey("builderPubkey", args.BuilderPubkey); err != nil {
+		return nil, err
+	}
+	limit, err := state.limit("deliveredPayloads", args.Limit, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := state.api.db.GetRecentDeliveredPayloads(database.GetPayloadsFilters{ //nolint:exhaustruct
+		Slot:           args.Slot.uint64(),
+		SlotFrom:       args.SlotFrom.uint64(),
+		SlotTo:         args.SlotTo.uint64(),
+		BlockHash:      gqlString(args.BlockHash),
+		BlockNumber:    args.BlockNumber.uint64(),
+		ProposerPubkey: gqlString(args.ProposerPubkey),
+		BuilderPubkey:  gqlString(args.BuilderPubkey),
+		Limit:          limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the registrations of all proposers are loaded together
+	registrations := &gqlRegistrations{state: state} //nolint:exhaustruct
+	payloads := make([]*gqlDeliveredPayload, len(entries))
+	for i, entry := range entries {
+		registrations.pubkeys = append(registrations.pubkeys, entry.ProposerPubkey)
+		payloads[i] = &gqlDeliveredPayload{
+			gqlBidTrace:   gqlBidTrace{state: state, trace: database.DeliveredPayloadEntryToBidTraceV2JSON(entry)},
+			registrations: registrations,
+		}
+	}
+	return payloads, nil
+}
+
+func (q *gqlQuery) BuilderSubmissions(ctx context.Context, args struct {
+	Slot, SlotFrom, SlotTo *gqlUint64
+	BlockHash              *string
+	BlockNumber            *gqlUint64
+	BuilderPubkey          *string
+	Limit                  int32
+},
+) ([]*gqlBuilderSubmission, error) {
+	state := gqlState(ctx)
+	if err := gqlSlotRange(args.Slot, args.SlotFrom, args.SlotTo); err != nil {
+		return nil, err
+	} else if err := gqlCheckPubkey("builderPubkey", args.BuilderPubkey); err != nil {
+		return nil, err
+	}
+	if args.Slot == nil && args.SlotTo == nil && args.BlockNumber == nil && args.BlockHash == nil && args.BuilderPubkey == nil {
+		return nil, fmt.Errorf("%w: need to query for specific slot or slot range or blockHash or blockNumber or builderPubkey", ErrGraphQLInvalidArgument)
+	}
+	limit, err := state.limit("builderSubmissions", args.Limit, 500)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := state.api.db.GetBuilderSubmissions(database.GetBuilderSubmissionsFilters{ //nolint:exhaustruct
+		Slot:          args.Slot.uint64(),
+		SlotFrom:      args.SlotFrom.uint64(),
+		SlotTo:        args.SlotTo.uint64(),
+		BlockHash:     gqlString(args.BlockHash),
+		BlockNumber:   args.BlockNumber.uint64(),
+		BuilderPubkey: gqlString(args.BuilderPubkey),
+		Limit:         limit,
+	})
+	return gqlBuilderSubmissions(state, entries), err
+}
+
+func (q *gqlQuery) Builders(ctx context.Context, args struct {
+	BuilderID    *string
+	IsOptimistic *bool
+	After        *string
+	Limit        int32
+},
+) ([]*gqlBuilder, error) {
+	state := gqlState(ctx)
+	limit, err := state.limit("builders", args.Limit, 1000)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := database.GetBlockBuildersFilters{BuilderID: gqlString(args.BuilderID), Cursor: gqlString(args.After), Limit: limit} //nolint:exhaustruct
+	if args.IsOptimistic != nil {
+		filters.IsOptimistic = database.NewNullBool(*args.IsOptimistic)
+	}
+	entries, err := state.api.db.GetFilteredBlockBuilders(filters)
+	if err != nil {
+		return nil, err
+	}
+	builders := make([]*gqlBuilder, len(entries))
+	for i, entry := range entries {
+		builders[i] = &gqlBuilder{state: state, entry: entry}
+	}
+	return builders, nil
+}
+
+func (q *gqlQuery) Builder(ctx context.Context, args struct{ Pubkey string }) (*gqlBuilder, error) {
+	state := gqlState(ctx)
+	if err := gqlCheckPubkey("pubkey", &args.Pubkey); err != nil {
+		return nil, err
+	}
+	return gqlBuilderByPubkey(state, args.Pubkey)
+}
+
+func (q *gqlQuery) BuilderStats(ctx context.Context, args struct {
+	BuilderID *string
+	Window    *gqlUint64
+	After     *string
+	Limit     int32
+},
+) ([]*gqlBuilderStats, error) {
+	state := gqlState(ctx)
+	window := uint64(dataAPIMaxSlotRange)
+	if args.Window != nil {
+		window = uint64(*args.Window)
+		if window == 0 || window > dataAPIMaxSlotRange {
+			return nil, fmt.Errorf("%w: window needs to be between 1 and %d", ErrGraphQLInvalidArgument, dataAPIMaxSlotRange)
+		}
+	}
+	filters := database.GetBuilderStatsFilters{BuilderID: gqlString(args.BuilderID)} //nolint:exhaustruct
+	if args.After != nil {
+		var err error
+		if filters.CursorValueDelivered, filters.CursorID, err = gqlParseNumericCursor(*args.After); err != nil {
+			return nil, err
+		}
+	}
+	headSlot := state.api.headSlot.Load()
+	if headSlot == 0 {
+		return nil, fmt.Errorf("%w: head slot not known yet, try again later", ErrGraphQLInvalidArgument)
+	} else if headSlot > window {
+		filters.SlotFrom = headSlot - window
+	}
+	var err error
+	if filters.Limit, err = state.limit("builderStats", args.Limit, 1000); err != nil {
+		return nil, err
+	}
+
+	entries, err := state.api.db.GetBuilderStats(filters)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*gqlBuilderStats, len(entries))
+	for i, entry := range entries {
+		stats[i] = &gqlBuilderStats{stats: database.BuilderStatsEntryToJSON(entry, filters.SlotFrom), id: entry.ID}
+	}
+	return stats, nil
+}
+
+func (q *gqlQuery) ValidatorRegistrations(ctx context.Context, args struct{ Pubkeys []string }) ([]*gqlValidatorRegistration, error) {
+	state := gqlState(ctx)
+	if len(args.Pubkeys) > dataAPIMaxRegPubkeys {
+		return nil, fmt.Errorf("%w: maximum number of pubkeys is %d", ErrGraphQLInvalidArgument, dataAPIMaxRegPubkeys)
+	}
+	for _, pubkey := range args.Pubkeys {
+		if err := checkBLSPublicKeyHex(pubkey); err != nil {
+			return nil, fmt.Errorf("%w: invalid pubkey %s", ErrGraphQLInvalidArgument, pubkey)
+		}
+	}
+	if len(args.Pubkeys) == 0 {
+		return []*gqlValidatorRegistration{}, nil
+	}
+	if err := state.charge(1 + uint64(len(args.Pubkeys))); err != nil {
+		return nil, err
+	}
+
+	entries, err := state.api.db.GetValidatorRegistrationsForPubkeys(args.Pubkeys)
+	return gqlValidatorRegistrations(entries), err
+}
+
+func (q *gqlQuery) ValidatorRegistrationHistory(ctx context.Context, args struct {
+	Pubkey string
+	Limit  int32
+},
+) ([]*gqlValidatorRegistration, error) {
+	state := gqlState(ctx)
+	if err := gqlCheckPubkey("pubkey", &args.Pubkey); err != nil {
+		return nil, err
+	}
+	limit, err := state.limit("validatorRegistrationHistory", args.Limit, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := state.api.db.GetValidatorRegistrationHistory(args.Pubkey, limit)
+	return gqlValidatorRegistrations(entries), err
+}
+
+func (q *gqlQuery) Demotions(ctx context.Context, args struct {
+	SlotFrom, SlotTo gqlUint64
+	BuilderPubkey    *string
+	After            *string
+	Limit            int32
+},
+) ([]*gqlDemotion, error) {
+	state := gqlState(ctx)
+	if err := gqlSlotRange(nil, &args.SlotFrom, &args.SlotTo); err != nil {
+		return nil, err
+	} else if err := gqlCheckPubkey("builderPubkey", args.BuilderPubkey); err != nil {
+		return nil, err
+	}
+	filters := database.GetBuilderDemotionsFilters{SlotFrom: uint64(args.SlotFrom), SlotTo: uint64(args.SlotTo), BuilderPubkey: gqlString(args.BuilderPubkey)} //nolint:exhaustruct
+	if args.After != nil {
+		slot, id, err := gqlParseNumericCursor(*args.After)
+		if err != nil {
+			return nil, err
+		}
+		if filters.CursorSlot, err = strconv.ParseUint(slot, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: invalid cursor", ErrGraphQLInvalidArgument)
+		}
+		filters.CursorID = id
+	}
+	var err error
+	if filters.Limit, err = state.limit("demotions", args.Limit, 500); err != nil {
+		return nil, err
+	}
+
+	entries, err := state.api.db.GetBuilderDemotions(filters)
+	return gqlDemotions(state, entries), err
+}
+
+// gqlBidTrace resolves the fields of a bid trace, like in the REST data API
+type gqlBidTrace struct {
+	state *gqlRequestState
+	trace common.BidTraceV2JSON
+}
+
+func (r *gqlBidTrace) Slot() gqlUint64              { return gqlUint64(r.trace.Slot) }
+func (r *gqlBidTrace) ParentHash() string           { return r.trace.ParentHash }
+func (r *gqlBidTrace) BlockHash() string            { return r.trace.BlockHash }
+func (r *gqlBidTrace) BuilderPubkey() string        { return r.trace.BuilderPubkey }
+func (r *gqlBidTrace) ProposerPubkey() string       { return r.trace.ProposerPubkey }
+func (r *gqlBidTrace) ProposerFeeRecipient() string { return r.trace.ProposerFeeRecipient }
+func (r *gqlBidTrace) GasLimit() gqlUint64          { return gqlUint64(r.trace.GasLimit) }
+func (r *gqlBidTrace) GasUsed() gqlUint64           { return gqlUint64(r.trace.GasUsed) }
+func (r *gqlBidTrace) Value() string                { return r.trace.Value }
+func (r *gqlBidTrace) NumTx() gqlUint64             { return gqlUint64(r.trace.NumTx) }
+func (r *gqlBidTrace) BlockNumber() gqlUint64       { return gqlUint64(r.trace.BlockNumber) }
+func (r *gqlBidTrace) Builder() (*gqlBuilder, error) {
+	return gqlBuilderByPubkey(r.state, r.trace.BuilderPubkey)
+}
+
+type gqlDeliveredPayload struct {
+	gqlBidTrace
+	registrations *gqlRegistrations
+}
+
+func (r *gqlDeliveredPayload) Bids(args struct {
+	BuilderPubkey *string
+	Limit         int32
+},
+) ([]*gqlBuilderSubmission, error) {
+	if err := gqlCheckPubkey("builderPubkey", args.BuilderPubkey); err != nil {
+		return nil, err
+	}
+	limit, err := r.state.limit("bids", args.Limit, 500)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := r.state.api.db.GetBuilderSubmissions(database.GetBuilderSubmissionsFilters{ //nolint:exhaustruct
+		Slot:          r.trace.Slot,
+		BuilderPubkey: gqlString(args.BuilderPubkey),
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// only the bids of the delivered auction
+	bids := []*database.BuilderBlockSubmissionEntry{}
+	for _, entry := range entries {
+		if entry.ParentHash == r.trace.ParentHash && entry.ProposerPubkey == r.trace.ProposerPubkey {
+			bids = append(bids, entry)
+		}
+	}
+	return gqlBuilderSubmissions(r.state, bids), nil
+}
+
+func (r *gqlDeliveredPayload) Registration() (*gqlValidatorRegistration, error) {
+	entry, err := r.registrations.get(r.trace.ProposerPubkey)
+	if entry == nil || err != nil {
+		return nil, err
+	}
+	return &gqlValidatorRegistration{entry: entry}, nil
+}
+
+// gqlRegistrations loads the latest registrations of the proposers of a list of delivered payloads with one query
+type gqlRegistrations struct {
+	state    *gqlRequestState
+	pubkeys  []string
+	once     sync.Once
+	byPubkey map[string]*database.ValidatorRegistrationEntry
+	err      error
+}
+
+func (r *gqlRegistrations) get(pubkey string) (*database.ValidatorRegistrationEntry, error) {
+	r.once.Do(func() {
+		if r.err = r.state.charge(1 + uint64(len(r.pubkeys))); r.err != nil {
+			return
+		}
+		entries, err := r.state.api.db.GetValidatorRegistrationsForPubkeys(r.pubkeys)
+		if err != nil {
+			r.err = err
+			return
+		}
+		r.byPubkey = make(map[string]*database.ValidatorRegistrationEntry, len(entries))
+		for _, entry := range entries {
+			r.byPubkey[entry.Pubkey] = entry
+		}
+	})
+	return r.byPubkey[pubkey], r.err
+}
+
+type gqlBuilderSubmission struct {
+	gqlBidTrace
+	submission common.BidTraceV2WithTimestampJSON
+}
+
+func gqlBuilderSubmissions(state *gqlRequestState, entries []*database.BuilderBlockSubmissionEntry) []*gqlBuilderSubmission {
+	submissions := make([]*gqlBuilderSubmission, len(entries))
+	for i, entry := range entries {
+		submission := database.BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(entry)
+		submissions[i] = &gqlBuilderSubmission{gqlBidTrace: gqlBidTrace{state: state, trace: submission.BidTraceV2JSON}, submission: submission}
+	}
+	return submissions
+}
+
+func (r *gqlBuilderSubmission) Timestamp() gqlUint64       { return gqlUint64(r.submission.Timestamp) }
+func (r *gqlBuilderSubmission) TimestampMs() gqlUint64     { return gqlUint64(r.submission.TimestampMs) }
+func (r *gqlBuilderSubmission) OptimisticSubmission() bool { return r.submission.OptimisticSubmission }
+
+type gqlBuilder struct {
+	state *gqlRequestState
+	entry *database.BlockBuilderEntry
+}
+
+func gqlBuilderByPubkey(state *gqlRequestState, pubkey string) (*gqlBuilder, error) {
+	entry, err := state.builder(pubkey)
+	if entry == nil || err != nil {
+		return nil, err
+	}
+	return &gqlBuilder{state: state, entry: entry}, nil
+}
+
+func (r *gqlBuilder) BuilderPubkey() string { return r.entry.BuilderPubkey }
+func (r *gqlBuilder) BuilderID() string     { return r.entry.BuilderID }
+func (r *gqlBuilder) IsHighPrio() bool      { return r.entry.IsHighPrio }
+func (r *gqlBuilder) IsOptimistic() bool    { return r.entry.IsOptimistic }
+func (r *gqlBuilder) Cursor() string        { return r.entry.BuilderPubkey }
+
+func (r *gqlBuilder) Submissions(args struct {
+	Slot, SlotFrom, SlotTo *gqlUint64
+	Limit                  int32
+},
+) ([]*gqlBuilderSubmission, error) {
+	if err := gqlSlotRange(args.Slot, args.SlotFrom, args.SlotTo); err != nil {
+		return nil, err
+	}
+	limit, err := r.state.limit("submissions", args.Limit, 500)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := r.state.api.db.GetBuilderSubmissions(database.GetBuilderSubmissionsFilters{ //nolint:exhaustruct
+		Slot:          args.Slot.uint64(),
+		SlotFrom:      args.SlotFrom.uint64(),
+		SlotTo:        args.SlotTo.uint64(),
+		BuilderPubkey: r.entry.BuilderPubkey,
+		Limit:         limit,
+	})
+	return gqlBuilderSubmissions(r.state, entries), err
+}
+
+func (r *gqlBuilder) Demotions(args struct {
+	SlotFrom, SlotTo *gqlUint64
+	Limit            int32
+},
+) ([]*gqlDemotion, error) {
+	if err := gqlSlotRange(nil, args.SlotFrom, args.SlotTo); err != nil {
+		return nil, err
+	}
+	if args.Limit < 1 || args.Limit > 200 {
+		return nil, fmt.Errorf("%w: limit on field demotions needs to be between 1 and 200", ErrGraphQLInvalidArgument)
+	}
+	slotFrom, slotTo := args.SlotFrom.uint64(), args.SlotTo.uint64()
+	if args.SlotTo == nil {
+		slotTo = r.state.api.headSlot.Load()
+		if slotTo >= dataAPIMaxSlotRange {
+			slotFrom = slotTo - dataAPIMaxSlotRange + 1
+		}
+	}
+
+	// the demotions of all builders in the range are loaded and charged once
+	entries, err := r.state.builderDemotions(slotFrom, slotTo, r.entry.BuilderPubkey)
+	if uint64(len(entries)) > uint64(args.Limit) {
+		entries = entries[:args.Limit]
+	}
+	return gqlDemotions(r.state, entries), err
+}
+
+type gqlBuilderStats struct {
+	stats common.BuilderStatsJSON
+	id    int64
+}
+
+func (r *gqlBuilderStats) BuilderPubkey() string { return r.stats.BuilderPubkey }
+func (r *gqlBuilderStats) BuilderID() string     { return r.stats.BuilderID }
+func (r *gqlBuilderStats) IsHighPrio() bool      { return r.stats.IsHighPrio }
+func (r *gqlBuilderStats) IsOptimistic() bool    { return r.stats.IsOptimistic }
+func (r *gqlBuilderStats) NumSubmissionsTotal() gqlUint64 {
+	return gqlUint64(r.stats.NumSubmissionsTotal)
+}
+func (r *gqlBuilderStats) NumSubmissionsSimError() gqlUint64 {
+	return gqlUint64(r.stats.NumSubmissionsSimError)
+}
+func (r *gqlBuilderStats) NumSentGetPayload() gqlUint64 { return gqlUint64(r.stats.NumSentGetPayload) }
+func (r *gqlBuilderStats) SimErrorRate() float64        { return r.stats.SimErrorRate }
+func (r *gqlBuilderStats) WindowSlotFrom() gqlUint64    { return gqlUint64(r.stats.WindowSlotFrom) }
+func (r *gqlBuilderStats) NumSlotsBid() gqlUint64       { return gqlUint64(r.stats.NumSlotsBid) }
+func (r *gqlBuilderStats) NumSlotsDelivered() gqlUint64 { return gqlUint64(r.stats.NumSlotsDelivered) }
+func (r *gqlBuilderStats) WinRate() float64             { return r.stats.WinRate }
+func (r *gqlBuilderStats) ValueDelivered() string       { return r.stats.ValueDelivered }
+func (r *gqlBuilderStats) MedianTotalDurationUs() gqlUint64 {
+	return gqlUint64(r.stats.MedianTotalDurationUs)
+}
+func (r *gqlBuilderStats) Cursor() string { return gqlCursor(r.stats.ValueDelivered, r.id) }
+
+type gqlValidatorRegistration struct {
+	entry *database.ValidatorRegistrationEntry
+}
+
+func gqlValidatorRegistrations(entries []*database.ValidatorRegistrationEntry) []*gqlValidatorRegistration {
+	registrations := make([]*gqlValidatorRegistration, len(entries))
+	for i, entry := range entries {
+		registrations[i] = &gqlValidatorRegistration{entry: entry}
+	}
+	return registrations
+}
+
+func (r *gqlValidatorRegistration) Pubkey() string       { return r.entry.Pubkey }
+func (r *gqlValidatorRegistration) FeeRecipient() string { return r.entry.FeeRecipient }
+func (r *gqlValidatorRegistration) GasLimit() gqlUint64  { return gqlUint64(r.entry.GasLimit) }
+func (r *gqlValidatorRegistration) Timestamp() gqlUint64 { return gqlUint64(r.entry.Timestamp) }
+func (r *gqlValidatorRegistration) Signature() string    { return r.entry.Signature }
+
+// gqlDemotion resolves the public fields of a demotion, without the review of the operators
+type gqlDemotion struct {
+	state    *gqlRequestState
+	demotion common.BuilderDemotionRefundJSON
+}
+
+func gqlDemotions(state *gqlRequestState, entries []*database.BuilderDemotionEntry) []*gqlDemotion {
+	demotions := make([]*gqlDemotion, len(entries))
+	for i, entry := range entries {
+		demotions[i] = &gqlDemotion{state: state, demotion: database.BuilderDemotionEntryToRefundJSON(entry)}
+	}
+	return demotions
+}
+
+func (r *gqlDemotion) ID() gqlUint64          { return gqlUint64(r.demotion.ID) }
+func (r *gqlDemotion) Slot() gqlUint64        { return gqlUint64(r.demotion.Slot) }
+func (r *gqlDemotion) Epoch() gqlUint64       { return gqlUint64(r.demotion.Epoch) }
+func (r *gqlDemotion) BuilderPubkey() string  { return r.demotion.BuilderPubkey }
+func (r *gqlDemotion) ProposerPubkey() string { return r.demotion.ProposerPubkey }
+func (r *gqlDemotion) BlockHash() string      { return r.demotion.BlockHash }
+func (r *gqlDemotion) FeeRecipient() string   { return r.demotion.FeeRecipient }
+func (r *gqlDemotion) Value() string          { return r.demotion.Value }
+func (r *gqlDemotion) SimError() string       { return r.demotion.SimError }
+func (r *gqlDemotion) DemotedAt() gqlUint64   { return gqlUint64(r.demotion.DemotedAt) }
+func (r *gqlDemotion) WasDelivered() bool     { return r.demotion.WasDelivered }
+func (r *gqlDemotion) RefundOwed() string     { return r.demotion.RefundOwed }
+func (r *gqlDemotion) Cursor() string {
+	return gqlCursor(strconv.FormatUint(r.demotion.Slot, 10), r.demotion.ID)
+}
+
+func (r *gqlDemotion) Builder() (*gqlBuilder, error) {
+	return gqlBuilderByPubkey(r.state, r.demotion.BuilderPubkey)
+}
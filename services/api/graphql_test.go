@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataApiGraphQL(t *testing.T) {
+	path := "/relay/v1/data/graphql"
+	builderPubkey := "0xb67a5148a03229926e34b190af81a82a81c4df66831c98c03a139778418dd09a3b542ced0022620d19f35781ece6dc36"
+	otherBuilderPubkey := "0xa1885d66bef164889a2e35845c3b626545d7b0e513efe335e97c3a45e534013fa3bc38c3b7e6143695aecc4872ac52c4"
+	proposerPubkey := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+
+	backend := newTestBackend(t, 1)
+	backend.relay.headSlot.Store(100)
+	backend.relay.db = database.MockDB{
+		Builders: map[string]*database.BlockBuilderEntry{
+			builderPubkey:      {ID: 1, BuilderPubkey: builderPubkey, BuilderID: "builder1", IsOptimistic: true, Collateral: "1000"},         //nolint:exhaustruct
+			otherBuilderPubkey: {ID: 2, BuilderPubkey: otherBuilderPubkey, BuilderID: "builder2", Description: "other", IsBlacklisted: true}, //nolint:exhaustruct
+		},
+		DeliveredPayloads: []*database.DeliveredPayloadEntry{
+			{Slot: 90, ParentHash: "0x01", ProposerPubkey: proposerPubkey, BuilderPubkey: builderPubkey, BlockHash: "0xaa", Value: "100"}, //nolint:exhaustruct
+		},
+		BuilderSubmissions: []*database.BuilderBlockSubmissionEntry{
+			{ID: 3, Slot: 90, ParentHash: "0x01", ProposerPubkey: proposerPubkey, BuilderPubkey: builderPubkey, BlockHash: "0xaa", Value: "100"},     //nolint:exhaustruct
+			{ID: 2, Slot: 90, ParentHash: "0x01", ProposerPubkey: proposerPubkey, BuilderPubkey: otherBuilderPubkey, BlockHash: "0xbb", Value: "90"}, //nolint:exhaustruct
+			{ID: 1, Slot: 89, ParentHash: "0x00", ProposerPubkey: proposerPubkey, BuilderPubkey: otherBuilderPubkey, BlockHash: "0xcc", Value: "80"}, //nolint:exhaustruct
+		},
+		Registrations: []*database.ValidatorRegistrationEntry{
+			{Pubkey: proposerPubkey, FeeRecipient: "0xfee", GasLimit: 30000000, Timestamp: 1234}, //nolint:exhaustruct
+		},
+		DemotionEntries: []*database.BuilderDemotionEntry{
+			{ID: 1, Slot: 80, BuilderPubkey: otherBuilderPubkey, Value: "50", ReviewStatus: database.DemotionReviewOpen, ReviewNotes: "internal"}, //nolint:exhaustruct
+			{ID: 2, Slot: 85, BuilderPubkey: builderPubkey, Value: "60"},                                                                          //nolint:exhaustruct
+			{ID: 3, Slot: 86, BuilderPubkey: otherBuilderPubkey, Value: "70"},                                                                     //nolint:exhaustruct
+		},
+	}
+
+	post := func(query string, variables map[string]any) (int, string) {
+		body, err := json.Marshal(gqlRequest{Query: query, Variables: variables}) //nolint:exhaustruct
+		require.NoError(t, err)
+		rr := backend.requestBytes(http.MethodPost, path, body, nil)
+		return rr.Code, rr.Body.String()
+	}
+
+	t.Run("deliveries joined with bids, builders and registrations", func(t *testing.T) {
+		code, body := post(`query ($slot: Uint64!) {
+			deliveredPayloads(slot: $slot) {
+				slot
+				value
+				builder { builderId isOptimistic }
+				bids(limit: 10) { __typename blockHash value builder { builderId } }
+				registration { feeRecipient gasLimit }
+			}
+		}`, map[string]any{"slot": 90})
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"data":{"deliveredPayloads":[{
+			"slot":"90",
+			"value":"100",
+			"builder":{"builderId":"builder1","isOptimistic":true},
+			"bids":[
+				{"__typename":"BuilderSubmission","blockHash":"0xaa","value":"100","builder":{"builderId":"builder1"}},
+				{"__typename":"BuilderSubmission","blockHash":"0xbb","value":"90","builder":{"builderId":"builder2"}}
+			],
+			"registration":{"feeRecipient":"0xfee","gasLimit":"30000000"}
+		}]}}`, body)
+	})
+
+	t.Run("fields keep the order of the query", func(t *testing.T) {
+		code, body := post(`{ b: builders(builderId: "builder2") { builderId, id: builderPubkey } a: builders(limit: 1) { builderPubkey } }`, nil)
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, `{"data":{"b":[{"builderId":"builder2","id":"`+otherBuilderPubkey+`"}],"a":[{"builderPubkey":"`+otherBuilderPubkey+`"}]}}`+"\n", body)
+	})
+
+	t.Run("GET with variables", func(t *testing.T) {
+		args := url.Values{}
+		args.Set("query", `query ($pk: String!) { builder(pubkey: $pk) { builderId submissions { slot } } }`)
+		args.Set("variables", `{"pk":"`+otherBuilderPubkey+`"}`)
+		rr := backend.request(http.MethodGet, path+"?"+args.Encode(), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.JSONEq(t, `{"data":{"builder":{"builderId":"builder2","submissions":[{"slot":"90"},{"slot":"89"}]}}}`, rr.Body.String())
+	})
+
+	t.Run("pages of builders and demotions", func(t *testing.T) {
+		code, body := post(`{ builders(limit: 1, after: "`+otherBuilderPubkey+`") { builderPubkey cursor } }`, nil)
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"data":{"builders":[{"builderPubkey":"`+builderPubkey+`","cursor":"`+builderPubkey+`"}]}}`, body)
+
+		code, body = post(`{ demotions(slotFrom: 80, slotTo: 90, limit: 1, after: "80:1") { id slot cursor } }`, nil)
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"data":{"demotions":[{"id":"2","slot":"85","cursor":"85:2"}]}}`, body)
+
+		code, body = post(`{ builderStats(limit: 1, after: "0:1") { builderId cursor } }`, nil)
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"data":{"builderStats":[{"builderId":"builder2","cursor":"0:2"}]}}`, body)
+	})
+
+	t.Run("demotions of several builders", func(t *testing.T) {
+		code, body := post(`{ builders { builderId demotions(slotFrom: "80", slotTo: "89") { slot value } } }`, nil)
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"data":{"builders":[
+			{"builderId":"builder2","demotions":[{"slot":"80","value":"50"},{"slot":"86","value":"70"}]},
+			{"builderId":"builder1","demotions":[{"slot":"85","value":"60"}]}
+		]}}`, body)
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		testCases := map[string]struct {
+			query     string
+			variables map[string]any
+			code      int
+			error     string
+		}{
+			"syntax":             {query: `{ builders {`, code: http.StatusBadRequest, error: "syntax error"},
+			"unknown field":      {query: `{ builders { foo } }`, code: http.StatusBadRequest, error: `Cannot query field "foo" on type "Builder"`},
+			"internal field":     {query: `{ builders { collateral } }`, code: http.StatusBadRequest, error: `Cannot query field "collateral" on type "Builder"`},
+			"review of demotion": {query: `{ demotions(slotFrom: 80, slotTo: 90) { reviewNotes } }`, code: http.StatusBadRequest, error: `Cannot query field "reviewNotes" on type "Demotion"`},
+			"unknown argument":   {query: `{ builders(foo: 1) { builderId } }`, code: http.StatusBadRequest, error: `Unknown argument "foo"`},
+			"missing subfields":  {query: `{ builders }`, code: http.StatusBadRequest, error: "must have a selection of subfields"},
+			"scalar subfields":   {query: `{ builders { builderId { foo } } }`, code: http.StatusBadRequest, error: "must not have a selection since type"},
+			"wrong type":         {query: `{ deliveredPayloads(slot: "abc") { slot } }`, code: http.StatusBadRequest, error: "invalid Uint64"},
+			"required argument":  {query: `{ builder { builderId } }`, code: http.StatusBadRequest, error: `argument "pubkey" of type "String!" is required`},
+			"required variable":  {query: `query ($slot: Uint64!) { deliveredPayloads(slot: $slot) { slot } }`, code: http.StatusBadRequest, error: `Variable "slot" has invalid value null`},
+			"max limit":          {query: `{ deliveredPayloads(limit: 1000) { slot } }`, code: http.StatusBadRequest, error: "limit on field deliveredPayloads needs to be between 1 and 200"},
+			"depth":              {query: `{ builders { demotions { builder { demotions { builder { builderId } } } } } }`, code: http.StatusBadRequest, error: "exceeds max depth 5"},
+			"slot range":         {query: `{ deliveredPayloads(slotFrom: 1) { slot } }`, code: http.StatusBadRequest, error: "slotFrom and slotTo need to be given together"},
+			"invalid pubkey":     {query: `{ builder(pubkey: "0x123") { builderId } }`, code: http.StatusBadRequest, error: "invalid pubkey"},
+			"invalid cursor":     {query: `{ demotions(slotFrom: 80, slotTo: 90, after: "abc") { id } }`, code: http.StatusBadRequest, error: "invalid cursor"},
+			"out of range":       {query: `{ deliveredPayloads(slot: 99999999999) { slot } }`, code: http.StatusBadRequest, error: "value out of range"},
+			"unfiltered bids":    {query: `{ builderSubmissions { slot } }`, code: http.StatusBadRequest, error: "need to query for specific slot"},
+		}
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				code, body := post(tc.query, tc.variables)
+				require.Equal(t, tc.code, code, body)
+				resp := new(graphql.Response)
+				require.NoError(t, json.Unmarshal([]byte(body), resp))
+				require.Nil(t, resp.Data)
+				require.NotEmpty(t, resp.Errors)
+				require.Contains(t, resp.Errors[0].Message, tc.error)
+			})
+		}
+	})
+
+	t.Run("cost of nested queries", func(t *testing.T) {
+		maxCost := dataAPIGraphQLMaxCost
+		dataAPIGraphQLMaxCost = 600
+		t.Cleanup(func() { dataAPIGraphQLMaxCost = maxCost })
+
+		// the deliveries and builders cost 201 and 1, the bids of the delivery 501
+		code, body := post(`{ deliveredPayloads(limit: 200) { builder { builderId } bids(limit: 500) { slot } } }`, nil)
+		require.Equal(t, http.StatusBadRequest, code, body)
+		require.Contains(t, body, "query cost exceeds the maximum of 600")
+
+		code, body = post(`{ deliveredPayloads(limit: 200) { builder { builderId } bids(limit: 300) { slot } } }`, nil)
+		require.Equal(t, http.StatusOK, code, body)
+	})
+}
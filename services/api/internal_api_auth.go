@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/sirupsen/logrus"
+)
+
+// internal API audit log actions
+const (
+	auditActionBuilderStatus     = "builder_status"
+	auditActionBuilderCollateral = "builder_collateral"
+	auditActionBuilderUpdate     = "builder_update"
+	auditActionDemotionReview    = "demotion_review"
+	auditActionDataAPIKeyIssue   = "data_api_key_issue"
+	auditActionDataAPIKeyRevoke  = "data_api_key_revoke"
+	auditActionCacheRefresh      = "cache_refresh"
+	auditActionRuntimeConfig     = "runtime_config"
+	auditActionBidWithdrawal     = "bid_withdrawal"
+)
+
+var (
+	ErrInvalidInternalAPIToken = errors.New("invalid internal API token, expected name:token")
+
+	// comma separated name:token pairs for the internal API. The name is recorded in the audit log.
+	internalAPITokenEntries = common.GetEnvStrSlice("INTERNAL_API_TOKENS", nil)
+
+	internalAPIAuditMaxLimit = uint64(1000)
+)
+
+type internalAPIActorContextKey struct{}
+
+type builderCollateralAudit struct {
+	BuilderID  string `json:"builder_id"`
+	Collateral string `json:"collateral"`
+}
+
+type demotionReviewAudit struct {
+	Status string `json:"status"`
+	Notes  string `json:"notes"`
+}
+
+// parseInternalAPITokens parses name:token pairs into a map of token to name
+func parseInternalAPITokens(entries []string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(entry, ":")
+		if !ok || name == "" || token == "" {
+			return nil, ErrInvalidInternalAPIToken
+		}
+		if _, exists := tokens[token]; exists {
+			return nil, fmt.Errorf("%w: duplicate token for %s", ErrInvalidInternalAPIToken, name)
+		}
+		tokens[token] = name
+	}
+	return tokens, nil
+}
+
+// getInternalAPIActor returns the name of the token an internal API request was authenticated with
+func getInternalAPIActor(req *http.Request) string {
+	actor, _ := req.Context().Value(internalAPIActorContextKey{}).(string)
+	return actor
+}
+
+// withInternalAPIAuth requires a bearer token for internal API requests, if any tokens are configured
+func (api *RelayAPI) withInternalAPIAuth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if len(api.internalAPITokens) == 0 {
+			handler(w, req)
+			return
+		}
+
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		actor := ""
+		if ok && token != "" {
+			// compare against every token, so the response time doesn't depend on which one matched
+			for t, name := range api.internalAPITokens {
+				if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+					actor = name
+				}
+			}
+		}
+		if actor == "" {
+			api.log.WithFields(logrus.Fields{
+				"method":     req.Method,
+				"path":       req.URL.Path,
+				"remoteIP":   dataAPIClientIP(req),
+				"remoteAddr": req.RemoteAddr,
+			}).Warn("unauthorized internal API request")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			api.RespondError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		handler(w, req.WithContext(context.WithValue(req.Context(), internalAPIActorContextKey{}, actor)))
+	}
+}
+
+// auditInternalAPI records a change made through the internal API. The change was already made,
+// so failing to record it is only logged.
+func (api *RelayAPI) auditInternalAPI(req *http.Request, action, target string, oldValue, newValue any) {
+	entry := &database.InternalAPIAuditEntry{ //nolint:exhaustruct
+		Actor:      getInternalAPIActor(req),
+		RemoteIP:   dataAPIClientIP(req),
+		RemoteAddr: req.RemoteAddr,
+		Method:     req.Method,
+		Path:       req.URL.RequestURI(),
+		Action:     action,
+		Target:     target,
+	}
+	log := api.log.WithFields(logrus.Fields{
+		"actor":  entry.Actor,
+		"action": action,
+		"target": target,
+	})
+
+	oldJSON, err := json.Marshal(oldValue)
+	if err != nil {
+		log.WithError(err).Error("could not encode old value for audit log")
+	}
+	newJSON, err := json.Marshal(newValue)
+	if err != nil {
+		log.WithError(err).Error("could not encode new value for audit log")
+	}
+	entry.OldValue = string(oldJSON)
+	entry.NewValue = string(newJSON)
+
+	if err := api.db.InsertInternalAPIAudit(entry); err != nil {
+		log.WithError(err).Error("could not save internal API audit log entry")
+	}
+}
+
+// handleInternalAudit returns the internal API audit log, newest first
+func (api *RelayAPI) handleInternalAudit(w http.ResponseWriter, req *http.Request) {
+	args := req.URL.Query()
+	filters := database.GetInternalAPIAuditFilters{
+		Actor:  args.Get("actor"),
+		Action: args.Get("action"),
+		Target: args.Get("target"),
+		Limit:  100,
+		Cursor: 0,
+	}
+
+	if args.Get("cursor") != "" {
+		cursor, err := strconv.ParseUint(args.Get("cursor"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid cursor argument")
+			return
+		}
+		filters.Cursor = cursor
+	}
+	if args.Get("limit") != "" {
+		limit, err := strconv.ParseUint(args.Get("limit"), 10, 64)
+		if err != nil || limit == 0 {
+			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
+			return
+		} else if limit > internalAPIAuditMaxLimit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", internalAPIAuditMaxLimit))
+			return
+		}
+		filters.Limit = limit
+	}
+
+	entries, err := api.db.GetInternalAPIAudit(filters)
+	if err != nil {
+		api.log.WithError(err).Error("could not get internal API audit log")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]common.InternalAPIAuditJSON, len(entries))
+	for i, entry := range entries {
+		response[i] = database.InternalAPIAuditEntryToJSON(entry)
+	}
+	api.RespondOK(w, response)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInternalAPITokens(t *testing.T) {
+	tokens, err := parseInternalAPITokens([]string{"alice:secret1", " bob:secret2:with-colon ", ""})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"secret1": "alice", "secret2:with-colon": "bob"}, tokens)
+
+	for _, entries := range [][]string{{"alice"}, {":secret"}, {"alice:"}, {"alice:secret", "bob:secret"}} {
+		_, err = parseInternalAPITokens(entries)
+		require.ErrorIs(t, err, ErrInvalidInternalAPIToken, entries)
+	}
+}
+
+func TestInternalAPIAuth(t *testing.T) {
+	pubkey, _, backend := startTestBackend(t)
+	path := "/internal/v1/builder/" + pubkey.String()
+	mockDB, ok := backend.relay.db.(*database.MockDB)
+	require.True(t, ok)
+	mockDB.InternalAPIAudit = make(map[int64]*database.InternalAPIAuditEntry)
+	require.NoError(t, backend.relay.db.SetBlockBuilderStatus(pubkey.String(), common.BuilderStatus{IsOptimistic: true}))
+
+	// Without tokens the internal API is unauthenticated
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	backend.relay.internalAPITokens = map[string]string{"secret1": "alice", "secret2": "bob"}
+	for _, headers := range []map[string]string{nil, {"Authorization": "Bearer wrong"}, {"Authorization": "secret1"}} {
+		rr = backend.requestBytes(http.MethodPost, path+"?high_prio=true", nil, headers)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+		require.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+	}
+	require.Empty(t, mockDB.InternalAPIAudit)
+
+	// the audit log has the client IP appended by the proxy and the address of the connection
+	prevHops := dataAPITrustedProxyHops
+	dataAPITrustedProxyHops = 1
+	defer func() { dataAPITrustedProxyHops = prevHops }()
+	req := httptest.NewRequest(http.MethodPost, path+"?high_prio=true", nil)
+	req.Header.Set("Authorization", "Bearer secret2")
+	req.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2")
+	rr = httptest.NewRecorder()
+	backend.relay.getRouter().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = backend.requestBytes(http.MethodPost, "/internal/v1/builder/collateral/"+pubkey.String()+"?collateral=builder0x69&value=10000", nil, map[string]string{"Authorization": "Bearer secret1"})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// The changes are recorded in the audit log, newest first
+	rr = backend.requestBytes(http.MethodGet, "/internal/v1/audit", nil, map[string]string{"Authorization": "Bearer secret1"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	entries := []common.InternalAPIAuditJSON{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	require.Len(t, entries, 2)
+
+	require.Equal(t, "alice", entries[0].Actor)
+	require.Equal(t, auditActionBuilderCollateral, entries[0].Action)
+	require.Equal(t, pubkey.String(), entries[0].Target)
+	require.JSONEq(t, `{"builder_id":"builder0x69","collateral":"10000"}`, string(entries[0].NewValue))
+
+	require.Equal(t, "bob", entries[1].Actor)
+	require.Equal(t, auditActionBuilderStatus, entries[1].Action)
+	require.Equal(t, http.MethodPost, entries[1].Method)
+	require.Equal(t, path+"?high_prio=true", entries[1].Path)
+	require.Equal(t, "10.0.0.2", entries[1].RemoteIP)
+	require.Equal(t, "192.0.2.1:1234", entries[1].RemoteAddr)
+	require.JSONEq(t, `{"IsHighPrio":false,"IsBlacklisted":false,"IsOptimistic":true}`, string(entries[1].OldValue))
+	require.JSONEq(t, `{"IsHighPrio":true,"IsBlacklisted":false,"IsOptimistic":true}`, string(entries[1].NewValue))
+
+	// Filters
+	rr = backend.requestBytes(http.MethodGet, "/internal/v1/audit?actor=bob&action=builder_status", nil, map[string]string{"Authorization": "Bearer secret1"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	require.Equal(t, "bob", entries[0].Actor)
+
+	rr = backend.requestBytes(http.MethodGet, "/internal/v1/audit?limit=5000", nil, map[string]string{"Authorization": "Bearer secret1"})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// internalBidWithdrawalRequest removes all bids of a builder, or the bids with a block hash,
+// from the auction of a slot+parent+proposer
+type internalBidWithdrawalRequest struct {
+	Slot           uint64 `json:"slot"`
+	ParentHash     string `json:"parent_hash"`
+	ProposerPubkey string `json:"proposer_pubkey"`
+
+	BuilderPubkey string `json:"builder_pubkey"`
+	BlockHash     string `json:"block_hash"`
+	Reason        string `json:"reason"`
+}
+
+type bidWithdrawalAudit struct {
+	Slot                 uint64   `json:"slot"`
+	ParentHash           string   `json:"parent_hash"`
+	ProposerPubkey       string   `json:"proposer_pubkey"`
+	WithdrawnBuilders    []string `json:"withdrawn_builders"`
+	WithdrawnBlockHashes []string `json:"withdrawn_block_hashes"`
+	WasFloorBidRemoved   bool     `json:"was_floor_bid_removed"`
+	TopBidValue          string   `json:"top_bid_value"`
+	PrevTopBidValue      string   `json:"prev_top_bid_value"`
+	Reason               string   `json:"reason"`
+}
+
+// handleInternalBidWithdrawal pulls a bad bid from a running auction. The top bid is recomputed from the
+// remaining bids, and getPayload is refused for the withdrawn block hashes.
+func (api *RelayAPI) handleInternalBidWithdrawal(w http.ResponseWriter, req *http.Request) {
+	payload := new(internalBidWithdrawalRequest)
+	if err := json.NewDecoder(req.Body).Decode(payload); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	payload.ParentHash = strings.ToLower(payload.ParentHash)
+	payload.ProposerPubkey = strings.ToLower(payload.ProposerPubkey)
+	payload.BuilderPubkey = strings.ToLower(payload.BuilderPubkey)
+	payload.BlockHash = strings.ToLower(payload.BlockHash)
+
+	if payload.Slot <= api.headSlot.Load() {
+		api.RespondError(w, http.StatusBadRequest, "slot needs to be in the future")
+		return
+	} else if checkHashHex(payload.ParentHash) != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid parent_hash")
+		return
+	} else if checkBLSPublicKeyHex(payload.ProposerPubkey) != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid proposer_pubkey")
+		return
+	} else if (payload.BuilderPubkey == "") == (payload.BlockHash == "") {
+		api.RespondError(w, http.StatusBadRequest, "either builder_pubkey or block_hash is required")
+		return
+	} else if payload.BuilderPubkey != "" && checkBLSPublicKeyHex(payload.BuilderPubkey) != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid builder_pubkey")
+		return
+	} else if payload.BlockHash != "" && checkHashHex(payload.BlockHash) != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid block_hash")
+		return
+	}
+
+	log := api.log.WithFields(logrus.Fields{
+		"method":         "handleInternalBidWithdrawal",
+		"actor":          getInternalAPIActor(req),
+		"slot":           payload.Slot,
+		"parentHash":     payload.ParentHash,
+		"proposerPubkey": payload.ProposerPubkey,
+		"builderPubkey":  payload.BuilderPubkey,
+		"blockHash":      payload.BlockHash,
+		"reason":         payload.Reason,
+	})
+	log.Warn("withdrawing bid")
+
+	state, err := api.redis.WithdrawBid(payload.Slot, payload.ParentHash, payload.ProposerPubkey, payload.BuilderPubkey, payload.BlockHash)
+	if errors.Is(err, datastore.ErrNoBidToWithdraw) {
+		api.RespondError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		log.WithError(err).Error("could not withdraw bid")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := bidWithdrawalAudit{
+		Slot:                 payload.Slot,
+		ParentHash:           payload.ParentHash,
+		ProposerPubkey:       payload.ProposerPubkey,
+		WithdrawnBuilders:    state.WithdrawnBuilders,
+		WithdrawnBlockHashes: state.WithdrawnBlockHashes,
+		WasFloorBidRemoved:   state.WasFloorBidRemoved,
+		TopBidValue:          state.TopBidValue.String(),
+		PrevTopBidValue:      state.PrevTopBidValue.String(),
+		Reason:               payload.Reason,
+	}
+	log.WithFields(logrus.Fields{
+		"withdrawnBuilders":    state.WithdrawnBuilders,
+		"withdrawnBlockHashes": state.WithdrawnBlockHashes,
+		"wasFloorBidRemoved":   state.WasFloorBidRemoved,
+		"topBidValue":          result.TopBidValue,
+		"prevTopBidValue":      result.PrevTopBidValue,
+	}).Warn("bid withdrawn")
+
+	target := payload.BuilderPubkey
+	if target == "" {
+		target = payload.BlockHash
+	}
+	api.auditInternalAPI(req, auditActionBidWithdrawal, target, nil, result)
+	api.RespondOK(w, result)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternalBidWithdrawal(t *testing.T) {
+	path := "/internal/v1/bids/withdraw"
+	backend := newTestBackend(t, 1)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{
+			GenesisTime: uint64(time.Now().UTC().Unix()),
+		},
+	}
+	mockDB := &database.MockDB{ //nolint:exhaustruct
+		InternalAPIAudit: map[int64]*database.InternalAPIAuditEntry{},
+	}
+	backend.relay.db = mockDB
+
+	slot := uint64(2)
+	backend.relay.headSlot.Store(slot - 1)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	builderPubkey1 := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	builderPubkey2 := "0xb67a5148a03229926e34b190af81a82a81c4df66831c98c03a139778418dd09a3b542ced0022620d19f35781ece6dc36"
+
+	// Two builders bid
+	opts := common.CreateTestBlockSubmissionOpts{
+		Slot:           slot,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey,
+	}
+	for i, builderPubkey := range []string{builderPubkey1, builderPubkey2} {
+		payload, _, _ := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(int64(100*(i+1))), &opts)
+		payload.Capella.Message.BlockHash = phase0.Hash32{byte(i + 1)}
+		payload.Capella.ExecutionPayload.BlockHash = phase0.Hash32{byte(i + 1)}
+		getHeaderResp, err := common.BuildGetHeaderResponse(payload, &bls.SecretKey{}, &types.PublicKey{}, types.Domain{})
+		require.NoError(t, err)
+		getPayloadResp, err := common.BuildGetPayloadResponse(payload)
+		require.NoError(t, err)
+		_, err = backend.redis.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), true)
+		require.NoError(t, err)
+	}
+	getHeader := func() *common.GetHeaderResponse {
+		rr := backend.request(http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey), nil)
+		if rr.Code == http.StatusNoContent {
+			return nil
+		}
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		resp := new(common.GetHeaderResponse)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		return resp
+	}
+	require.Equal(t, big.NewInt(200), getHeader().Value())
+
+	t.Run("withdraw block hash", func(t *testing.T) {
+		rr := backend.request(http.MethodPost, path, map[string]any{"slot": slot, "parent_hash": parentHash, "proposer_pubkey": proposerPubkey, "block_hash": phase0.Hash32{2}.String(), "reason": "investigation"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		result := new(bidWithdrawalAudit)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), result))
+		require.Equal(t, []string{builderPubkey2}, result.WithdrawnBuilders)
+		require.Equal(t, "100", result.TopBidValue)
+		require.Equal(t, "200", result.PrevTopBidValue)
+
+		require.Equal(t, phase0.Hash32{1}, getHeader().BlockHash())
+		isWithdrawn, err := backend.redis.IsBidWithdrawn(slot, phase0.Hash32{2}.String())
+		require.NoError(t, err)
+		require.True(t, isWithdrawn)
+		require.Len(t, mockDB.InternalAPIAudit, 1)
+	})
+
+	t.Run("withdraw builder", func(t *testing.T) {
+		rr := backend.request(http.MethodPost, path, map[string]any{"slot": slot, "parent_hash": parentHash, "proposer_pubkey": proposerPubkey, "builder_pubkey": builderPubkey1})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Nil(t, getHeader())
+
+		rr = backend.request(http.MethodPost, path, map[string]any{"slot": slot, "parent_hash": parentHash, "proposer_pubkey": proposerPubkey, "builder_pubkey": builderPubkey1})
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		testCases := map[string]map[string]any{
+			"past slot":               {"slot": slot - 1, "parent_hash": parentHash, "proposer_pubkey": proposerPubkey, "builder_pubkey": builderPubkey1},
+			"invalid parent hash":     {"slot": slot, "parent_hash": "0x123", "proposer_pubkey": proposerPubkey, "builder_pubkey": builderPubkey1},
+			"invalid proposer pubkey": {"slot": slot, "parent_hash": parentHash, "proposer_pubkey": "0x123", "builder_pubkey": builderPubkey1},
+			"no bid":                  {"slot": slot, "parent_hash": parentHash, "proposer_pubkey": proposerPubkey},
+			"builder and block hash":  {"slot": slot, "parent_hash": parentHash, "proposer_pubkey": proposerPubkey, "builder_pubkey": builderPubkey1, "block_hash": parentHash},
+			"invalid block hash":      {"slot": slot, "parent_hash": parentHash, "proposer_pubkey": proposerPubkey, "block_hash": "0x123"},
+		}
+		for name, payload := range testCases {
+			t.Run(name, func(t *testing.T) {
+				rr := backend.request(http.MethodPost, path, payload)
+				require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+			})
+		}
+	})
+}
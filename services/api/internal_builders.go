@@ -0,0 +1,231 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+)
+
+// internalBuildersUpdateRequest is a bulk update of builders, either of the listed pubkeys or of all
+// pubkeys with the builder id. Fields that aren't set are left unchanged.
+type internalBuildersUpdateRequest struct {
+	Pubkeys   []string `json:"pubkeys"`
+	BuilderID string   `json:"builder_id"`
+
+	HighPrio          *bool   `json:"high_prio"`
+	Blacklisted       *bool   `json:"blacklisted"`
+	BlacklistDuration string  `json:"blacklist_duration"` // i.e. "6h", the blacklist is permanent if not set
+	Optimistic        *bool   `json:"optimistic"`
+	Description       *string `json:"description"`
+}
+
+type builderUpdateAudit struct {
+	IsHighPrio       bool   `json:"is_high_prio"`
+	IsBlacklisted    bool   `json:"is_blacklisted"`
+	BlacklistedUntil int64  `json:"blacklisted_until,omitempty"`
+	IsOptimistic     bool   `json:"is_optimistic"`
+	Description      string `json:"description"`
+}
+
+func newBuilderUpdateAudit(builder *database.BlockBuilderEntry, now time.Time) builderUpdateAudit {
+	ret := builderUpdateAudit{
+		IsHighPrio:       builder.IsHighPrio,
+		IsBlacklisted:    builder.IsBlacklistedAt(now),
+		BlacklistedUntil: 0,
+		IsOptimistic:     builder.IsOptimistic,
+		Description:      builder.Description,
+	}
+	if ret.IsBlacklisted && builder.BlacklistedUntil.Valid {
+		ret.BlacklistedUntil = builder.BlacklistedUntil.Time.Unix()
+	}
+	return ret
+}
+
+// handleInternalBuilders lists the builders (GET), or updates many of them at once (POST)
+func (api *RelayAPI) handleInternalBuilders(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPost {
+		api.handleInternalBuildersUpdate(w, req)
+		return
+	}
+
+	args := req.URL.Query()
+	filters := make(map[string]bool)
+	for _, arg := range []string{"high_prio", "blacklisted", "optimistic"} {
+		switch args.Get(arg) {
+		case "":
+		case "true", "1":
+			filters[arg] = true
+		case "false", "0":
+			filters[arg] = false
+		default:
+			api.RespondError(w, http.StatusBadRequest, "invalid "+arg+" argument")
+			return
+		}
+	}
+	builderID := args.Get("builder_id")
+
+	builders, err := api.db.GetBlockBuilders()
+	if err != nil {
+		api.log.WithError(err).Error("could not get block builders")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	now := time.Now()
+	response := []*database.BlockBuilderEntry{}
+	for _, builder := range builders {
+		if builderID != "" && builder.BuilderID != builderID {
+			continue
+		}
+		if v, ok := filters["high_prio"]; ok && builder.IsHighPrio != v {
+			continue
+		}
+		if v, ok := filters["blacklisted"]; ok && builder.IsBlacklistedAt(now) != v {
+			continue
+		}
+		if v, ok := filters["optimistic"]; ok && builder.IsOptimistic != v {
+			continue
+		}
+		response = append(response, builder.EffectiveAt(now))
+	}
+	slices.SortFunc(response, func(a, b *database.BlockBuilderEntry) bool {
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.BuilderPubkey < b.BuilderPubkey
+	})
+	api.RespondOK(w, response)
+}
+
+func (api *RelayAPI) handleInternalBuildersUpdate(w http.ResponseWriter, req *http.Request) {
+	payload := new(internalBuildersUpdateRequest)
+	if err := json.NewDecoder(req.Body).Decode(payload); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if (len(payload.Pubkeys) == 0) == (payload.BuilderID == "") {
+		api.RespondError(w, http.StatusBadRequest, "either pubkeys or builder_id is required")
+		return
+	}
+	if payload.HighPrio == nil && payload.Blacklisted == nil && payload.Optimistic == nil && payload.Description == nil {
+		api.RespondError(w, http.StatusBadRequest, "nothing to update")
+		return
+	}
+
+	now := time.Now()
+	update := database.BlockBuilderUpdate{
+		IsHighPrio:       payload.HighPrio,
+		IsBlacklisted:    payload.Blacklisted,
+		BlacklistedUntil: sql.NullTime{},
+		IsOptimistic:     payload.Optimistic,
+		Description:      payload.Description,
+	}
+	if payload.BlacklistDuration != "" {
+		duration, err := time.ParseDuration(payload.BlacklistDuration)
+		if err != nil || duration <= 0 {
+			api.RespondError(w, http.StatusBadRequest, "invalid blacklist_duration")
+			return
+		} else if payload.Blacklisted == nil || !*payload.Blacklisted {
+			api.RespondError(w, http.StatusBadRequest, "blacklist_duration needs blacklisted to be true")
+			return
+		}
+		update.BlacklistedUntil = database.NewNullTime(now.Add(duration))
+	}
+
+	// Find the builders to update
+	builders, err := api.db.GetBlockBuilders()
+	if err != nil {
+		api.log.WithError(err).Error("could not get block builders")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	buildersByPubkey := make(map[string]*database.BlockBuilderEntry, len(builders))
+	for _, builder := range builders {
+		buildersByPubkey[builder.BuilderPubkey] = builder
+	}
+
+	targets := []*database.BlockBuilderEntry{}
+	if payload.BuilderID != "" {
+		for _, builder := range builders {
+			if builder.BuilderID == payload.BuilderID {
+				targets = append(targets, builder)
+			}
+		}
+		if len(targets) == 0 {
+			api.RespondError(w, http.StatusNotFound, "no builders with builder_id "+payload.BuilderID)
+			return
+		}
+	} else {
+		for _, pubkey := range payload.Pubkeys {
+			pubkey = strings.ToLower(pubkey)
+			if err := checkBLSPublicKeyHex(pubkey); err != nil {
+				api.RespondError(w, http.StatusBadRequest, "invalid pubkey "+pubkey)
+				return
+			}
+			builder, ok := buildersByPubkey[pubkey]
+			if !ok {
+				api.RespondError(w, http.StatusNotFound, "builder not found: "+pubkey)
+				return
+			}
+			if !slices.Contains(targets, builder) {
+				targets = append(targets, builder)
+			}
+		}
+	}
+
+	// Re-enabling optimistic mode requires all demotions to be reviewed
+	pubkeys := make([]string, len(targets))
+	oldValues := make([]builderUpdateAudit, len(targets))
+	for i, builder := range targets {
+		if payload.Optimistic != nil && *payload.Optimistic && !builder.IsOptimistic {
+			if err := api.checkBuilderDemotionsResolved(builder.BuilderPubkey); err != nil {
+				api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("%s: %s", builder.BuilderPubkey, err.Error()))
+				return
+			}
+		}
+		pubkeys[i] = builder.BuilderPubkey
+		oldValues[i] = newBuilderUpdateAudit(builder, now)
+	}
+
+	log := api.log.WithFields(logrus.Fields{
+		"numBuilders":       len(pubkeys),
+		"builderID":         payload.BuilderID,
+		"blacklistDuration": payload.BlacklistDuration,
+	})
+	log.Info("updating builders")
+	if err := api.db.UpdateBlockBuilders(pubkeys, update); err != nil {
+		log.WithError(err).Error("could not update builders")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]*database.BlockBuilderEntry, len(pubkeys))
+	for i, pubkey := range pubkeys {
+		builder, err := api.db.GetBlockBuilderByPubkey(pubkey)
+		if err != nil {
+			log.WithError(err).Error("could not get updated builder")
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response[i] = builder.EffectiveAt(now)
+
+		api.publishBuilderStatusUpdate(&datastore.BuilderStatusUpdate{ //nolint:exhaustruct
+			Kind:          datastore.BuilderStatusUpdateStatus,
+			BuilderPubkey: pubkey,
+			IsHighPrio:    builder.IsHighPrio,
+			IsBlacklisted: builder.IsBlacklistedAt(now),
+			IsOptimistic:  builder.IsOptimistic,
+		})
+		api.auditInternalAPI(req, auditActionBuilderUpdate, pubkey, oldValues[i], newBuilderUpdateAudit(builder, now))
+	}
+	api.RespondOK(w, response)
+}
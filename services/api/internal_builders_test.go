@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternalBuilders(t *testing.T) {
+	path := "/internal/v1/builders"
+	pubkey1 := "0xb67a5148a03229926e34b190af81a82a81c4df66831c98c03a139778418dd09a3b542ced0022620d19f35781ece6dc36"
+	pubkey2 := "0xa1885d66bef164889a2e35845c3b626545d7b0e513efe335e97c3a45e534013fa3bc38c3b7e6143695aecc4872ac52c4"
+	pubkey3 := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+
+	backend := newTestBackend(t, 1)
+	mockDB := &database.MockDB{ //nolint:exhaustruct
+		Builders: map[string]*database.BlockBuilderEntry{
+			pubkey1: {ID: 1, BuilderPubkey: pubkey1, BuilderID: "builder1", Collateral: "0"},                   //nolint:exhaustruct
+			pubkey2: {ID: 2, BuilderPubkey: pubkey2, BuilderID: "builder1", Collateral: "0"},                   //nolint:exhaustruct
+			pubkey3: {ID: 3, BuilderPubkey: pubkey3, BuilderID: "builder2", Collateral: "0", IsHighPrio: true}, //nolint:exhaustruct
+		},
+		Refunds:          map[string]bool{},
+		InternalAPIAudit: map[int64]*database.InternalAPIAuditEntry{},
+	}
+	backend.relay.db = mockDB
+
+	list := func(query string) []string {
+		rr := backend.request(http.MethodGet, path+query, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		builders := []*database.BlockBuilderEntry{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &builders))
+		pubkeys := []string{}
+		for _, builder := range builders {
+			pubkeys = append(pubkeys, builder.BuilderPubkey)
+		}
+		return pubkeys
+	}
+
+	t.Run("list with filters", func(t *testing.T) {
+		require.Equal(t, []string{pubkey1, pubkey2, pubkey3}, list(""))
+		require.Equal(t, []string{pubkey1, pubkey2}, list("?builder_id=builder1"))
+		require.Equal(t, []string{pubkey3}, list("?high_prio=true"))
+		require.Equal(t, []string{pubkey1, pubkey2}, list("?builder_id=builder1&high_prio=false&blacklisted=false"))
+
+		rr := backend.request(http.MethodGet, path+"?optimistic=maybe", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("bulk update by builder id", func(t *testing.T) {
+		rr := backend.request(http.MethodPost, path, map[string]any{"builder_id": "builder1", "high_prio": true, "description": "builder one"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.True(t, mockDB.Builders[pubkey1].IsHighPrio)
+		require.True(t, mockDB.Builders[pubkey2].IsHighPrio)
+		require.Equal(t, "builder one", mockDB.Builders[pubkey2].Description)
+		require.Equal(t, "", mockDB.Builders[pubkey3].Description)
+		require.Len(t, mockDB.InternalAPIAudit, 2)
+		require.Equal(t, []string{pubkey1, pubkey2, pubkey3}, list("?high_prio=true"))
+	})
+
+	t.Run("expiring blacklist", func(t *testing.T) {
+		rr := backend.request(http.MethodPost, path, map[string]any{"pubkeys": []string{pubkey2, pubkey3}, "blacklisted": true, "blacklist_duration": "1h"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.True(t, mockDB.Builders[pubkey2].BlacklistedUntil.Valid)
+		require.Equal(t, []string{pubkey2, pubkey3}, list("?blacklisted=true"))
+
+		backend.relay.prepareBuildersForSlot(1)
+		require.True(t, backend.relay.blockBuildersCache[pubkey2].status.IsBlacklisted)
+		require.True(t, backend.relay.blockBuildersCache[pubkey3].status.IsBlacklisted)
+		require.False(t, backend.relay.blockBuildersCache[pubkey1].status.IsBlacklisted)
+
+		// The blacklist of pubkey2 expires, the next refresh lifts it
+		mockDB.Builders[pubkey2].BlacklistedUntil = database.NewNullTime(time.Now().Add(-time.Second))
+		backend.relay.prepareBuildersForSlot(2)
+		require.False(t, backend.relay.blockBuildersCache[pubkey2].status.IsBlacklisted)
+		require.True(t, backend.relay.blockBuildersCache[pubkey3].status.IsBlacklisted)
+		require.Equal(t, []string{pubkey3}, list("?blacklisted=true"))
+
+		// The responses show the effective status of the expired blacklist
+		rr = backend.request(http.MethodGet, path+"?builder_id=builder1", nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		builders := []*database.BlockBuilderEntry{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &builders))
+		require.Equal(t, pubkey2, builders[1].BuilderPubkey)
+		require.False(t, builders[1].IsBlacklisted)
+		require.False(t, builders[1].BlacklistedUntil.Valid)
+
+		rr = backend.request(http.MethodGet, "/internal/v1/builder/"+pubkey2, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		builder := new(database.BlockBuilderEntry)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), builder))
+		require.False(t, builder.IsBlacklisted)
+		require.True(t, mockDB.Builders[pubkey2].IsBlacklisted)
+
+		// Removing the blacklist clears the expiry
+		rr = backend.request(http.MethodPost, path, map[string]any{"pubkeys": []string{pubkey3}, "blacklisted": false})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.False(t, mockDB.Builders[pubkey3].IsBlacklisted)
+		require.False(t, mockDB.Builders[pubkey3].BlacklistedUntil.Valid)
+	})
+
+	t.Run("invalid updates", func(t *testing.T) {
+		testCases := map[string]struct {
+			payload map[string]any
+			code    int
+		}{
+			"no builders":             {payload: map[string]any{"high_prio": true}, code: http.StatusBadRequest},
+			"pubkeys and builder id":  {payload: map[string]any{"pubkeys": []string{pubkey1}, "builder_id": "builder1", "high_prio": true}, code: http.StatusBadRequest},
+			"nothing to update":       {payload: map[string]any{"pubkeys": []string{pubkey1}}, code: http.StatusBadRequest},
+			"duration without list":   {payload: map[string]any{"pubkeys": []string{pubkey1}, "blacklist_duration": "1h"}, code: http.StatusBadRequest},
+			"invalid duration":        {payload: map[string]any{"pubkeys": []string{pubkey1}, "blacklisted": true, "blacklist_duration": "-1h"}, code: http.StatusBadRequest},
+			"invalid pubkey":          {payload: map[string]any{"pubkeys": []string{"0x123"}, "high_prio": true}, code: http.StatusBadRequest},
+			"unknown pubkey":          {payload: map[string]any{"pubkeys": []string{pubkey1[:len(pubkey1)-2] + "00"}, "high_prio": true}, code: http.StatusNotFound},
+			"unknown builder id":      {payload: map[string]any{"builder_id": "builder3", "high_prio": true}, code: http.StatusNotFound},
+			"optimistic with demoted": {payload: map[string]any{"pubkeys": []string{pubkey1}, "optimistic": true}, code: http.StatusBadRequest},
+		}
+		mockDB.Demotions = map[string]bool{pubkey1: true}
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				rr := backend.request(http.MethodPost, path, tc.payload)
+				require.Equal(t, tc.code, rr.Code, rr.Body.String())
+			})
+		}
+		require.False(t, mockDB.Builders[pubkey1].IsOptimistic)
+	})
+}
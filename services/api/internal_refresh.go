@@ -0,0 +1,380 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+)
+
+// caches that can be refreshed through the internal API
+const (
+	refreshCacheProposerDuties    = "proposer_duties"
+	refreshCacheBuilders          = "builders"
+	refreshCacheKnownValidators   = "known_validators"
+	refreshCachePayloadAttributes = "payload_attributes"
+)
+
+var (
+	refreshCaches = []string{refreshCacheProposerDuties, refreshCacheBuilders, refreshCacheKnownValidators, refreshCachePayloadAttributes}
+
+	// how long to wait for the results of a cache refresh from all replicas
+	cacheRefreshTimeout = 10 * time.Second
+)
+
+var (
+	ErrNoBeaconResponse      = errors.New("no response from beacon node")
+	ErrRefreshAlreadyRunning = errors.New("refresh already running, try again")
+	ErrUnknownCache          = errors.New("unknown cache")
+)
+
+// proposerDutiesDiff describes how the proposer duties changed with a refresh
+type proposerDutiesDiff struct {
+	NumDuties    int      `json:"num_duties"`
+	SlotsAdded   []uint64 `json:"slots_added"`
+	SlotsRemoved []uint64 `json:"slots_removed"`
+	SlotsChanged []uint64 `json:"slots_changed"` // different proposer or registration
+}
+
+// buildersCacheDiff describes how the builder cache changed with a refresh
+type buildersCacheDiff struct {
+	NumBuilders int      `json:"num_builders"`
+	Added       []string `json:"added"`
+	Removed     []string `json:"removed"`
+	Changed     []string `json:"changed"` // different status, collateral or builder id
+}
+
+// cacheRefreshResponse reports how a cache refresh went on each replica
+type cacheRefreshResponse struct {
+	Cache       string                          `json:"cache"`
+	NumReplicas int64                           `json:"num_replicas"` // replicas which received the request
+	NumFailed   int                             `json:"num_failed"`   // replicas which failed or didn't respond in time
+	Results     []*datastore.CacheRefreshResult `json:"results"`
+}
+
+// payloadAttributesDiff describes the payload attributes loaded for the next slot
+type payloadAttributesDiff struct {
+	Slot            uint64 `json:"slot,string"`
+	ParentHash      string `json:"parent_hash"`
+	PrevRandao      string `json:"prev_randao"`
+	WithdrawalsRoot string `json:"withdrawals_root"`
+	IsNew           bool   `json:"is_new"`
+	IsChanged       bool   `json:"is_changed"` // different prev_randao or withdrawals
+}
+
+func diffProposerDuties(prev, next map[uint64]*common.BuilderGetValidatorsResponseEntry) *proposerDutiesDiff {
+	diff := &proposerDutiesDiff{
+		NumDuties:    len(next),
+		SlotsAdded:   []uint64{},
+		SlotsRemoved: []uint64{},
+		SlotsChanged: []uint64{},
+	}
+	for slot, duty := range next {
+		prevDuty, ok := prev[slot]
+		if !ok {
+			diff.SlotsAdded = append(diff.SlotsAdded, slot)
+		} else if !isSameProposerDuty(prevDuty, duty) {
+			diff.SlotsChanged = append(diff.SlotsChanged, slot)
+		}
+	}
+	for slot := range prev {
+		if _, ok := next[slot]; !ok {
+			diff.SlotsRemoved = append(diff.SlotsRemoved, slot)
+		}
+	}
+	for _, slots := range [][]uint64{diff.SlotsAdded, diff.SlotsRemoved, diff.SlotsChanged} {
+		sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+	}
+	return diff
+}
+
+func isSameProposerDuty(a, b *common.BuilderGetValidatorsResponseEntry) bool {
+	if a.ValidatorIndex != b.ValidatorIndex {
+		return false
+	}
+	if a.Entry == nil || b.Entry == nil {
+		return a.Entry == b.Entry
+	}
+	return *a.Entry.Message == *b.Entry.Message
+}
+
+func diffBuildersCache(prev, next map[string]*blockBuilderCacheEntry) *buildersCacheDiff {
+	diff := &buildersCacheDiff{
+		NumBuilders: len(next),
+		Added:       []string{},
+		Removed:     []string{},
+		Changed:     []string{},
+	}
+	for pubkey, entry := range next {
+		prevEntry, ok := prev[pubkey]
+		if !ok {
+			diff.Added = append(diff.Added, pubkey)
+		} else if prevEntry.status != entry.status || prevEntry.builderID != entry.builderID || prevEntry.collateral.Cmp(entry.collateral) != 0 {
+			diff.Changed = append(diff.Changed, pubkey)
+		}
+	}
+	for pubkey := range prev {
+		if _, ok := next[pubkey]; !ok {
+			diff.Removed = append(diff.Removed, pubkey)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// loadPayloadAttributes builds the payload attributes for the slot after the beacon node head,
+// the same way they would be received through the payload_attributes event
+func (api *RelayAPI) loadPayloadAttributes() (*payloadAttributesDiff, error) {
+	block, err := api.beaconClient.GetBlock("head")
+	if err != nil {
+		return nil, err
+	} else if block == nil {
+		return nil, ErrNoBeaconResponse
+	}
+	headSlot := block.Data.Message.Slot
+	slot := headSlot + 1
+	parentHash := block.Data.Message.Body.ExecutionPayload.BlockHash.String()
+
+	randao, err := api.beaconClient.GetRandao(headSlot)
+	if err != nil {
+		return nil, err
+	} else if randao == nil {
+		return nil, ErrNoBeaconResponse
+	}
+
+	attrs := beaconclient.PayloadAttributes{
+		Timestamp:             api.genesisInfo.Data.GenesisTime + slot*common.SecondsPerSlot,
+		PrevRandao:            randao.Data.Randao,
+		SuggestedFeeRecipient: "",
+		Withdrawals:           nil,
+	}
+	api.proposerDutiesLock.RLock()
+	if duty, ok := api.proposerDutiesMap[slot]; ok && duty.Entry != nil {
+		attrs.SuggestedFeeRecipient = duty.Entry.Message.FeeRecipient.String()
+	}
+	api.proposerDutiesLock.RUnlock()
+
+	var withdrawalsRoot phase0.Root
+	if api.isCapella(slot) {
+		withdrawals, err := api.beaconClient.GetWithdrawals(headSlot)
+		if err != nil {
+			return nil, err
+		} else if withdrawals == nil {
+			return nil, ErrNoBeaconResponse
+		}
+		attrs.Withdrawals = withdrawals.Data.Withdrawals
+		withdrawalsRoot, err = ComputeWithdrawalsRoot(attrs.Withdrawals)
+		if err != nil {
+			return nil, fmt.Errorf("error computing withdrawals root: %w", err)
+		}
+	}
+
+	api.payloadAttributesLock.Lock()
+	prev, ok := api.payloadAttributes[parentHash]
+	if ok && attrs.SuggestedFeeRecipient == "" {
+		attrs.SuggestedFeeRecipient = prev.payloadAttributes.SuggestedFeeRecipient
+	}
+	api.payloadAttributes[parentHash] = payloadAttributesHelper{
+		slot:              slot,
+		parentHash:        parentHash,
+		withdrawalsRoot:   withdrawalsRoot,
+		payloadAttributes: attrs,
+	}
+	api.payloadAttributesLock.Unlock()
+
+	api.log.WithFields(logrus.Fields{
+		"payloadAttrSlot":   slot,
+		"payloadAttrParent": parentHash,
+		"randao":            attrs.PrevRandao,
+		"withdrawalsRoot":   withdrawalsRoot.String(),
+	}).Info("refreshed payload attributes")
+
+	return &payloadAttributesDiff{
+		Slot:            slot,
+		ParentHash:      parentHash,
+		PrevRandao:      attrs.PrevRandao,
+		WithdrawalsRoot: withdrawalsRoot.String(),
+		IsNew:           !ok,
+		IsChanged:       ok && (prev.slot != slot || prev.payloadAttributes.PrevRandao != attrs.PrevRandao || prev.withdrawalsRoot != withdrawalsRoot),
+	}, nil
+}
+
+// getReplicaName returns the name of this replica in the cache refresh results
+func getReplicaName() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s/%d", hostname, os.Getpid())
+}
+
+// refreshCache reloads one of the in-memory caches immediately, and returns how it changed
+func (api *RelayAPI) refreshCache(cache string) (diff any, err error) {
+	switch cache {
+	case refreshCacheProposerDuties:
+		// Don't run at the same time as the regular update
+		if api.isUpdatingProposerDuties.Swap(true) {
+			return nil, ErrRefreshAlreadyRunning
+		}
+		defer api.isUpdatingProposerDuties.Store(false)
+		return api.loadProposerDuties(api.headSlot.Load())
+	case refreshCacheBuilders:
+		return api.loadBlockBuilders()
+	case refreshCacheKnownValidators:
+		return api.datastore.RefreshKnownValidatorsDiff()
+	case refreshCachePayloadAttributes:
+		return api.loadPayloadAttributes()
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCache, cache)
+	}
+}
+
+// startCacheRefreshSubscription refreshes the caches on the requests made through any replica (including
+// this one), and publishes the results. It resubscribes if the subscription ends.
+func (api *RelayAPI) startCacheRefreshSubscription(ctx context.Context) {
+	for {
+		pubsub := api.redis.SubscribeCacheRefreshRequests(ctx)
+		for msg := range pubsub.Channel() {
+			refreshReq := new(datastore.CacheRefreshRequest)
+			if err := json.Unmarshal([]byte(msg.Payload), refreshReq); err != nil {
+				api.log.WithError(err).Error("failed to decode cache refresh request")
+				continue
+			}
+			api.processCacheRefreshRequest(refreshReq)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		api.log.Warn("cache refresh subscription ended, resubscribing")
+		time.Sleep(time.Second)
+	}
+}
+
+func (api *RelayAPI) processCacheRefreshRequest(refreshReq *datastore.CacheRefreshRequest) {
+	log := api.log.WithFields(logrus.Fields{
+		"cache":     refreshReq.Cache,
+		"refreshID": refreshReq.ID,
+	})
+	result := &datastore.CacheRefreshResult{ID: refreshReq.ID, Replica: api.replicaName} //nolint:exhaustruct
+	diff, err := api.refreshCache(refreshReq.Cache)
+	if err == nil {
+		result.Diff, err = json.Marshal(diff)
+	}
+	if err != nil {
+		log.WithError(err).Error("could not refresh cache")
+		result.Error = err.Error()
+	} else {
+		log.Info("cache refreshed")
+	}
+	if err := api.redis.PublishCacheRefreshResult(result); err != nil {
+		log.WithError(err).Error("failed to publish cache refresh result")
+	}
+}
+
+// handleInternalRefresh reloads one of the in-memory caches immediately on all replicas, and returns how
+// it changed on each of them. Replicas which don't respond in time count as failed.
+func (api *RelayAPI) handleInternalRefresh(w http.ResponseWriter, req *http.Request) {
+	cache := mux.Vars(req)["cache"]
+	log := api.log.WithFields(logrus.Fields{
+		"method": "handleInternalRefresh",
+		"cache":  cache,
+		"actor":  getInternalAPIActor(req),
+	})
+	if !slices.Contains(refreshCaches, cache) {
+		api.RespondError(w, http.StatusNotFound, "unknown cache "+cache)
+		return
+	}
+
+	id, err := generateCacheRefreshID()
+	if err != nil {
+		log.WithError(err).Error("could not generate refresh id")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log = log.WithField("refreshID", id)
+
+	// Subscribe to the results before sending the request, so none are missed
+	ctx, cancel := context.WithTimeout(req.Context(), cacheRefreshTimeout)
+	defer cancel()
+	results := api.redis.SubscribeCacheRefreshResults(ctx)
+	if _, err := results.Receive(ctx); err != nil {
+		log.WithError(err).Error("could not subscribe to cache refresh results")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	numReplicas, err := api.redis.PublishCacheRefreshRequest(&datastore.CacheRefreshRequest{ID: id, Cache: cache})
+	if err != nil {
+		log.WithError(err).Error("could not publish cache refresh request")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := &cacheRefreshResponse{
+		Cache:       cache,
+		NumReplicas: numReplicas,
+		NumFailed:   0,
+		Results:     []*datastore.CacheRefreshResult{},
+	}
+	resultsC := results.Channel()
+collect:
+	for int64(len(resp.Results)) < numReplicas {
+		select {
+		case msg, ok := <-resultsC:
+			if !ok {
+				break collect
+			}
+			result := new(datastore.CacheRefreshResult)
+			if err := json.Unmarshal([]byte(msg.Payload), result); err != nil {
+				log.WithError(err).Error("failed to decode cache refresh result")
+				continue
+			} else if result.ID != id {
+				continue
+			}
+			if result.Error != "" {
+				resp.NumFailed++
+			}
+			resp.Results = append(resp.Results, result)
+		case <-ctx.Done():
+			break collect
+		}
+	}
+	resp.NumFailed += int(numReplicas) - len(resp.Results)
+	sort.Slice(resp.Results, func(i, j int) bool { return resp.Results[i].Replica < resp.Results[j].Replica })
+
+	log.WithFields(logrus.Fields{
+		"numReplicas": resp.NumReplicas,
+		"numFailed":   resp.NumFailed,
+	}).Info("cache refreshed on all replicas")
+	api.auditInternalAPI(req, auditActionCacheRefresh, cache, nil, resp)
+	if numReplicas == 0 || resp.NumFailed > 0 {
+		api.Respond(w, http.StatusInternalServerError, resp)
+		return
+	}
+	api.RespondOK(w, resp)
+}
+
+// generateCacheRefreshID returns a random ID, to match the results of a cache refresh to its request
+func generateCacheRefreshID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternalRefresh(t *testing.T) {
+	pubkey1 := "0xb67a5148a03229926e34b190af81a82a81c4df66831c98c03a139778418dd09a3b542ced0022620d19f35781ece6dc36"
+	pubkey2 := "0xa1885d66bef164889a2e35845c3b626545d7b0e513efe335e97c3a45e534013fa3bc38c3b7e6143695aecc4872ac52c4"
+	pubkey3 := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+
+	backend := newTestBackend(t, 1)
+	backend.relay.headSlot.Store(100)
+	mockDB := &database.MockDB{ //nolint:exhaustruct
+		Builders: map[string]*database.BlockBuilderEntry{
+			pubkey1: {BuilderPubkey: pubkey1, BuilderID: "builder1", Collateral: "0"}, //nolint:exhaustruct
+			pubkey2: {BuilderPubkey: pubkey2, BuilderID: "builder2", Collateral: "0"}, //nolint:exhaustruct
+		},
+		InternalAPIAudit: map[int64]*database.InternalAPIAuditEntry{},
+	}
+	backend.relay.db = mockDB
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go backend.relay.startCacheRefreshSubscription(ctx)
+
+	// waitForReplicas waits until the given number of replicas are subscribed to the refresh requests
+	waitForReplicas := func(numReplicas int64) {
+		require.Eventually(t, func() bool {
+			n, err := backend.redis.PublishCacheRefreshRequest(&datastore.CacheRefreshRequest{ID: "", Cache: ""})
+			require.NoError(t, err)
+			return n == numReplicas
+		}, time.Second, 10*time.Millisecond)
+	}
+	waitForReplicas(1)
+
+	refreshAll := func(cache string, code int) *cacheRefreshResponse {
+		rr := backend.request(http.MethodPost, "/internal/v1/refresh/"+cache, nil)
+		require.Equal(t, code, rr.Code, rr.Body.String())
+		resp := new(cacheRefreshResponse)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, cache, resp.Cache)
+		return resp
+	}
+	refresh := func(cache string, diff any) {
+		resp := refreshAll(cache, http.StatusOK)
+		require.Equal(t, int64(1), resp.NumReplicas)
+		require.Len(t, resp.Results, 1)
+		require.Equal(t, backend.relay.replicaName, resp.Results[0].Replica)
+		require.NoError(t, json.Unmarshal(resp.Results[0].Diff, diff))
+	}
+
+	t.Run("proposer duties", func(t *testing.T) {
+		duty := func(slot, index uint64, feeRecipient string) common.BuilderGetValidatorsResponseEntry {
+			address, err := types.HexToAddress(feeRecipient)
+			require.NoError(t, err)
+			return common.BuilderGetValidatorsResponseEntry{
+				Slot:           slot,
+				ValidatorIndex: index,
+				Entry: &types.SignedValidatorRegistration{ //nolint:exhaustruct
+					Message: &types.RegisterValidatorRequestMessage{FeeRecipient: address}, //nolint:exhaustruct
+				},
+			}
+		}
+		require.NoError(t, backend.redis.SetProposerDuties([]common.BuilderGetValidatorsResponseEntry{
+			duty(101, 1, "0x5cc0dde14e7256340cc820415a6022a7d1c93a35"),
+			duty(102, 2, "0x5cc0dde14e7256340cc820415a6022a7d1c93a35"),
+		}))
+		diff := new(proposerDutiesDiff)
+		refresh(refreshCacheProposerDuties, diff)
+		require.Equal(t, proposerDutiesDiff{NumDuties: 2, SlotsAdded: []uint64{101, 102}, SlotsRemoved: []uint64{}, SlotsChanged: []uint64{}}, *diff)
+
+		require.NoError(t, backend.redis.SetProposerDuties([]common.BuilderGetValidatorsResponseEntry{
+			duty(102, 2, "0x0000000000000000000000000000000000000001"),
+			duty(103, 3, "0x5cc0dde14e7256340cc820415a6022a7d1c93a35"),
+		}))
+		refresh(refreshCacheProposerDuties, diff)
+		require.Equal(t, proposerDutiesDiff{NumDuties: 2, SlotsAdded: []uint64{103}, SlotsRemoved: []uint64{101}, SlotsChanged: []uint64{102}}, *diff)
+		require.Contains(t, backend.relay.proposerDutiesMap, uint64(103))
+
+		// A refresh doesn't run at the same time as the regular update
+		backend.relay.isUpdatingProposerDuties.Store(true)
+		resp := refreshAll(refreshCacheProposerDuties, http.StatusInternalServerError)
+		require.Equal(t, 1, resp.NumFailed)
+		require.Equal(t, ErrRefreshAlreadyRunning.Error(), resp.Results[0].Error)
+		backend.relay.isUpdatingProposerDuties.Store(false)
+	})
+
+	t.Run("builders", func(t *testing.T) {
+		diff := new(buildersCacheDiff)
+		refresh(refreshCacheBuilders, diff)
+		require.Equal(t, buildersCacheDiff{NumBuilders: 2, Added: []string{pubkey2, pubkey1}, Removed: []string{}, Changed: []string{}}, *diff)
+
+		// A manual fix in the database
+		delete(mockDB.Builders, pubkey2)
+		mockDB.Builders[pubkey1].IsHighPrio = true
+		mockDB.Builders[pubkey3] = &database.BlockBuilderEntry{BuilderPubkey: pubkey3, Collateral: "0"} //nolint:exhaustruct
+		refresh(refreshCacheBuilders, diff)
+		require.Equal(t, buildersCacheDiff{NumBuilders: 2, Added: []string{pubkey3}, Removed: []string{pubkey2}, Changed: []string{pubkey1}}, *diff)
+		require.True(t, backend.relay.blockBuildersCache[pubkey1].status.IsHighPrio)
+	})
+
+	t.Run("known validators", func(t *testing.T) {
+		require.NoError(t, backend.redis.SetKnownValidator(types.PubkeyHex(pubkey3), 3))
+		diff := new(datastore.KnownValidatorsDiff)
+		refresh(refreshCacheKnownValidators, diff)
+		require.Equal(t, datastore.KnownValidatorsDiff{NumValidators: 1, NumAdded: 1, NumRemoved: 0, NumChanged: 0}, *diff)
+		require.True(t, backend.datastore.IsKnownValidator(types.PubkeyHex(pubkey3)))
+	})
+
+	t.Run("payload attributes", func(t *testing.T) {
+		beaconInstance := beaconclient.NewMockBeaconInstance()
+		backend.relay.beaconClient = beaconclient.NewMultiBeaconClient(common.TestLog, []beaconclient.IBeaconInstance{beaconInstance})
+
+		// The beacon node doesn't respond
+		resp := refreshAll(refreshCachePayloadAttributes, http.StatusInternalServerError)
+		require.Equal(t, 1, resp.NumFailed)
+
+		beaconInstance.MockBlock = new(beaconclient.GetBlockResponse)
+		beaconInstance.MockBlock.Data.Message.Slot = 102
+		beaconInstance.MockBlock.Data.Message.Body.ExecutionPayload.BlockHash = types.Hash{0x09}
+		beaconInstance.MockRandao = new(beaconclient.GetRandaoResponse)
+		beaconInstance.MockRandao.Data.Randao = "0x01"
+
+		diff := new(payloadAttributesDiff)
+		refresh(refreshCachePayloadAttributes, diff)
+		require.Equal(t, uint64(103), diff.Slot)
+		require.Equal(t, types.Hash{0x09}.String(), diff.ParentHash)
+		require.True(t, diff.IsNew)
+
+		attrs := backend.relay.payloadAttributes[diff.ParentHash]
+		require.Equal(t, uint64(103), attrs.slot)
+		require.Equal(t, "0x01", attrs.payloadAttributes.PrevRandao)
+		require.Equal(t, backend.relay.genesisInfo.Data.GenesisTime+103*common.SecondsPerSlot, attrs.payloadAttributes.Timestamp)
+		require.Equal(t, "0x5cc0dde14e7256340cc820415a6022a7d1c93a35", attrs.payloadAttributes.SuggestedFeeRecipient)
+
+		beaconInstance.MockRandao.Data.Randao = "0x02"
+		refresh(refreshCachePayloadAttributes, diff)
+		require.False(t, diff.IsNew)
+		require.True(t, diff.IsChanged)
+		require.Equal(t, "0x02", backend.relay.payloadAttributes[diff.ParentHash].payloadAttributes.PrevRandao)
+	})
+
+	t.Run("builders on all replicas", func(t *testing.T) {
+		replicaCtx, replicaCancel := context.WithCancel(ctx)
+		defer func() {
+			replicaCancel()
+			waitForReplicas(1)
+		}()
+
+		// A second replica sharing the same redis, whose builder cache is out of date
+		replica := &RelayAPI{ //nolint:exhaustruct
+			log:                backend.relay.log,
+			redis:              backend.relay.redis,
+			db:                 mockDB,
+			replicaName:        "replica2",
+			blockBuildersCache: map[string]*blockBuilderCacheEntry{},
+		}
+		go replica.startCacheRefreshSubscription(replicaCtx)
+		waitForReplicas(2)
+
+		resp := refreshAll(refreshCacheBuilders, http.StatusOK)
+		require.Equal(t, int64(2), resp.NumReplicas)
+		require.Equal(t, 0, resp.NumFailed)
+		require.Len(t, resp.Results, 2)
+		for _, result := range resp.Results {
+			diff := new(buildersCacheDiff)
+			require.NoError(t, json.Unmarshal(result.Diff, diff))
+			if result.Replica == "replica2" {
+				require.Equal(t, []string{pubkey3, pubkey1}, diff.Added)
+			} else {
+				require.Equal(t, backend.relay.replicaName, result.Replica)
+				require.Empty(t, diff.Added)
+			}
+		}
+		require.True(t, replica.blockBuildersCache[pubkey1].status.IsHighPrio)
+
+		// A replica which doesn't respond in time
+		timeout := cacheRefreshTimeout
+		cacheRefreshTimeout = 100 * time.Millisecond
+		t.Cleanup(func() { cacheRefreshTimeout = timeout })
+		backend.redis.SubscribeCacheRefreshRequests(replicaCtx)
+		waitForReplicas(3)
+
+		resp = refreshAll(refreshCacheBuilders, http.StatusInternalServerError)
+		require.Equal(t, int64(3), resp.NumReplicas)
+		require.Equal(t, 1, resp.NumFailed)
+		require.Len(t, resp.Results, 2)
+	})
+
+	t.Run("unknown cache", func(t *testing.T) {
+		rr := backend.request(http.MethodPost, "/internal/v1/refresh/foo", nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	// Every refresh is recorded in the audit log, also the ones which failed on some replicas
+	require.Len(t, mockDB.InternalAPIAudit, 11)
+}
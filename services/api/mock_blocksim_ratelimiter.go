@@ -7,11 +7,12 @@ import (
 )
 
 type MockBlockSimulationRateLimiter struct {
+	requestError    error
 	simulationError error
 }
 
 func (m *MockBlockSimulationRateLimiter) Send(context context.Context, payload *common.BuilderBlockValidationRequest, isHighPrio, fastTrack bool) (error, error) {
-	return nil, m.simulationError
+	return m.requestError, m.simulationError
 }
 
 func (m *MockBlockSimulationRateLimiter) CurrentCounter() int64 {
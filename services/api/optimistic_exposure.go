@@ -0,0 +1,125 @@
+package api
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var gweiPerWei = big.NewInt(1e9)
+
+// optimisticExposureLedger tracks the value of optimistic bids which are not
+// verified yet, per slot and builder. It's kept in Redis to be shared by all
+// replicas, and in memory to release the own exposure and as a fallback if
+// Redis is unavailable.
+type optimisticExposureLedger struct {
+	mu       sync.Mutex
+	exposure map[uint64]map[string]*big.Int // slot -> builder -> unverified value
+}
+
+func newOptimisticExposureLedger() *optimisticExposureLedger {
+	return &optimisticExposureLedger{ //nolint:exhaustruct
+		exposure: make(map[uint64]map[string]*big.Int),
+	}
+}
+
+// Get returns the unverified value of a builder in a slot known to this replica
+func (l *optimisticExposureLedger) Get(slot uint64, builder string) *big.Int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if v, ok := l.exposure[slot][builder]; ok {
+		return new(big.Int).Set(v)
+	}
+	return big.NewInt(0)
+}
+
+// Add adds value (which can be negative) to the unverified value of a builder in a slot
+func (l *optimisticExposureLedger) Add(slot uint64, builder string, value *big.Int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.exposure[slot] == nil {
+		l.exposure[slot] = make(map[string]*big.Int)
+	}
+	if l.exposure[slot][builder] == nil {
+		l.exposure[slot][builder] = big.NewInt(0)
+	}
+	l.exposure[slot][builder].Add(l.exposure[slot][builder], value)
+	if l.exposure[slot][builder].Sign() <= 0 {
+		delete(l.exposure[slot], builder)
+	}
+}
+
+// Prune removes all slots before the given one
+func (l *optimisticExposureLedger) Prune(beforeSlot uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for slot := range l.exposure {
+		if slot < beforeSlot {
+			delete(l.exposure, slot)
+		}
+	}
+}
+
+// exposureBuilderKey returns the key the exposure is tracked by. Collateral belongs to the
+// builder id, shared by all its pubkeys.
+func exposureBuilderKey(builderPubkey string, builder *blockBuilderCacheEntry) string {
+	if builder.builderID != "" {
+		return builder.builderID
+	}
+	return builderPubkey
+}
+
+// valueToGwei rounds up, so the exposure is never underestimated
+func valueToGwei(value *big.Int) int64 {
+	gwei, rem := new(big.Int).QuoRem(value, gweiPerWei, new(big.Int))
+	if rem.Sign() > 0 {
+		gwei.Add(gwei, big.NewInt(1))
+	}
+	return gwei.Int64()
+}
+
+// reserveOptimisticExposure adds the value of an optimistic bid to the builder's exposure in
+// the slot, if the total stays within the collateral. Otherwise the block must be simulated
+// synchronously, and false is returned.
+func (api *RelayAPI) reserveOptimisticExposure(log *logrus.Entry, slot uint64, builderPubkey string, builder *blockBuilderCacheEntry, value *big.Int) bool {
+	key := exposureBuilderKey(builderPubkey, builder)
+	valueGwei := valueToGwei(value)
+
+	// Redis tracks gwei, so compare with the collateral in gwei too (both rounded up)
+	var exceedsCollateral bool
+	totalGwei, err := api.redis.IncrOptimisticExposure(slot, key, valueGwei)
+	if err != nil {
+		log.WithError(err).Error("failed to update optimistic exposure in redis, using local exposure")
+		exposure := new(big.Int).Add(api.optimisticExposure.Get(slot, key), value)
+		exceedsCollateral = exposure.Cmp(builder.collateral) > 0
+	} else {
+		exceedsCollateral = totalGwei > valueToGwei(builder.collateral)
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"optimisticExposureGwei": totalGwei,
+		"collateral":             builder.collateral.String(),
+	})
+	if exceedsCollateral {
+		if err == nil {
+			if _, err := api.redis.IncrOptimisticExposure(slot, key, -valueGwei); err != nil {
+				log.WithError(err).Error("failed to revert optimistic exposure in redis")
+			}
+		}
+		log.Info("optimistic exposure would exceed collateral, simulating synchronously")
+		return false
+	}
+
+	api.optimisticExposure.Add(slot, key, value)
+	return true
+}
+
+// releaseOptimisticExposure removes the value of a successfully simulated optimistic bid from the exposure
+func (api *RelayAPI) releaseOptimisticExposure(log *logrus.Entry, slot uint64, builderPubkey string, builder *blockBuilderCacheEntry, value *big.Int) {
+	key := exposureBuilderKey(builderPubkey, builder)
+	api.optimisticExposure.Add(slot, key, new(big.Int).Neg(value))
+	if _, err := api.redis.IncrOptimisticExposure(slot, key, -valueToGwei(value)); err != nil {
+		log.WithError(err).Error("failed to release optimistic exposure in redis")
+	}
+}
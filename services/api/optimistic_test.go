@@ -215,7 +215,7 @@ func TestSimulateBlock(t *testing.T) {
 			backend.relay.blockSimRateLimiter = &MockBlockSimulationRateLimiter{
 				simulationError: tc.simulationError,
 			}
-			_, simErr := backend.relay.simulateBlock(context.Background(), blockSimOptions{
+			_, simErr, _ := backend.relay.simulateBlock(context.Background(), blockSimOptions{
 				isHighPrio: true,
 				log:        backend.relay.log,
 				builder: &blockBuilderCacheEntry{
@@ -235,6 +235,54 @@ func TestSimulateBlock(t *testing.T) {
 	}
 }
 
+func TestSimulateBlockCache(t *testing.T) {
+	pubkey, secretkey, backend := startTestBackend(t)
+	opts := blockSimOptions{
+		isHighPrio: true,
+		log:        backend.relay.log,
+		builder: &blockBuilderCacheEntry{
+			status: common.BuilderStatus{
+				IsOptimistic: true,
+			},
+		},
+		req: &common.BuilderBlockValidationRequest{
+			BuilderSubmitBlockRequest: common.TestBuilderSubmitBlockRequest(
+				secretkey, getTestBidTrace(*pubkey, collateral)),
+		},
+	}
+
+	// Ignorable errors are not cached
+	backend.relay.blockSimRateLimiter = &MockBlockSimulationRateLimiter{
+		simulationError: fmt.Errorf(ErrBlockAlreadyKnown), //nolint:goerr113
+	}
+	_, simErr, simCacheHit := backend.relay.simulateBlock(context.Background(), opts)
+	require.Error(t, simErr)
+	require.False(t, simCacheHit)
+
+	// Validation errors are cached
+	backend.relay.blockSimRateLimiter = &MockBlockSimulationRateLimiter{
+		simulationError: fmt.Errorf("%w: invalid block", ErrSimulationFailed),
+	}
+	_, simErr, simCacheHit = backend.relay.simulateBlock(context.Background(), opts)
+	require.Error(t, simErr)
+	require.False(t, simCacheHit)
+
+	backend.relay.blockSimRateLimiter = &MockBlockSimulationRateLimiter{}
+	_, simErr, simCacheHit = backend.relay.simulateBlock(context.Background(), opts)
+	require.ErrorIs(t, simErr, ErrSimulationFailed)
+	require.Equal(t, "simulation failed: invalid block", simErr.Error())
+	require.True(t, simCacheHit)
+
+	// A different registered gas limit needs a new simulation
+	opts.req.RegisteredGasLimit++
+	_, simErr, simCacheHit = backend.relay.simulateBlock(context.Background(), opts)
+	require.NoError(t, simErr)
+	require.False(t, simCacheHit)
+	_, simErr, simCacheHit = backend.relay.simulateBlock(context.Background(), opts)
+	require.NoError(t, simErr)
+	require.True(t, simCacheHit)
+}
+
 func TestProcessOptimisticBlock(t *testing.T) {
 	cases := []struct {
 		description     string
@@ -453,6 +501,38 @@ func TestInternalBuilderStatus(t *testing.T) {
 	setAndGetStatus("?optimistic=true", common.BuilderStatus{IsHighPrio: true, IsBlacklisted: true, IsOptimistic: true})
 }
 
+func TestInternalBuilderStatusOpenDemotion(t *testing.T) {
+	pubkey, secretkey, backend := startTestBackend(t)
+	pkStr := pubkey.String()
+	path := "/internal/v1/builder/" + pkStr
+
+	req := common.TestBuilderSubmitBlockRequest(secretkey, getTestBidTrace(*pubkey, collateral))
+	backend.relay.demoteBuilder(pkStr, &req, errFake)
+
+	// Can't re-enable optimistic mode while the demotion is open
+	rr := backend.request(http.MethodPost, path+"?optimistic=true", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), ErrBuilderDemotionsOpen.Error())
+
+	// Other status changes are still possible
+	rr = backend.request(http.MethodPost, path+"?high_prio=false", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Once resolved, it can be re-enabled
+	mockDB, ok := backend.relay.db.(*database.MockDB)
+	require.True(t, ok)
+	mockDB.Refunds[pkStr] = true
+	rr = backend.request(http.MethodPost, path+"?optimistic=true", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestInternalDemotion(t *testing.T) {
+	_, _, backend := startTestBackend(t)
+
+	rr := backend.request(http.MethodGet, "/internal/v1/demotions/1", nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
 func TestInternalBuilderCollateral(t *testing.T) {
 	pubkey, _, backend := startTestBackend(t)
 	path := "/internal/v1/builder/collateral/" + pubkey.String()
@@ -469,3 +549,34 @@ func TestInternalBuilderCollateral(t *testing.T) {
 	require.Equal(t, resp.BuilderID, "builder0x69")
 	require.Equal(t, resp.Collateral, "10000")
 }
+
+func TestOptimisticExposure(t *testing.T) {
+	pubkey, _, backend := startTestBackend(t)
+	pkStr := pubkey.String()
+	log := backend.relay.log
+	builder := &blockBuilderCacheEntry{
+		status:     common.BuilderStatus{IsOptimistic: true},
+		collateral: big.NewInt(3e9),
+		builderID:  builderID,
+	}
+	value := big.NewInt(1e9)
+
+	// Three bids fit in the collateral, the fourth doesn't
+	for i := 0; i < 3; i++ {
+		require.True(t, backend.relay.reserveOptimisticExposure(log, slot, pkStr, builder, value))
+	}
+	require.False(t, backend.relay.reserveOptimisticExposure(log, slot, pkStr, builder, value))
+	require.Equal(t, big.NewInt(3e9), backend.relay.optimisticExposure.Get(slot, builderID))
+
+	// Other slots are independent
+	require.True(t, backend.relay.reserveOptimisticExposure(log, slot+1, pkStr, builder, value))
+
+	// Successful simulations release exposure
+	backend.relay.releaseOptimisticExposure(log, slot, pkStr, builder, value)
+	require.Equal(t, big.NewInt(2e9), backend.relay.optimisticExposure.Get(slot, builderID))
+	require.True(t, backend.relay.reserveOptimisticExposure(log, slot, pkStr, builder, value))
+
+	backend.relay.optimisticExposure.Prune(slot + 1)
+	require.Equal(t, big.NewInt(0), backend.relay.optimisticExposure.Get(slot, builderID))
+	require.Equal(t, big.NewInt(1e9), backend.relay.optimisticExposure.Get(slot+1, builderID))
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrInvalidRuntimeConfig = errors.New("invalid runtime config")
+
+// runtimeConfigFromEnv returns the runtime config defaults from the environment, and the fields that are set
+// in it. They are only used for settings that aren't in Redis yet, afterwards the config is changed through
+// the internal API. The exception is FORCE_GET_HEADER_204, which always stops serving bids on this replica.
+func runtimeConfigFromEnv(log *logrus.Entry) (cfg *datastore.RuntimeConfig, envFields []string) {
+	cfg = &datastore.RuntimeConfig{
+		StopServingBids:            false,
+		DisableLowPrioBuilders:     false,
+		DisablePayloadDBStorage:    false,
+		LogInvalidSignaturePayload: false,
+		EnableBuilderCancellations: false,
+		RegValContinueOnInvalidSig: false,
+		IgnorableValidationErrors:  false,
+		GetPayloadRetryTimeoutMs:   timeoutGetPayloadRetryMs,
+		GetPayloadRequestCutoffMs:  getPayloadRequestCutoffMs,
+		GetPayloadResponseDelayMs:  getPayloadResponseDelayMs,
+	}
+
+	if os.Getenv("FORCE_GET_HEADER_204") == "1" {
+		log.Warn("env: FORCE_GET_HEADER_204 - forcing getHeader to always return 204 on this replica, regardless of the runtime config")
+		cfg.StopServingBids = true
+		envFields = append(envFields, "stop_serving_bids")
+	}
+
+	if os.Getenv("DISABLE_LOWPRIO_BUILDERS") == "1" {
+		log.Warn("env: DISABLE_LOWPRIO_BUILDERS - allowing only high-level builders")
+		cfg.DisableLowPrioBuilders = true
+		envFields = append(envFields, "disable_lowprio_builders")
+	}
+
+	if os.Getenv("DISABLE_PAYLOAD_DATABASE_STORAGE") == "1" {
+		log.Warn("env: DISABLE_PAYLOAD_DATABASE_STORAGE - disabling storing payloads in the database")
+		cfg.DisablePayloadDBStorage = true
+		envFields = append(envFields, "disable_payload_database_storage")
+	}
+
+	if os.Getenv("LOG_INVALID_GETPAYLOAD_SIGNATURE") == "1" {
+		log.Warn("env: LOG_INVALID_GETPAYLOAD_SIGNATURE - getPayload payloads with invalid proposer signature will be logged")
+		cfg.LogInvalidSignaturePayload = true
+		envFields = append(envFields, "log_invalid_getpayload_signature")
+	}
+
+	if os.Getenv("ENABLE_BUILDER_CANCELLATIONS") == "1" {
+		log.Warn("env: ENABLE_BUILDER_CANCELLATIONS - builders are allowed to cancel submissions when using ?cancellation=1")
+		cfg.EnableBuilderCancellations = true
+		envFields = append(envFields, "enable_builder_cancellations")
+	}
+
+	if os.Getenv("REGISTER_VALIDATOR_CONTINUE_ON_INVALID_SIG") == "1" {
+		log.Warn("env: REGISTER_VALIDATOR_CONTINUE_ON_INVALID_SIG - validator registration will continue processing even if one validator has an invalid signature")
+		cfg.RegValContinueOnInvalidSig = true
+		envFields = append(envFields, "register_validator_continue_on_invalid_sig")
+	}
+
+	if os.Getenv("ENABLE_IGNORABLE_VALIDATION_ERRORS") == "1" {
+		log.Warn("env: ENABLE_IGNORABLE_VALIDATION_ERRORS - some validation errors will be ignored")
+		cfg.IgnorableValidationErrors = true
+		envFields = append(envFields, "enable_ignorable_validation_errors")
+	}
+
+	for _, env := range []struct{ name, field string }{
+		{"GETPAYLOAD_RETRY_TIMEOUT_MS", "getpayload_retry_timeout_ms"},
+		{"GETPAYLOAD_REQUEST_CUTOFF_MS", "getpayload_request_cutoff_ms"},
+		{"GETPAYLOAD_RESPONSE_DELAY_MS", "getpayload_response_delay_ms"},
+	} {
+		if os.Getenv(env.name) != "" {
+			envFields = append(envFields, env.field)
+		}
+	}
+
+	return cfg, envFields
+}
+
+// warnRuntimeConfigEnvOverrides logs the settings from the environment which differ from the runtime
+// config in Redis, which takes precedence. They need to be changed through the internal API instead.
+func (api *RelayAPI) warnRuntimeConfigEnvOverrides() {
+	cfg, err := api.redis.GetRuntimeConfig(api.runtimeConfigDefaults)
+	if err != nil {
+		api.log.WithError(err).Error("failed to load runtime config")
+		return
+	}
+	fields, err := cfg.Fields()
+	if err != nil {
+		api.log.WithError(err).Error("failed to encode runtime config")
+		return
+	}
+	envFields, err := api.runtimeConfigDefaults.Fields()
+	if err != nil {
+		api.log.WithError(err).Error("failed to encode runtime config")
+		return
+	}
+	for _, field := range api.runtimeConfigEnvFields {
+		if field == "stop_serving_bids" || string(fields[field]) == string(envFields[field]) {
+			continue
+		}
+		api.log.WithFields(logrus.Fields{
+			"field":      field,
+			"envValue":   string(envFields[field]),
+			"redisValue": string(fields[field]),
+		}).Error("runtime config: the setting from the environment is ignored, since it's already in Redis - change it through the internal API")
+	}
+}
+
+// validateRuntimeConfig checks that the timings are between zero and the slot duration
+func validateRuntimeConfig(cfg *datastore.RuntimeConfig) error {
+	maxMs := int(common.DurationPerSlot.Milliseconds())
+	for _, timing := range []struct {
+		field string
+		ms    int
+	}{
+		{"getpayload_retry_timeout_ms", cfg.GetPayloadRetryTimeoutMs},
+		{"getpayload_request_cutoff_ms", cfg.GetPayloadRequestCutoffMs},
+		{"getpayload_response_delay_ms", cfg.GetPayloadResponseDelayMs},
+	} {
+		if timing.ms < 0 {
+			return fmt.Errorf("%w: %s can't be negative", ErrInvalidRuntimeConfig, timing.field)
+		} else if timing.ms > maxMs {
+			return fmt.Errorf("%w: %s can't be more than the slot duration of %d ms", ErrInvalidRuntimeConfig, timing.field, maxMs)
+		}
+	}
+	return nil
+}
+
+// loadRuntimeConfig loads the runtime config from Redis, keeping the current one if that fails
+func (api *RelayAPI) loadRuntimeConfig() {
+	cfg, err := api.redis.GetRuntimeConfig(api.runtimeConfigDefaults)
+	if err != nil {
+		api.log.WithError(err).Error("failed to load runtime config, keeping the current one")
+		return
+	}
+	if api.runtimeConfigDefaults.StopServingBids {
+		// FORCE_GET_HEADER_204 can't be turned off through the runtime config
+		cfg.StopServingBids = true
+	}
+	prev := api.runtimeConfig.Swap(cfg)
+	if *prev != *cfg {
+		api.log.WithField("runtimeConfig", cfg).Info("runtime config updated")
+	}
+	if cfg.StopServingBids && !prev.StopServingBids {
+		api.log.Warn("runtime config: stop_serving_bids - getHeader returns 204 on all replicas")
+	}
+}
+
+// startRuntimeConfigSubscription loads the runtime config whenever any replica changes it. It is also
+// loaded after (re)subscribing, to catch up on changes that were missed in the meantime.
+func (api *RelayAPI) startRuntimeConfigSubscription(ctx context.Context) {
+	for {
+		pubsub := api.redis.SubscribeRuntimeConfigUpdates(ctx)
+		if _, err := pubsub.Receive(ctx); err != nil {
+			api.log.WithError(err).Error("failed to subscribe to runtime config updates")
+		}
+		api.loadRuntimeConfig()
+		for range pubsub.Channel() {
+			api.loadRuntimeConfig()
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		api.log.Warn("runtime config updates subscription ended, resubscribing")
+		time.Sleep(time.Second)
+	}
+}
+
+// handleInternalRuntimeConfig returns the runtime config (GET), or changes the given settings on all replicas (POST)
+func (api *RelayAPI) handleInternalRuntimeConfig(w http.ResponseWriter, req *http.Request) {
+	cfg, err := api.redis.GetRuntimeConfig(api.runtimeConfigDefaults)
+	if err != nil {
+		api.log.WithError(err).Error("could not get runtime config")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if req.Method == http.MethodGet {
+		api.RespondOK(w, cfg)
+		return
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.NewDecoder(req.Body).Decode(&fields); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	} else if len(fields) == 0 {
+		api.RespondError(w, http.StatusBadRequest, "nothing to update")
+		return
+	}
+
+	newCfg, err := cfg.WithFields(fields)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if err := validateRuntimeConfig(newCfg); err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Store the parsed values, so Redis only holds valid JSON
+	newFields, err := newCfg.Fields()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for field := range fields {
+		fields[field] = newFields[field]
+	}
+
+	log := api.log.WithFields(logrus.Fields{
+		"actor":         getInternalAPIActor(req),
+		"runtimeConfig": newCfg,
+	})
+	log.Info("updating runtime config")
+	if err := api.redis.SetRuntimeConfig(fields); err != nil {
+		log.WithError(err).Error("could not update runtime config")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.loadRuntimeConfig()
+	api.auditInternalAPI(req, auditActionRuntimeConfig, "runtime_config", cfg, newCfg)
+	api.RespondOK(w, newCfg)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternalRuntimeConfig(t *testing.T) {
+	path := "/internal/v1/runtime_config"
+	backend := newTestBackend(t, 1)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{
+			GenesisTime: uint64(time.Now().UTC().Unix()),
+		},
+	}
+	mockDB := &database.MockDB{ //nolint:exhaustruct
+		InternalAPIAudit: map[int64]*database.InternalAPIAuditEntry{},
+	}
+	backend.relay.db = mockDB
+
+	// Create a bid
+	slot := uint64(2)
+	backend.relay.headSlot.Store(slot)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	opts := common.CreateTestBlockSubmissionOpts{
+		Slot:           slot,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey,
+	}
+	payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(99), &opts)
+	_, err := backend.redis.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), false)
+	require.NoError(t, err)
+	getHeader := func() int {
+		return backend.request(http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey), nil).Code
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		rr := backend.request(http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		cfg := new(datastore.RuntimeConfig)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), cfg))
+		require.Equal(t, *backend.relay.runtimeConfigDefaults, *cfg)
+		require.Equal(t, http.StatusOK, getHeader())
+	})
+
+	t.Run("stop serving bids", func(t *testing.T) {
+		rr := backend.request(http.MethodPost, path, map[string]any{"stop_serving_bids": true, "getpayload_response_delay_ms": 0})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.True(t, backend.relay.runtimeConfig.Load().StopServingBids)
+		require.Equal(t, 0, backend.relay.runtimeConfig.Load().GetPayloadResponseDelayMs)
+		require.Equal(t, http.StatusNoContent, getHeader())
+		require.Len(t, mockDB.InternalAPIAudit, 1)
+	})
+
+	t.Run("changes by other replicas", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go backend.relay.startRuntimeConfigSubscription(ctx)
+
+		require.Eventually(t, func() bool {
+			err := backend.redis.SetRuntimeConfig(map[string]json.RawMessage{"stop_serving_bids": json.RawMessage("false")})
+			require.NoError(t, err)
+			return !backend.relay.runtimeConfig.Load().StopServingBids
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, http.StatusOK, getHeader())
+	})
+
+	t.Run("invalid updates", func(t *testing.T) {
+		testCases := map[string]map[string]any{
+			"nothing to update": {},
+			"unknown field":     {"foo": true},
+			"wrong type":        {"stop_serving_bids": "yes"},
+			"negative timing":   {"getpayload_request_cutoff_ms": -1},
+			"timing after slot": {"getpayload_response_delay_ms": common.DurationPerSlot.Milliseconds() + 1},
+		}
+		for name, payload := range testCases {
+			t.Run(name, func(t *testing.T) {
+				rr := backend.request(http.MethodPost, path, payload)
+				require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+			})
+		}
+		require.False(t, backend.relay.runtimeConfig.Load().StopServingBids)
+	})
+
+	t.Run("kill switch from the environment", func(t *testing.T) {
+		defaults := backend.relay.runtimeConfigDefaults
+		t.Cleanup(func() { backend.relay.runtimeConfigDefaults = defaults })
+		forced := *defaults
+		forced.StopServingBids = true
+		backend.relay.runtimeConfigDefaults = &forced
+
+		// FORCE_GET_HEADER_204 stays in effect, although stop_serving_bids is false in Redis
+		backend.relay.loadRuntimeConfig()
+		require.True(t, backend.relay.runtimeConfig.Load().StopServingBids)
+		require.Equal(t, http.StatusNoContent, getHeader())
+
+		rr := backend.request(http.MethodPost, path, map[string]any{"stop_serving_bids": false})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.True(t, backend.relay.runtimeConfig.Load().StopServingBids)
+	})
+}
@@ -10,9 +10,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"net/http"
 	_ "net/http/pprof"
+	"net/url"
 	"os"
 	"sort"
 	"strconv"
@@ -44,6 +46,9 @@ const (
 	ErrBlockAlreadyKnown  = "simulation failed: block already known"
 	ErrBlockRequiresReorg = "simulation failed: block requires a reorg"
 	ErrMissingTrieNode    = "missing trie node"
+
+	// headerNextCursor is set on data API responses when there may be more results to page through
+	headerNextCursor = "X-Next-Cursor"
 )
 
 var (
@@ -55,6 +60,8 @@ var (
 	ErrBuilderAPIWithoutSecretKey = errors.New("cannot start builder API without secret key")
 	ErrMismatchedForkVersions     = errors.New("can not find matching fork versions as retrieved from beacon node")
 	ErrMissingForkVersions        = errors.New("invalid bellatrix/capella fork version from beacon node")
+	ErrBuilderDemotionsOpen       = errors.New("builder has demotions that need to be resolved first")
+	ErrBuilderOptimisticRevoked   = errors.New("builder optimistic mode was permanently revoked")
 )
 
 var (
@@ -69,13 +76,30 @@ var (
 	pathSubmitNewBlock       = "/relay/v1/builder/blocks"
 
 	// Data API
-	pathDataProposerPayloadDelivered = "/relay/v1/data/bidtraces/proposer_payload_delivered"
-	pathDataBuilderBidsReceived      = "/relay/v1/data/bidtraces/builder_blocks_received"
-	pathDataValidatorRegistration    = "/relay/v1/data/validator_registration"
+	pathDataProposerPayloadDelivered     = "/relay/v1/data/bidtraces/proposer_payload_delivered"
+	pathDataBuilderBidsReceived          = "/relay/v1/data/bidtraces/builder_blocks_received"
+	pathDataValidatorRegistration        = "/relay/v1/data/validator_registration"
+	pathDataValidatorRegistrationHistory = "/relay/v1/data/validator_registration/history"
+	pathDataValidatorRegistrations       = "/relay/v1/data/validator_registrations"
+	pathDataBuilders                     = "/relay/v1/data/builders"
+	pathDataSlotAuctionSummary           = "/relay/v1/data/slot_auction_summary"
+	pathDataSignedBlindedBlock           = "/relay/v1/data/signed_blinded_block"
+	pathDataExecutionPayload             = "/relay/v1/data/execution_payload"
+	pathDataGetPayloadTiming             = "/relay/v1/data/getpayload_timing"
+	pathDataGraphQL                      = "/relay/v1/data/graphql"
 
 	// Internal API
 	pathInternalBuilderStatus     = "/internal/v1/builder/{pubkey:0x[a-fA-F0-9]+}"
 	pathInternalBuilderCollateral = "/internal/v1/builder/collateral/{pubkey:0x[a-fA-F0-9]+}"
+	pathInternalBuilders          = "/internal/v1/builders"
+	pathInternalDemotions         = "/internal/v1/demotions"
+	pathInternalDemotion          = "/internal/v1/demotions/{id:[0-9]+}"
+	pathInternalDataAPIKeys       = "/internal/v1/data_api_keys"
+	pathInternalDataAPIKey        = "/internal/v1/data_api_keys/{id:[0-9]+}"
+	pathInternalAudit             = "/internal/v1/audit"
+	pathInternalRefresh           = "/internal/v1/refresh/{cache}"
+	pathInternalRuntimeConfig     = "/internal/v1/runtime_config"
+	pathInternalBidWithdrawal     = "/internal/v1/bids/withdraw"
 
 	// number of goroutines to save active validator
 	numActiveValidatorProcessors = cli.GetEnvInt("NUM_ACTIVE_VALIDATOR_PROCESSORS", 10)
@@ -93,6 +117,11 @@ var (
 	apiIdleTimeoutMs       = cli.GetEnvInt("API_TIMEOUT_IDLE_MS", 3000)
 	apiMaxHeaderBytes      = cli.GetEnvInt("API_MAX_HEADER_BYTES", 60000)
 
+	// data API range limits, to protect the database
+	dataAPIMaxSlotRange    = uint64(cli.GetEnvInt("DATA_API_MAX_SLOT_RANGE", 7200))
+	dataAPIMaxTimeRangeSec = int64(cli.GetEnvInt("DATA_API_MAX_TIME_RANGE_SEC", 86400))
+	dataAPIMaxRegPubkeys   = cli.GetEnvInt("DATA_API_MAX_REGISTRATION_PUBKEYS", 1000)
+
 	// user-agents which shouldn't receive bids
 	apiNoHeaderUserAgents = common.GetEnvStrSlice("NO_HEADER_USERAGENTS", []string{
 		"mev-boost/v1.5.0 Go-http-client/1.1", // Prysm v4.0.1 (Shapella signing issue)
@@ -144,6 +173,7 @@ type blockSimOptions struct {
 type blockBuilderCacheEntry struct {
 	status     common.BuilderStatus
 	collateral *big.Int
+	builderID  string
 }
 
 type blockSimResult struct {
@@ -151,6 +181,7 @@ type blockSimResult struct {
 	optimisticSubmission bool
 	requestErr           error
 	validationErr        error
+	simCacheHit          bool
 }
 
 // RelayAPI represents a single Relay instance
@@ -182,6 +213,7 @@ type RelayAPI struct {
 	isUpdatingProposerDuties uberatomic.Bool
 
 	blockSimRateLimiter IBlockSimRateLimiter
+	blockSimBreaker     *blockSimCircuitBreaker
 
 	activeValidatorC chan boostTypes.PubkeyHex
 	validatorRegC    chan boostTypes.SignedValidatorRegistration
@@ -189,14 +221,13 @@ type RelayAPI struct {
 	// used to wait on any active getPayload calls on shutdown
 	getPayloadCallsInFlight sync.WaitGroup
 
-	// Feature flags
-	ffForceGetHeader204          bool
-	ffDisableLowPrioBuilders     bool
-	ffDisablePayloadDBStorage    bool // disable storing the execution payloads in the database
-	ffLogInvalidSignaturePayload bool // log payload if getPayload signature validation fails
-	ffEnableCancellations        bool // whether to enable block builder cancellations
-	ffRegValContinueOnInvalidSig bool // whether to continue processing further validators if one fails
-	ffIgnorableValidationErrors  bool // whether to enable ignorable validation errors
+	// Feature flags and timings, shared by all replicas through Redis
+	runtimeConfig          uberatomic.Pointer[datastore.RuntimeConfig]
+	runtimeConfigDefaults  *datastore.RuntimeConfig
+	runtimeConfigEnvFields []string // fields of the runtime config set in the environment
+
+	// stop sending bids on shutdown, only on this replica
+	isStopping uberatomic.Bool
 
 	payloadAttributes     map[string]payloadAttributesHelper // key:parentBlockHash
 	payloadAttributesLock sync.RWMutex
@@ -207,8 +238,20 @@ type RelayAPI struct {
 	optimisticBlocksInFlight uberatomic.Uint64
 	// Wait group used to monitor status of per-slot optimistic processing.
 	optimisticBlocksWG sync.WaitGroup
-	// Cache for builder statuses and collaterals.
-	blockBuildersCache map[string]*blockBuilderCacheEntry
+	// Value of the optimistic bids not verified yet, per slot and builder.
+	optimisticExposure *optimisticExposureLedger
+	// Cache for builder statuses and collaterals. The map is replaced rather
+	// than modified, and the entries are never changed once in the map.
+	blockBuildersCache     map[string]*blockBuilderCacheEntry
+	blockBuildersCacheLock sync.Mutex
+	// Cache for data API responses, nil if disabled.
+	dataCache *dataResponseCache
+	// Cache for data API key lookups.
+	dataAPIKeys *dataAPIKeyCache
+	// Bearer tokens for the internal API, token to name. The internal API is unauthenticated if empty.
+	internalAPITokens map[string]string
+	// Name of this replica in the results of cache refreshes, which run on all replicas.
+	replicaName string
 }
 
 // NewRelayAPI creates a new service. if builders is nil, allow any builder
@@ -257,6 +300,16 @@ func NewRelayAPI(opts RelayAPIOpts) (api *RelayAPI, err error) {
 		}
 	}
 
+	blockSimRateLimiter, err := NewBlockSimulationRateLimiter(opts.BlockSimURL)
+	if err != nil {
+		return nil, err
+	}
+
+	internalAPITokens, err := parseInternalAPITokens(internalAPITokenEntries)
+	if err != nil {
+		return nil, err
+	}
+
 	api = &RelayAPI{
 		opts:         opts,
 		log:          opts.Log,
@@ -271,46 +324,27 @@ func NewRelayAPI(opts RelayAPIOpts) (api *RelayAPI, err error) {
 		payloadAttributes: make(map[string]payloadAttributesHelper),
 
 		proposerDutiesResponse: &[]byte{},
-		blockSimRateLimiter:    NewBlockSimulationRateLimiter(opts.BlockSimURL),
+		blockSimRateLimiter:    blockSimRateLimiter,
+		blockSimBreaker:        newBlockSimCircuitBreaker(opts.Log, defaultBlockSimCircuitBreakerOpts()),
+		optimisticExposure:     newOptimisticExposureLedger(),
+		dataAPIKeys:            newDataAPIKeyCache(),
+		internalAPITokens:      internalAPITokens,
+		replicaName:            getReplicaName(),
 
 		activeValidatorC: make(chan boostTypes.PubkeyHex, 450_000),
 		validatorRegC:    make(chan boostTypes.SignedValidatorRegistration, 450_000),
 	}
 
-	if os.Getenv("FORCE_GET_HEADER_204") == "1" {
-		api.log.Warn("env: FORCE_GET_HEADER_204 - forcing getHeader to always return 204")
-		api.ffForceGetHeader204 = true
-	}
-
-	if os.Getenv("DISABLE_LOWPRIO_BUILDERS") == "1" {
-		api.log.Warn("env: DISABLE_LOWPRIO_BUILDERS - allowing only high-level builders")
-		api.ffDisableLowPrioBuilders = true
-	}
-
-	if os.Getenv("DISABLE_PAYLOAD_DATABASE_STORAGE") == "1" {
-		api.log.Warn("env: DISABLE_PAYLOAD_DATABASE_STORAGE - disabling storing payloads in the database")
-		api.ffDisablePayloadDBStorage = true
-	}
-
-	if os.Getenv("LOG_INVALID_GETPAYLOAD_SIGNATURE") == "1" {
-		api.log.Warn("env: LOG_INVALID_GETPAYLOAD_SIGNATURE - getPayload payloads with invalid proposer signature will be logged")
-		api.ffLogInvalidSignaturePayload = true
+	if opts.InternalAPI && len(internalAPITokens) == 0 {
+		api.log.Warn("env: INTERNAL_API_TOKENS is not set - the internal API is unauthenticated")
 	}
 
-	if os.Getenv("ENABLE_BUILDER_CANCELLATIONS") == "1" {
-		api.log.Warn("env: ENABLE_BUILDER_CANCELLATIONS - builders are allowed to cancel submissions when using ?cancellation=1")
-		api.ffEnableCancellations = true
+	if opts.DataAPI && dataCacheMaxBytes > 0 {
+		api.dataCache = newDataResponseCache(dataCacheMaxBytes)
 	}
 
-	if os.Getenv("REGISTER_VALIDATOR_CONTINUE_ON_INVALID_SIG") == "1" {
-		api.log.Warn("env: REGISTER_VALIDATOR_CONTINUE_ON_INVALID_SIG - validator registration will continue processing even if one validator has an invalid signature")
-		api.ffRegValContinueOnInvalidSig = true
-	}
-
-	if os.Getenv("ENABLE_IGNORABLE_VALIDATION_ERRORS") == "1" {
-		api.log.Warn("env: ENABLE_IGNORABLE_VALIDATION_ERRORS - some validation errors will be ignored")
-		api.ffIgnorableValidationErrors = true
-	}
+	api.runtimeConfigDefaults, api.runtimeConfigEnvFields = runtimeConfigFromEnv(api.log)
+	api.runtimeConfig.Store(api.runtimeConfigDefaults)
 
 	return api, nil
 }
@@ -339,9 +373,17 @@ func (api *RelayAPI) getRouter() http.Handler {
 	// Data API
 	if api.opts.DataAPI {
 		api.log.Info("data API enabled")
-		r.HandleFunc(pathDataProposerPayloadDelivered, api.handleDataProposerPayloadDelivered).Methods(http.MethodGet)
-		r.HandleFunc(pathDataBuilderBidsReceived, api.handleDataBuilderBidsReceived).Methods(http.MethodGet)
-		r.HandleFunc(pathDataValidatorRegistration, api.handleDataValidatorRegistration).Methods(http.MethodGet)
+		r.HandleFunc(pathDataProposerPayloadDelivered, api.withDataAPIQuota(api.withDataCache(dataCacheUntilDelivery, api.handleDataProposerPayloadDelivered))).Methods(http.MethodGet)
+		r.HandleFunc(pathDataBuilderBidsReceived, api.withDataAPIQuota(api.withDataCache(dataCacheImmutableOnly, api.handleDataBuilderBidsReceived))).Methods(http.MethodGet)
+		r.HandleFunc(pathDataValidatorRegistration, api.withDataAPIQuota(api.handleDataValidatorRegistration)).Methods(http.MethodGet)
+		r.HandleFunc(pathDataValidatorRegistrationHistory, api.withDataAPIQuota(api.handleDataValidatorRegistrationHistory)).Methods(http.MethodGet)
+		r.HandleFunc(pathDataValidatorRegistrations, api.withDataAPIQuota(api.handleDataValidatorRegistrations)).Methods(http.MethodPost)
+		r.HandleFunc(pathDataBuilders, api.withDataAPIQuota(api.withDataCache(dataCacheUntilDelivery, api.handleDataBuilders))).Methods(http.MethodGet)
+		r.HandleFunc(pathDataSlotAuctionSummary, api.withDataAPIQuota(api.withDataCache(dataCacheImmutableOnly, api.handleDataSlotAuctionSummary))).Methods(http.MethodGet)
+		r.HandleFunc(pathDataSignedBlindedBlock, api.withDataAPIQuota(api.withDataCache(dataCacheUntilDelivery, api.handleDataSignedBlindedBlock))).Methods(http.MethodGet)
+		r.HandleFunc(pathDataExecutionPayload, api.withDataAPIQuota(api.withDataCache(dataCacheUntilDelivery, api.handleDataExecutionPayload))).Methods(http.MethodGet)
+		r.HandleFunc(pathDataGetPayloadTiming, api.withDataAPIQuota(api.withDataCache(dataCacheImmutableOnly, api.handleDataGetPayloadTiming))).Methods(http.MethodGet)
+		r.HandleFunc(pathDataGraphQL, api.withDataAPIQuota(api.handleDataGraphQL)).Methods(http.MethodGet, http.MethodPost)
 	}
 
 	// Pprof
@@ -353,13 +395,22 @@ func (api *RelayAPI) getRouter() http.Handler {
 	// /internal/...
 	if api.opts.InternalAPI {
 		api.log.Info("internal API enabled")
-		r.HandleFunc(pathInternalBuilderStatus, api.handleInternalBuilderStatus).Methods(http.MethodGet, http.MethodPost, http.MethodPut)
-		r.HandleFunc(pathInternalBuilderCollateral, api.handleInternalBuilderCollateral).Methods(http.MethodPost, http.MethodPut)
+		r.HandleFunc(pathInternalBuilderStatus, api.withInternalAPIAuth(api.handleInternalBuilderStatus)).Methods(http.MethodGet, http.MethodPost, http.MethodPut)
+		r.HandleFunc(pathInternalBuilderCollateral, api.withInternalAPIAuth(api.handleInternalBuilderCollateral)).Methods(http.MethodPost, http.MethodPut)
+		r.HandleFunc(pathInternalBuilders, api.withInternalAPIAuth(api.handleInternalBuilders)).Methods(http.MethodGet, http.MethodPost)
+		r.HandleFunc(pathInternalDemotions, api.withInternalAPIAuth(api.handleInternalDemotions)).Methods(http.MethodGet)
+		r.HandleFunc(pathInternalDemotion, api.withInternalAPIAuth(api.handleInternalDemotion)).Methods(http.MethodGet, http.MethodPost, http.MethodPut)
+		r.HandleFunc(pathInternalDataAPIKeys, api.withInternalAPIAuth(api.handleInternalDataAPIKeys)).Methods(http.MethodGet, http.MethodPost)
+		r.HandleFunc(pathInternalDataAPIKey, api.withInternalAPIAuth(api.handleInternalDataAPIKey)).Methods(http.MethodDelete)
+		r.HandleFunc(pathInternalAudit, api.withInternalAPIAuth(api.handleInternalAudit)).Methods(http.MethodGet)
+		r.HandleFunc(pathInternalRefresh, api.withInternalAPIAuth(api.handleInternalRefresh)).Methods(http.MethodPost)
+		r.HandleFunc(pathInternalRuntimeConfig, api.withInternalAPIAuth(api.handleInternalRuntimeConfig)).Methods(http.MethodGet, http.MethodPost)
+		r.HandleFunc(pathInternalBidWithdrawal, api.withInternalAPIAuth(api.handleInternalBidWithdrawal)).Methods(http.MethodPost)
 	}
 
 	// r.Use(mux.CORSMethodMiddleware(r))
 	loggedRouter := httplogger.LoggingMiddlewareLogrus(api.log, r)
-	withGz := gziphandler.GzipHandler(loggedRouter)
+	withGz := gziphandler.GzipHandler(withResponseFlusher(loggedRouter))
 	return withGz
 }
 
@@ -428,6 +479,20 @@ func (api *RelayAPI) StartServer() (err error) {
 		return ErrMismatchedForkVersions
 	}
 
+	// Load the runtime config shared by all replicas, and apply the changes made through any of them
+	if err := api.redis.InitRuntimeConfig(api.runtimeConfigDefaults); err != nil {
+		return err
+	}
+	api.warnRuntimeConfigEnvOverrides()
+	api.loadRuntimeConfig()
+	go api.startRuntimeConfigSubscription(context.Background())
+
+	// Apply builder status changes made through any replica to the builder cache, whichever APIs are enabled
+	go api.startBuilderStatusUpdatesSubscription(context.Background())
+
+	// Refresh the caches on the requests made through the internal API of any replica
+	go api.startCacheRefreshSubscription(context.Background())
+
 	// start things for the block-builder API
 	if api.opts.BlockBuilderAPI {
 		// Get current proposer duties blocking before starting, to have them ready
@@ -502,7 +567,7 @@ func (api *RelayAPI) StopServer() (err error) {
 
 	if api.opts.ProposerAPI {
 		// stop sending bids
-		api.ffForceGetHeader204 = true
+		api.isStopping.Store(true)
 		api.log.Info("Disabled sending bids, waiting a few seconds...")
 
 		// wait a few seconds, for any pending getPayload call to complete
@@ -542,35 +607,77 @@ func (api *RelayAPI) startValidatorRegistrationDBProcessor() {
 }
 
 // simulateBlock sends a request for a block simulation to blockSimRateLimiter.
-func (api *RelayAPI) simulateBlock(ctx context.Context, opts blockSimOptions) (requestErr, validationErr error) {
+// Verdicts are cached in Redis, so identical submissions (i.e. the same block
+// submitted by another builder pubkey or to another replica) aren't simulated again.
+func (api *RelayAPI) simulateBlock(ctx context.Context, opts blockSimOptions) (requestErr, validationErr error, simCacheHit bool) {
+	payload := opts.req.BuilderSubmitBlockRequest
+	cachedResult, err := api.redis.GetBlockSimResult(payload.BlockHash(), payload.ProposerFeeRecipient(), opts.req.RegisteredGasLimit, payload.Value().String())
+	if err != nil {
+		opts.log.WithError(err).Error("failed to get cached block simulation result")
+	} else if cachedResult != nil {
+		log := opts.log.WithField("simCacheHit", true)
+		if cachedResult.Error != "" {
+			validationErr = fmt.Errorf("%w: %s", ErrSimulationFailed, cachedResult.Error)
+			log.WithError(validationErr).Warn("block validation failed (cached)")
+			return nil, validationErr, true
+		}
+		log.Info("block validation successful (cached)")
+		return nil, nil, true
+	}
+
+	// Fail fast for low-prio submissions while the simulator is unhealthy
+	if !api.blockSimBreaker.Allow(opts.isHighPrio) {
+		opts.log.Info("block validation skipped: circuit breaker open")
+		return ErrBlockSimCircuitOpen, nil, false
+	}
+
 	t := time.Now()
 	requestErr, validationErr = api.blockSimRateLimiter.Send(ctx, opts.req, opts.isHighPrio, opts.fastTrack)
+	api.blockSimBreaker.Record(requestErr)
 	log := opts.log.WithFields(logrus.Fields{
 		"durationMs": time.Since(t).Milliseconds(),
 		"numWaiting": api.blockSimRateLimiter.CurrentCounter(),
 	})
 	if validationErr != nil {
-		if api.ffIgnorableValidationErrors {
+		// Ignorable errors depend on the state of the simulator, not on the block
+		ignoreError := validationErr.Error() == ErrBlockAlreadyKnown || validationErr.Error() == ErrBlockRequiresReorg || strings.Contains(validationErr.Error(), ErrMissingTrieNode)
+		if !ignoreError {
+			api.saveBlockSimResult(opts, validationErr)
+		}
+		if api.runtimeConfig.Load().IgnorableValidationErrors {
 			// Operators chooses to ignore certain validation errors
-			ignoreError := validationErr.Error() == ErrBlockAlreadyKnown || validationErr.Error() == ErrBlockRequiresReorg || strings.Contains(validationErr.Error(), ErrMissingTrieNode)
 			if ignoreError {
 				log.WithError(validationErr).Warn("block validation failed with ignorable error")
-				return nil, nil
+				return nil, nil, false
 			}
 		}
 		log.WithError(validationErr).Warn("block validation failed")
-		return nil, validationErr
+		return nil, validationErr, false
 	}
 	if requestErr != nil {
 		log.WithError(requestErr).Warn("block validation failed: request error")
-		return requestErr, nil
+		return requestErr, nil, false
 	}
+	api.saveBlockSimResult(opts, nil)
 	log.Info("block validation successful")
-	return nil, nil
+	return nil, nil, false
+}
+
+// saveBlockSimResult caches the verdict of the simulator
+func (api *RelayAPI) saveBlockSimResult(opts blockSimOptions, validationErr error) {
+	result := &datastore.BlockSimResult{Error: ""}
+	if validationErr != nil {
+		result.Error = strings.TrimPrefix(validationErr.Error(), ErrSimulationFailed.Error()+": ")
+	}
+	payload := opts.req.BuilderSubmitBlockRequest
+	err := api.redis.SaveBlockSimResult(payload.BlockHash(), payload.ProposerFeeRecipient(), opts.req.RegisteredGasLimit, payload.Value().String(), result)
+	if err != nil {
+		opts.log.WithError(err).Error("failed to cache block simulation result")
+	}
 }
 
 func (api *RelayAPI) demoteBuilder(pubkey string, req *common.BuilderSubmitBlockRequest, simError error) {
-	builderEntry, ok := api.blockBuildersCache[pubkey]
+	builderEntry, ok := api.getBlockBuilderCacheEntry(pubkey)
 	if !ok {
 		api.log.Warnf("builder %v not in the builder cache", pubkey)
 		builderEntry = &blockBuilderCacheEntry{} //nolint:exhaustruct
@@ -584,6 +691,15 @@ func (api *RelayAPI) demoteBuilder(pubkey string, req *common.BuilderSubmitBlock
 	if err := api.db.SetBlockBuilderIDStatusIsOptimistic(pubkey, false); err != nil {
 		api.log.Error(fmt.Errorf("error setting builder: %v status: %w", pubkey, err))
 	}
+	update := &datastore.BuilderStatusUpdate{ //nolint:exhaustruct
+		Kind:          datastore.BuilderStatusUpdateOptimistic,
+		BuilderPubkey: pubkey,
+		BuilderID:     builderEntry.builderID,
+		IsOptimistic:  false,
+	}
+	// Mark builder as non-optimistic here right away, the other replicas follow with the published update
+	api.applyBuilderStatusUpdate(update)
+	api.publishBuilderStatusUpdate(update)
 	// Write to demotions table.
 	api.log.WithFields(logrus.Fields{"builder_pubkey": pubkey}).Info("demoting builder")
 	if err := api.db.InsertBuilderDemotion(req, simError); err != nil {
@@ -612,11 +728,22 @@ func (api *RelayAPI) processOptimisticBlock(opts blockSimOptions, simResultC cha
 		// it for logging, it is not atomic to avoid the performance impact.
 		"optBlocksInFlight": api.optimisticBlocksInFlight,
 	}).Infof("simulating optimistic block with hash: %v", opts.req.BuilderSubmitBlockRequest.BlockHash())
-	reqErr, simErr := api.simulateBlock(ctx, opts)
-	simResultC <- &blockSimResult{reqErr == nil, true, reqErr, simErr}
+	reqErr, simErr, simCacheHit := api.simulateBlock(ctx, opts)
+	simResultC <- &blockSimResult{reqErr == nil, true, reqErr, simErr, simCacheHit}
+	if reqErr != nil && simErr == nil && api.blockSimBreaker.IsOpen() {
+		// The simulator is unhealthy, so the request error is not the builder's fault. The block stays
+		// unverified, so its value is kept in the builder's optimistic exposure for this slot.
+		opts.log.WithError(reqErr).WithFields(logrus.Fields{
+			"event":         "optimistic_demotion_skipped",
+			"builderPubkey": builderPubkey,
+		}).Error("block simulation request failed while circuit breaker is open, not demoting builder")
+		return
+	}
+	if reqErr == nil && simErr == nil {
+		payload := opts.req.BuilderSubmitBlockRequest
+		api.releaseOptimisticExposure(opts.log, payload.Slot(), builderPubkey, opts.builder, payload.Value())
+	}
 	if reqErr != nil || simErr != nil {
-		// Mark builder as non-optimistic.
-		opts.builder.status.IsOptimistic = false
 		api.log.WithError(simErr).Warn("block simulation failed in processOptimisticBlock, demoting builder")
 
 		var demotionErr error
@@ -739,11 +866,16 @@ func (api *RelayAPI) updateProposerDuties(headSlot uint64) {
 		return
 	}
 
-	// Load upcoming proposer duties from Redis
+	if _, err := api.loadProposerDuties(headSlot); err != nil {
+		api.log.WithError(err).Error("failed getting proposer duties from redis")
+	}
+}
+
+// loadProposerDuties loads the upcoming proposer duties from Redis, and returns how they changed
+func (api *RelayAPI) loadProposerDuties(headSlot uint64) (*proposerDutiesDiff, error) {
 	duties, err := api.redis.GetProposerDuties()
 	if err != nil {
-		api.log.WithError(err).Error("failed getting proposer duties from redis")
-		return
+		return nil, err
 	}
 
 	// Prepare raw bytes for HTTP response
@@ -760,6 +892,7 @@ func (api *RelayAPI) updateProposerDuties(headSlot uint64) {
 
 	// Update
 	api.proposerDutiesLock.Lock()
+	prevDutiesMap := api.proposerDutiesMap
 	if len(respBytes) > 0 {
 		api.proposerDutiesResponse = &respBytes
 	}
@@ -774,6 +907,8 @@ func (api *RelayAPI) updateProposerDuties(headSlot uint64) {
 	}
 	sort.Strings(_duties)
 	api.log.Infof("proposer duties updated: %s", strings.Join(_duties, ", "))
+
+	return diffProposerDuties(prevDutiesMap, dutiesMap), nil
 }
 
 func (api *RelayAPI) prepareBuildersForSlot(headSlot uint64) {
@@ -781,34 +916,58 @@ func (api *RelayAPI) prepareBuildersForSlot(headSlot uint64) {
 	// safely update the slot.
 	api.optimisticBlocksWG.Wait()
 	api.optimisticSlot.Store(headSlot + 1)
+	api.optimisticExposure.Prune(headSlot + 1)
+
+	if _, err := api.loadBlockBuilders(); err != nil {
+		api.log.WithError(err).Error("unable to read block builders from db, not updating builder cache")
+	}
+}
 
+// loadBlockBuilders replaces the builder cache with the builders in the database, and returns how it changed
+func (api *RelayAPI) loadBlockBuilders() (*buildersCacheDiff, error) {
 	builders, err := api.db.GetBlockBuilders()
 	if err != nil {
-		api.log.WithError(err).Error("unable to read block builders from db, not updating builder cache")
-		return
+		return nil, err
 	}
 	api.log.Debugf("Updating builder cache with %d builders from database", len(builders))
 
+	// Expired blacklists are lifted here, at most a slot after their expiry
+	now := time.Now()
 	newCache := make(map[string]*blockBuilderCacheEntry)
 	for _, v := range builders {
 		entry := &blockBuilderCacheEntry{ //nolint:exhaustruct
 			status: common.BuilderStatus{
 				IsHighPrio:    v.IsHighPrio,
-				IsBlacklisted: v.IsBlacklisted,
+				IsBlacklisted: v.IsBlacklistedAt(now),
 				IsOptimistic:  v.IsOptimistic,
 			},
+			builderID: v.BuilderID,
 		}
 		// Try to parse builder collateral string to big int.
 		builderCollateral, ok := big.NewInt(0).SetString(v.Collateral, 10)
 		if !ok {
-			api.log.WithError(err).Errorf("could not parse builder collateral string %s", v.Collateral)
+			api.log.Errorf("could not parse builder collateral string %s", v.Collateral)
 			entry.collateral = big.NewInt(0)
 		} else {
 			entry.collateral = builderCollateral
 		}
 		newCache[v.BuilderPubkey] = entry
 	}
+	api.blockBuildersCacheLock.Lock()
+	prevCache := api.blockBuildersCache
 	api.blockBuildersCache = newCache
+	api.blockBuildersCacheLock.Unlock()
+
+	return diffBuildersCache(prevCache, newCache), nil
+}
+
+// getBlockBuilderCacheEntry returns the cache entry of a builder. Entries are never changed once
+// they're in the cache, so they can be used without holding the lock.
+func (api *RelayAPI) getBlockBuilderCacheEntry(pubkey string) (*blockBuilderCacheEntry, bool) {
+	api.blockBuildersCacheLock.Lock()
+	defer api.blockBuildersCacheLock.Unlock()
+	entry, ok := api.blockBuildersCache[pubkey]
+	return entry, ok
 }
 
 func (api *RelayAPI) startKnownValidatorUpdates() {
@@ -1041,7 +1200,7 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 			return
 		} else if !ok {
 			regLog.Info("invalid validator signature")
-			if api.ffRegValContinueOnInvalidSig {
+			if api.runtimeConfig.Load().RegValContinueOnInvalidSig {
 				return
 			} else {
 				handleError(regLog, http.StatusBadRequest, fmt.Sprintf("failed to verify validator signature for %s", signedValidatorRegistration.Message.Pubkey.String()))
@@ -1133,14 +1292,15 @@ func (api *RelayAPI) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if api.ffForceGetHeader204 {
+	runtimeConfig := api.runtimeConfig.Load()
+	if api.isStopping.Load() || runtimeConfig.StopServingBids {
 		log.Info("forced getHeader 204 response")
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
 	// Only allow requests for the current slot until a certain cutoff time
-	if getPayloadRequestCutoffMs > 0 && msIntoSlot > 0 && msIntoSlot > int64(getPayloadRequestCutoffMs) {
+	if runtimeConfig.GetPayloadRequestCutoffMs > 0 && msIntoSlot > 0 && msIntoSlot > int64(runtimeConfig.GetPayloadRequestCutoffMs) {
 		log.Info("getHeader sent too late")
 		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("sent too late - %d ms into slot", msIntoSlot))
 		return
@@ -1178,6 +1338,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	ua := req.UserAgent()
 	headSlot := api.headSlot.Load()
 	receivedAt := time.Now().UTC()
+	runtimeConfig := api.runtimeConfig.Load()
 	log := api.log.WithFields(logrus.Fields{
 		"method":                "getPayload",
 		"ua":                    ua,
@@ -1282,7 +1443,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	if api.isCapella(headSlot + 1) {
 		ok, err := boostTypes.VerifySignature(payload.Message(), api.opts.EthNetDetails.DomainBeaconProposerCapella, pk[:], payload.Signature())
 		if !ok || err != nil {
-			if api.ffLogInvalidSignaturePayload {
+			if runtimeConfig.LogInvalidSignaturePayload {
 				txt, _ := json.Marshal(payload) //nolint:errchkjson
 				fmt.Println("payload_invalid_sig_capella: ", string(txt), "pubkey:", proposerPubkey.String())
 			}
@@ -1294,7 +1455,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		// Fall-back to verifying the bellatrix signature
 		ok, err := boostTypes.VerifySignature(payload.Message(), api.opts.EthNetDetails.DomainBeaconProposerBellatrix, pk[:], payload.Signature())
 		if !ok || err != nil {
-			if api.ffLogInvalidSignaturePayload {
+			if runtimeConfig.LogInvalidSignaturePayload {
 				txt, _ := json.Marshal(payload) //nolint:errchkjson
 				fmt.Println("payload_invalid_sig_bellatrix: ", string(txt), "pubkey:", proposerPubkey.String())
 			}
@@ -1308,6 +1469,16 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	log = log.WithField("timestampAfterSignatureVerify", time.Now().UTC().UnixMilli())
 	log.Info("getPayload request received")
 
+	// Refuse bids that were withdrawn from the auction
+	isWithdrawn, err := api.redis.IsBidWithdrawn(payload.Slot(), payload.BlockHash())
+	if err != nil {
+		log.WithError(err).Error("failed to check if bid was withdrawn")
+	} else if isWithdrawn {
+		log.Warn("getPayload for a withdrawn bid")
+		api.RespondError(w, http.StatusBadRequest, "bid was withdrawn")
+		return
+	}
+
 	// TODO: store signed blinded block in database (always)
 
 	// Get the response - from Redis, Memcache or DB
@@ -1315,7 +1486,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	getPayloadResp, err := api.datastore.GetGetPayloadResponse(payload.Slot(), proposerPubkey.String(), payload.BlockHash())
 	if err != nil || getPayloadResp == nil {
 		log.WithError(err).Warn("failed getting execution payload (1/2)")
-		time.Sleep(time.Duration(timeoutGetPayloadRetryMs) * time.Millisecond)
+		time.Sleep(time.Duration(runtimeConfig.GetPayloadRetryTimeoutMs) * time.Millisecond)
 
 		// Try again
 		getPayloadResp, err = api.datastore.GetGetPayloadResponse(payload.Slot(), proposerPubkey.String(), payload.BlockHash())
@@ -1365,7 +1536,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 			log.Info("waiting until slot start t=0")
 			time.Sleep(time.Duration(delayMillis) * time.Millisecond)
 		}
-	} else if getPayloadRequestCutoffMs > 0 && msIntoSlot > int64(getPayloadRequestCutoffMs) {
+	} else if runtimeConfig.GetPayloadRequestCutoffMs > 0 && msIntoSlot > int64(runtimeConfig.GetPayloadRequestCutoffMs) {
 		// Reject requests after cutoff time
 		log.Warn("getPayload sent too late")
 		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("sent too late - %d ms into slot", msIntoSlot))
@@ -1403,7 +1574,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	log.WithField("msNeededForPublishing", msNeededForPublishing).Info("block published through beacon node")
 
 	// give the beacon network some time to propagate the block
-	time.Sleep(time.Duration(getPayloadResponseDelayMs) * time.Millisecond)
+	time.Sleep(time.Duration(runtimeConfig.GetPayloadResponseDelayMs) * time.Millisecond)
 
 	// respond to the HTTP request
 	api.RespondOK(w, getPayloadResp)
@@ -1533,7 +1704,8 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	}()
 
 	// If cancellations are disabled but builder requested it, return error
-	if isCancellationEnabled && !api.ffEnableCancellations {
+	runtimeConfig := api.runtimeConfig.Load()
+	if isCancellationEnabled && !runtimeConfig.EnableBuilderCancellations {
 		log.Info("builder submitted with cancellations enabled, but feature flag is disabled")
 		api.RespondError(w, http.StatusBadRequest, "cancellations are disabled")
 		return
@@ -1626,7 +1798,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	}
 
 	builderPubkey := payload.BuilderPubkey()
-	builderEntry, ok := api.blockBuildersCache[builderPubkey.String()]
+	builderEntry, ok := api.getBlockBuilderCacheEntry(builderPubkey.String())
 	if !ok {
 		log.Warnf("unable to read builder: %s from the builder cache, using low-prio and no collateral", builderPubkey.String())
 		builderEntry = &blockBuilderCacheEntry{
@@ -1636,6 +1808,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 				IsBlacklisted: false,
 			},
 			collateral: big.NewInt(0),
+			builderID:  "",
 		}
 	}
 	log = log.WithFields(logrus.Fields{
@@ -1659,7 +1832,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	}
 
 	// In case only high-prio requests are accepted, fail others
-	if api.ffDisableLowPrioBuilders && !builderEntry.status.IsHighPrio {
+	if runtimeConfig.DisableLowPrioBuilders && !builderEntry.status.IsHighPrio {
 		log.Info("rejecting low-prio builder (ff-disable-low-prio-builders)")
 		time.Sleep(200 * time.Millisecond)
 		w.WriteHeader(http.StatusOK)
@@ -1761,14 +1934,15 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 
 	// Save the builder submission to the database whenever this function ends
 	defer func() {
-		savePayloadToDatabase := !api.ffDisablePayloadDBStorage
+		savePayloadToDatabase := !runtimeConfig.DisablePayloadDBStorage
 		var simResult *blockSimResult
 		select {
 		case simResult = <-simResultC:
 		case <-time.After(10 * time.Second):
 			log.Warn("timed out waiting for simulation result")
-			simResult = &blockSimResult{false, false, nil, nil}
+			simResult = &blockSimResult{false, false, nil, nil, false}
 		}
+		pf.SimCacheHit = simResult.simCacheHit
 
 		submissionEntry, err := api.db.SaveBuilderBlockSubmission(payload, simResult.requestErr, simResult.validationErr, receivedAt, eligibleAt, simResult.wasSimulated, savePayloadToDatabase, pf, simResult.optimisticSubmission)
 		if err != nil {
@@ -1795,7 +1969,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 
 		// Without cancellations, discard bids below floor value
 		if !isCancellationEnabled && !isBidAboveFloor {
-			simResultC <- &blockSimResult{false, false, nil, nil}
+			simResultC <- &blockSimResult{false, false, nil, nil, false}
 			log.Info("ignoring submission without cancellation and below floor bid value")
 			api.RespondMsg(w, http.StatusAccepted, "ignoring submission without cancellation and below floor bid value")
 			return
@@ -1839,22 +2013,28 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 			RegisteredGasLimit:        slotDuty.Entry.Message.GasLimit,
 		},
 	}
-	// With sufficient collateral, process the block optimistically.
+	// With sufficient collateral, process the block optimistically. The collateral
+	// needs to cover all unverified optimistic bids of the builder in this slot.
+	// While the simulator is unhealthy, blocks are only accepted once verified.
 	if builderEntry.status.IsOptimistic &&
+		!api.blockSimBreaker.IsOpen() &&
 		builderEntry.collateral.Cmp(payload.Value()) >= 0 &&
-		payload.Slot() == api.optimisticSlot.Load() {
+		payload.Slot() == api.optimisticSlot.Load() &&
+		api.reserveOptimisticExposure(log, payload.Slot(), builderPubkey.String(), builderEntry, payload.Value()) {
 		go api.processOptimisticBlock(opts, simResultC)
 	} else {
 		// Simulate block (synchronously).
-		requestErr, validationErr := api.simulateBlock(req.Context(), opts) // success/error logging happens inside
-		simResultC <- &blockSimResult{requestErr == nil, false, requestErr, validationErr}
+		requestErr, validationErr, simCacheHit := api.simulateBlock(req.Context(), opts) // success/error logging happens inside
+		simResultC <- &blockSimResult{requestErr == nil, false, requestErr, validationErr, simCacheHit}
 		validationDurationMs := time.Since(timeBeforeValidation).Milliseconds()
 		log = log.WithFields(logrus.Fields{
 			"timestampAfterValidation": time.Now().UTC().UnixMilli(),
 			"validationDurationMs":     validationDurationMs,
 		})
 		if requestErr != nil { // Request error
-			if os.IsTimeout(requestErr) {
+			if errors.Is(requestErr, ErrBlockSimCircuitOpen) {
+				api.RespondError(w, http.StatusServiceUnavailable, requestErr.Error())
+			} else if os.IsTimeout(requestErr) {
 				api.RespondError(w, http.StatusGatewayTimeout, "validation request timeout")
 			} else {
 				api.RespondError(w, http.StatusBadRequest, requestErr.Error())
@@ -1927,12 +2107,17 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	}
 
 	// 2. Save bid and recalculate top bid
-	updateBidResult, err := api.redis.SaveBidAndUpdateTopBid(payload, getPayloadResponse, getHeaderResponse, receivedAt, isCancellationEnabled, floorBidValue)
+	updateBidResult, err := api.redis.SaveBidAndUpdateTopBid(payload, getPayloadResponse, getHeaderResponse, receivedAt, isCancellationEnabled)
 	if err != nil {
 		log.WithError(err).Error("could not save bid and update top bids")
 		api.RespondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if updateBidResult.IsWithdrawn {
+		log.Info("block was withdrawn from the auction")
+		api.RespondError(w, http.StatusBadRequest, "block was withdrawn from the auction")
+		return
+	}
 
 	// Add fields to logs
 	log = log.WithFields(logrus.Fields{
@@ -1988,14 +2173,15 @@ func (api *RelayAPI) handleInternalBuilderStatus(w http.ResponseWriter, req *htt
 		return
 	}
 	if req.Method == http.MethodGet {
-		api.RespondOK(w, builderEntry)
+		api.RespondOK(w, builderEntry.EffectiveAt(time.Now()))
 		return
 	} else if req.Method == http.MethodPost || req.Method == http.MethodPut || req.Method == http.MethodPatch {
-		st := common.BuilderStatus{
+		prevStatus := common.BuilderStatus{
 			IsHighPrio:    builderEntry.IsHighPrio,
-			IsBlacklisted: builderEntry.IsBlacklisted,
+			IsBlacklisted: builderEntry.IsBlacklistedAt(time.Now()),
 			IsOptimistic:  builderEntry.IsOptimistic,
 		}
+		st := prevStatus
 		trueStr := "true"
 		args := req.URL.Query()
 		if args.Get("high_prio") != "" {
@@ -2007,6 +2193,13 @@ func (api *RelayAPI) handleInternalBuilderStatus(w http.ResponseWriter, req *htt
 		if args.Get("optimistic") != "" {
 			st.IsOptimistic = args.Get("optimistic") == trueStr
 		}
+		if st.IsOptimistic && !prevStatus.IsOptimistic {
+			// Re-enabling optimistic mode requires all demotions to be reviewed
+			if err := api.checkBuilderDemotionsResolved(builderPubkey); err != nil {
+				api.RespondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
 		api.log.WithFields(logrus.Fields{
 			"builderPubkey": builderPubkey,
 			"isHighPrio":    st.IsHighPrio,
@@ -2020,6 +2213,14 @@ func (api *RelayAPI) handleInternalBuilderStatus(w http.ResponseWriter, req *htt
 			api.RespondError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+		api.publishBuilderStatusUpdate(&datastore.BuilderStatusUpdate{ //nolint:exhaustruct
+			Kind:          datastore.BuilderStatusUpdateStatus,
+			BuilderPubkey: builderPubkey,
+			IsHighPrio:    st.IsHighPrio,
+			IsBlacklisted: st.IsBlacklisted,
+			IsOptimistic:  st.IsOptimistic,
+		})
+		api.auditInternalAPI(req, auditActionBuilderStatus, builderPubkey, prevStatus, st)
 		api.RespondOK(w, st)
 	}
 }
@@ -2037,20 +2238,210 @@ func (api *RelayAPI) handleInternalBuilderCollateral(w http.ResponseWriter, req
 			"value":      value,
 		})
 		log.Infof("updating builder collateral")
+		var oldCollateral *builderCollateralAudit
+		if builderEntry, err := api.db.GetBlockBuilderByPubkey(builderPubkey); err == nil {
+			oldCollateral = &builderCollateralAudit{BuilderID: builderEntry.BuilderID, Collateral: builderEntry.Collateral}
+		}
 		if err := api.db.SetBlockBuilderCollateral(builderPubkey, collateral, value); err != nil {
 			fullErr := fmt.Errorf("unable to set collateral in db for pubkey: %v: %w", builderPubkey, err)
 			log.Error(fullErr.Error())
 			api.RespondError(w, http.StatusInternalServerError, fullErr.Error())
 			return
 		}
+		api.publishBuilderStatusUpdate(&datastore.BuilderStatusUpdate{ //nolint:exhaustruct
+			Kind:          datastore.BuilderStatusUpdateCollateral,
+			BuilderPubkey: builderPubkey,
+			BuilderID:     collateral,
+			Collateral:    value,
+		})
+		api.auditInternalAPI(req, auditActionBuilderCollateral, builderPubkey, oldCollateral, builderCollateralAudit{BuilderID: collateral, Collateral: value})
 		api.RespondOK(w, NilResponse)
 	}
 }
 
+// checkBuilderDemotionsResolved returns an error if the builder (or another pubkey with the same
+// builder id) has demotions that are still open or were permanently revoked
+func (api *RelayAPI) checkBuilderDemotionsResolved(builderPubkey string) error {
+	counts, err := api.db.GetBuilderDemotionReviewCounts(builderPubkey)
+	if err != nil {
+		return fmt.Errorf("could not get builder demotions: %w", err)
+	}
+	if counts[database.DemotionReviewRevoked] > 0 {
+		return ErrBuilderOptimisticRevoked
+	}
+	if counts[database.DemotionReviewOpen] > 0 {
+		return fmt.Errorf("%w: %d open", ErrBuilderDemotionsOpen, counts[database.DemotionReviewOpen])
+	}
+	return nil
+}
+
+// handleInternalDemotion returns a single demotion, or resolves it with the review
+// status and notes of an operator. Resolving as reinstated makes the builder
+// optimistic again, once none of its other demotions are open.
+func (api *RelayAPI) handleInternalDemotion(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(req)["id"], 10, 64)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid demotion id")
+		return
+	}
+
+	demotion, err := api.db.GetBuilderDemotionByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		api.RespondError(w, http.StatusNotFound, "demotion not found")
+		return
+	} else if err != nil {
+		api.log.WithError(err).Error("could not get demotion")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if req.Method == http.MethodGet {
+		api.RespondOK(w, database.BuilderDemotionEntryToRefundJSON(demotion))
+		return
+	}
+
+	args := req.URL.Query()
+	status := args.Get("status")
+	notes := args.Get("notes")
+	if !database.IsValidDemotionReviewStatus(status) || status == database.DemotionReviewOpen {
+		api.RespondError(w, http.StatusBadRequest, "invalid status argument, must be one of: refunded, reinstated, revoked")
+		return
+	}
+	if notes == "" {
+		api.RespondError(w, http.StatusBadRequest, "notes argument is required")
+		return
+	}
+	if demotion.ReviewStatus == database.DemotionReviewRevoked {
+		api.RespondError(w, http.StatusBadRequest, "demotion was permanently revoked")
+		return
+	}
+
+	prevReview := demotionReviewAudit{Status: demotion.ReviewStatus, Notes: demotion.ReviewNotes}
+	log := api.log.WithFields(logrus.Fields{
+		"demotionID":    id,
+		"builderPubkey": demotion.BuilderPubkey,
+		"blockHash":     demotion.BlockHash,
+		"prevStatus":    demotion.ReviewStatus,
+		"status":        status,
+		"notes":         notes,
+	})
+	log.Info("resolving builder demotion")
+	if err := api.db.ResolveBuilderDemotion(id, status, notes); err != nil {
+		log.WithError(err).Error("could not resolve demotion")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	api.auditInternalAPI(req, auditActionDemotionReview, strconv.FormatInt(id, 10), prevReview, demotionReviewAudit{Status: status, Notes: notes})
+	demotion.ReviewStatus = status
+	demotion.ReviewNotes = notes
+
+	if status == database.DemotionReviewReinstated {
+		if err := api.checkBuilderDemotionsResolved(demotion.BuilderPubkey); err != nil {
+			log.WithError(err).Info("not reinstating optimistic mode yet")
+		} else if err := api.db.SetBlockBuilderIDStatusIsOptimistic(demotion.BuilderPubkey, true); err != nil {
+			log.WithError(err).Error("could not reinstate optimistic mode")
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		} else {
+			log.Info("reinstated optimistic mode for builder")
+			builderID := ""
+			if builder, err := api.db.GetBlockBuilderByPubkey(demotion.BuilderPubkey); err == nil {
+				builderID = builder.BuilderID
+			}
+			api.publishBuilderStatusUpdate(&datastore.BuilderStatusUpdate{ //nolint:exhaustruct
+				Kind:          datastore.BuilderStatusUpdateOptimistic,
+				BuilderPubkey: demotion.BuilderPubkey,
+				BuilderID:     builderID,
+				IsOptimistic:  true,
+			})
+		}
+	}
+
+	api.RespondOK(w, database.BuilderDemotionEntryToRefundJSON(demotion))
+}
+
+// handleInternalDemotions returns the demotions in a slot range, with the refund owed to
+// the proposer and the evidence. Responds with CSV if requested in the Accept header.
+func (api *RelayAPI) handleInternalDemotions(w http.ResponseWriter, req *http.Request) {
+	args := req.URL.Query()
+
+	slotFrom, err := strconv.ParseUint(args.Get("slot_from"), 10, 64)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid slot_from argument")
+		return
+	}
+	slotTo, err := strconv.ParseUint(args.Get("slot_to"), 10, 64)
+	if err != nil || slotTo < slotFrom {
+		api.RespondError(w, http.StatusBadRequest, "invalid slot_to argument")
+		return
+	}
+
+	builderPubkey := args.Get("builder_pubkey")
+	if builderPubkey != "" {
+		if err = checkBLSPublicKeyHex(builderPubkey); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid builder_pubkey argument")
+			return
+		}
+	}
+
+	demotions, err := api.db.GetBuilderDemotions(database.GetBuilderDemotionsFilters{SlotFrom: slotFrom, SlotTo: slotTo, BuilderPubkey: builderPubkey}) //nolint:exhaustruct
+	if err != nil {
+		api.log.WithError(err).Error("error getting demotions")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]common.BuilderDemotionRefundJSON, len(demotions))
+	for i, demotion := range demotions {
+		response[i] = database.BuilderDemotionEntryToRefundJSON(demotion)
+	}
+
+	respondDataRows(api, w, req, response)
+}
+
 // -----------
 //  DATA APIS
 // -----------
 
+// parseDataSlotRangeArgs parses the slot_from/slot_to and epoch arguments into an inclusive slot range.
+// It responds with an error and returns ok=false if the arguments are invalid.
+func (api *RelayAPI) parseDataSlotRangeArgs(w http.ResponseWriter, args url.Values) (slotFrom, slotTo uint64, ok bool) {
+	var err error
+	if args.Get("epoch") != "" {
+		if args.Get("slot_from") != "" || args.Get("slot_to") != "" {
+			api.RespondError(w, http.StatusBadRequest, "cannot specify both epoch and slot_from/slot_to")
+			return 0, 0, false
+		}
+		epoch, err := strconv.ParseUint(args.Get("epoch"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid epoch argument")
+			return 0, 0, false
+		}
+		slotFrom = epoch * common.SlotsPerEpoch
+		return slotFrom, slotFrom + common.SlotsPerEpoch - 1, true
+	}
+
+	if args.Get("slot_from") == "" && args.Get("slot_to") == "" {
+		return 0, 0, true
+	}
+
+	slotFrom, err = strconv.ParseUint(args.Get("slot_from"), 10, 64)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid slot_from argument")
+		return 0, 0, false
+	}
+	slotTo, err = strconv.ParseUint(args.Get("slot_to"), 10, 64)
+	if err != nil || slotTo < slotFrom {
+		api.RespondError(w, http.StatusBadRequest, "invalid slot_to argument")
+		return 0, 0, false
+	}
+	if slotTo-slotFrom >= dataAPIMaxSlotRange {
+		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum slot range is %d", dataAPIMaxSlotRange))
+		return 0, 0, false
+	}
+	return slotFrom, slotTo, true
+}
+
 func (api *RelayAPI) handleDataProposerPayloadDelivered(w http.ResponseWriter, req *http.Request) {
 	var err error
 	args := req.URL.Query()
@@ -2076,6 +2467,15 @@ func (api *RelayAPI) handleDataProposerPayloadDelivered(w http.ResponseWriter, r
 		}
 	}
 
+	var ok bool
+	filters.SlotFrom, filters.SlotTo, ok = api.parseDataSlotRangeArgs(w, args)
+	if !ok {
+		return
+	} else if filters.Slot > 0 && filters.SlotTo > 0 {
+		api.RespondError(w, http.StatusBadRequest, "cannot specify both slot and slot range")
+		return
+	}
+
 	if args.Get("block_hash") != "" {
 		var hash boostTypes.Hash
 		err = hash.UnmarshalText([]byte(args.Get("block_hash")))
@@ -2116,8 +2516,8 @@ func (api *RelayAPI) handleDataProposerPayloadDelivered(w http.ResponseWriter, r
 			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
 			return
 		}
-		if _limit > filters.Limit {
-			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", filters.Limit))
+		if maxLimit := dataAPIMaxLimit(req, filters.Limit); _limit > maxLimit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", maxLimit))
 			return
 		}
 		filters.Limit = _limit
@@ -2141,7 +2541,15 @@ func (api *RelayAPI) handleDataProposerPayloadDelivered(w http.ResponseWriter, r
 		response[i] = database.DeliveredPayloadEntryToBidTraceV2JSON(payload)
 	}
 
-	api.RespondOK(w, response)
+	// At most one payload is delivered per slot, so the slot works as a keyset cursor when ordering by slot
+	if filters.Slot == 0 && filters.OrderByValue == 0 && uint64(len(deliveredPayloads)) == filters.Limit {
+		lastSlot := deliveredPayloads[len(deliveredPayloads)-1].Slot
+		if lastSlot > 1 {
+			w.Header().Set(headerNextCursor, strconv.FormatUint(lastSlot-1, 10))
+		}
+	}
+
+	respondDataRows(api, w, req, response)
 }
 
 func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *http.Request) {
@@ -2149,16 +2557,24 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 	args := req.URL.Query()
 
 	filters := database.GetBuilderSubmissionsFilters{
-		Limit:         500,
-		Slot:          0,
-		BlockHash:     "",
-		BlockNumber:   0,
-		BuilderPubkey: "",
+		Limit:          500,
+		Slot:           0,
+		SlotFrom:       0,
+		SlotTo:         0,
+		ReceivedAfter:  time.Time{},
+		ReceivedBefore: time.Time{},
+		Cursor:         0,
+		BlockHash:      "",
+		BlockNumber:    0,
+		BuilderPubkey:  "",
 	}
 
 	if args.Get("cursor") != "" {
-		api.RespondError(w, http.StatusBadRequest, "cursor argument not supported")
-		return
+		filters.Cursor, err = strconv.ParseUint(args.Get("cursor"), 10, 64)
+		if err != nil || filters.Cursor == 0 {
+			api.RespondError(w, http.StatusBadRequest, "invalid cursor argument")
+			return
+		}
 	}
 
 	if args.Get("slot") != "" {
@@ -2169,6 +2585,34 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 		}
 	}
 
+	var ok bool
+	filters.SlotFrom, filters.SlotTo, ok = api.parseDataSlotRangeArgs(w, args)
+	if !ok {
+		return
+	} else if filters.Slot > 0 && filters.SlotTo > 0 {
+		api.RespondError(w, http.StatusBadRequest, "cannot specify both slot and slot range")
+		return
+	}
+
+	if args.Get("received_after") != "" || args.Get("received_before") != "" {
+		receivedAfter, err := strconv.ParseInt(args.Get("received_after"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid received_after argument")
+			return
+		}
+		receivedBefore, err := strconv.ParseInt(args.Get("received_before"), 10, 64)
+		if err != nil || receivedBefore <= receivedAfter {
+			api.RespondError(w, http.StatusBadRequest, "invalid received_before argument")
+			return
+		}
+		if receivedBefore-receivedAfter > dataAPIMaxTimeRangeSec {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum received range is %d seconds", dataAPIMaxTimeRangeSec))
+			return
+		}
+		filters.ReceivedAfter = time.Unix(receivedAfter, 0)
+		filters.ReceivedBefore = time.Unix(receivedBefore, 0)
+	}
+
 	if args.Get("block_hash") != "" {
 		var hash boostTypes.Hash
 		err = hash.UnmarshalText([]byte(args.Get("block_hash")))
@@ -2196,8 +2640,8 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 	}
 
 	// at least one query arguments is required
-	if filters.Slot == 0 && filters.BlockHash == "" && filters.BlockNumber == 0 && filters.BuilderPubkey == "" {
-		api.RespondError(w, http.StatusBadRequest, "need to query for specific slot or block_hash or block_number or builder_pubkey")
+	if filters.Slot == 0 && filters.SlotTo == 0 && filters.ReceivedBefore.IsZero() && filters.BlockHash == "" && filters.BlockNumber == 0 && filters.BuilderPubkey == "" {
+		api.RespondError(w, http.StatusBadRequest, "need to query for specific slot or slot range or received range or block_hash or block_number or builder_pubkey")
 		return
 	}
 
@@ -2207,11 +2651,14 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
 			return
 		}
-		if _limit > filters.Limit {
-			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", filters.Limit))
+		if maxLimit := dataAPIMaxLimit(req, filters.Limit); _limit > maxLimit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", maxLimit))
 			return
 		}
 		filters.Limit = _limit
+	} else if filters.Cursor == 0 && (filters.Slot > 0 || filters.BlockHash != "" || filters.BlockNumber > 0) {
+		// these filters are narrow enough to return all results, unless the client asks to paginate
+		filters.Limit = 0
 	}
 
 	blockSubmissions, err := api.db.GetBuilderSubmissions(filters)
@@ -2226,7 +2673,15 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 		response[i] = database.BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(payload)
 	}
 
-	api.RespondOK(w, response)
+	// Submissions are ordered by id descending, the next page starts right below the last returned id
+	if filters.Limit > 0 && uint64(len(blockSubmissions)) == filters.Limit {
+		lastID := blockSubmissions[len(blockSubmissions)-1].ID
+		if lastID > 1 {
+			w.Header().Set(headerNextCursor, strconv.FormatInt(lastID-1, 10))
+		}
+	}
+
+	respondDataRows(api, w, req, response)
 }
 
 func (api *RelayAPI) handleDataValidatorRegistration(w http.ResponseWriter, req *http.Request) {
@@ -2261,5 +2716,338 @@ func (api *RelayAPI) handleDataValidatorRegistration(w http.ResponseWriter, req
 		return
 	}
 
+	if dataResponseContentType(req) != contentTypeJSON {
+		respondDataRows(api, w, req, []validatorRegistrationRow{{signedRegistration}})
+		return
+	}
 	api.RespondOK(w, signedRegistration)
 }
+
+func (api *RelayAPI) handleDataValidatorRegistrationHistory(w http.ResponseWriter, req *http.Request) {
+	args := req.URL.Query()
+	pkStr := args.Get("pubkey")
+	if pkStr == "" {
+		api.RespondError(w, http.StatusBadRequest, "missing pubkey argument")
+		return
+	}
+
+	var pk boostTypes.PublicKey
+	err := pk.UnmarshalText([]byte(pkStr))
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid pubkey")
+		return
+	}
+
+	limit := uint64(200)
+	if args.Get("limit") != "" {
+		_limit, err := strconv.ParseUint(args.Get("limit"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
+			return
+		}
+		if maxLimit := dataAPIMaxLimit(req, limit); _limit > maxLimit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", maxLimit))
+			return
+		}
+		limit = _limit
+	}
+
+	registrationEntries, err := api.db.GetValidatorRegistrationHistory(pkStr, limit)
+	if err != nil {
+		api.log.WithError(err).Error("error getting validator registration history")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.respondValidatorRegistrations(w, req, registrationEntries)
+}
+
+func (api *RelayAPI) handleDataValidatorRegistrations(w http.ResponseWriter, req *http.Request) {
+	pubkeys := []string{}
+	if err := json.NewDecoder(req.Body).Decode(&pubkeys); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid request body, expected a list of pubkeys")
+		return
+	}
+
+	if len(pubkeys) == 0 {
+		api.RespondError(w, http.StatusBadRequest, "no pubkeys in request")
+		return
+	} else if len(pubkeys) > dataAPIMaxRegPubkeys {
+		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum number of pubkeys is %d", dataAPIMaxRegPubkeys))
+		return
+	}
+
+	for _, pkStr := range pubkeys {
+		if err := checkBLSPublicKeyHex(pkStr); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid pubkey "+pkStr)
+			return
+		}
+	}
+
+	registrationEntries, err := api.db.GetValidatorRegistrationsForPubkeys(pubkeys)
+	if err != nil {
+		api.log.WithError(err).Error("error getting validator registrations")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.respondValidatorRegistrations(w, req, registrationEntries)
+}
+
+func (api *RelayAPI) handleDataBuilders(w http.ResponseWriter, req *http.Request) {
+	args := req.URL.Query()
+
+	// statistics window in slots, ending at the head slot
+	window := dataAPIMaxSlotRange
+	if args.Get("window") != "" {
+		_window, err := strconv.ParseUint(args.Get("window"), 10, 64)
+		if err != nil || _window == 0 {
+			api.RespondError(w, http.StatusBadRequest, "invalid window argument")
+			return
+		}
+		if _window > dataAPIMaxSlotRange {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum window is %d", dataAPIMaxSlotRange))
+			return
+		}
+		window = _window
+	}
+
+	// Without the head slot, the window would cover the whole submissions table
+	headSlot := api.headSlot.Load()
+	if headSlot == 0 {
+		api.RespondError(w, http.StatusServiceUnavailable, "head slot not known yet, try again later")
+		return
+	}
+	windowSlotFrom := uint64(0)
+	if headSlot > window {
+		windowSlotFrom = headSlot - window
+	}
+
+	builderStats, err := api.db.GetBuilderStats(database.GetBuilderStatsFilters{BuilderID: args.Get("builder_id"), SlotFrom: windowSlotFrom}) //nolint:exhaustruct
+	if err != nil {
+		api.log.WithError(err).Error("error getting builder stats")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]common.BuilderStatsJSON, len(builderStats))
+	for i, entry := range builderStats {
+		response[i] = database.BuilderStatsEntryToJSON(entry, windowSlotFrom)
+	}
+
+	respondDataRows(api, w, req, response)
+}
+
+func (api *RelayAPI) handleDataSlotAuctionSummary(w http.ResponseWriter, req *http.Request) {
+	args := req.URL.Query()
+
+	slotFrom, slotTo, ok := api.parseDataSlotRangeArgs(w, args)
+	if !ok {
+		return
+	}
+
+	if args.Get("slot") != "" {
+		if slotTo > 0 {
+			api.RespondError(w, http.StatusBadRequest, "cannot specify both slot and slot range")
+			return
+		}
+		slot, err := strconv.ParseUint(args.Get("slot"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
+			return
+		}
+		slotFrom, slotTo = slot, slot
+	} else if slotTo == 0 {
+		api.RespondError(w, http.StatusBadRequest, "need to query for specific slot or slot range or epoch")
+		return
+	}
+
+	summaries, err := api.db.GetSlotAuctionSummaries(slotFrom, slotTo)
+	if err != nil {
+		api.log.WithError(err).Error("error getting slot auction summaries")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]common.SlotAuctionSummaryJSON, len(summaries))
+	for i, summary := range summaries {
+		response[i] = database.SlotAuctionSummaryEntryToJSON(summary)
+	}
+
+	respondDataRows(api, w, req, response)
+}
+
+// getDeliveredPayloadByBlockHashArg looks up the delivered payload for the block_hash argument. It responds with an
+// error and returns ok=false if the argument is invalid or no payload was delivered for the block hash.
+func (api *RelayAPI) getDeliveredPayloadByBlockHashArg(w http.ResponseWriter, req *http.Request) (deliveredPayload *database.DeliveredPayloadEntry, ok bool) {
+	blockHash := req.URL.Query().Get("block_hash")
+	var hash boostTypes.Hash
+	if err := hash.UnmarshalText([]byte(blockHash)); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid block_hash argument")
+		return nil, false
+	}
+
+	deliveredPayload, err := api.db.GetDeliveredPayloadByBlockHash(hash.String())
+	if errors.Is(err, sql.ErrNoRows) {
+		api.RespondError(w, http.StatusNotFound, "no payload delivered for block_hash")
+		return nil, false
+	} else if err != nil {
+		api.log.WithError(err).Error("error getting delivered payload")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return deliveredPayload, true
+}
+
+func (api *RelayAPI) handleDataSignedBlindedBlock(w http.ResponseWriter, req *http.Request) {
+	deliveredPayload, ok := api.getDeliveredPayloadByBlockHashArg(w, req)
+	if !ok {
+		return
+	}
+
+	if !deliveredPayload.SignedBlindedBeaconBlock.Valid {
+		api.RespondError(w, http.StatusNotFound, "signed blinded block not available")
+		return
+	}
+
+	version := "bellatrix"
+	if api.isCapella(deliveredPayload.Slot) {
+		version = "capella"
+	}
+
+	api.RespondOK(w, common.VersionedDataJSON{
+		Version: version,
+		Data:    json.RawMessage(deliveredPayload.SignedBlindedBeaconBlock.String),
+	})
+}
+
+func (api *RelayAPI) handleDataExecutionPayload(w http.ResponseWriter, req *http.Request) {
+	deliveredPayload, ok := api.getDeliveredPayloadByBlockHashArg(w, req)
+	if !ok {
+		return
+	}
+
+	// Only payloads that were delivered are exposed, the lookup above makes sure of that
+	executionPayload, err := api.db.GetExecutionPayloadEntryBySlotPkHash(deliveredPayload.Slot, deliveredPayload.ProposerPubkey, deliveredPayload.BlockHash)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && executionPayload == nil) {
+		api.RespondError(w, http.StatusNotFound, "execution payload not available")
+		return
+	} else if err != nil {
+		api.log.WithError(err).Error("error getting execution payload")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.RespondOK(w, common.VersionedDataJSON{
+		Version: executionPayload.Version,
+		Data:    json.RawMessage(executionPayload.Payload),
+	})
+}
+
+func (api *RelayAPI) handleDataGetPayloadTiming(w http.ResponseWriter, req *http.Request) {
+	args := req.URL.Query()
+
+	filters := database.GetPayloadsFilters{ //nolint:exhaustruct
+		Limit: 200,
+	}
+
+	var ok bool
+	filters.SlotFrom, filters.SlotTo, ok = api.parseDataSlotRangeArgs(w, args)
+	if !ok {
+		return
+	}
+
+	if args.Get("slot") != "" {
+		if filters.SlotTo > 0 {
+			api.RespondError(w, http.StatusBadRequest, "cannot specify both slot and slot range")
+			return
+		}
+		slot, err := strconv.ParseUint(args.Get("slot"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
+			return
+		}
+		filters.SlotFrom, filters.SlotTo = slot, slot
+	}
+
+	if args.Get("proposer_pubkey") != "" {
+		if err := checkBLSPublicKeyHex(args.Get("proposer_pubkey")); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid proposer_pubkey argument")
+			return
+		}
+		filters.ProposerPubkey = args.Get("proposer_pubkey")
+	}
+
+	if filters.SlotTo == 0 && filters.ProposerPubkey == "" {
+		api.RespondError(w, http.StatusBadRequest, "need to query for specific slot or slot range or epoch or proposer_pubkey")
+		return
+	}
+
+	if args.Get("limit") != "" {
+		_limit, err := strconv.ParseUint(args.Get("limit"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
+			return
+		}
+		if maxLimit := dataAPIMaxLimit(req, filters.Limit); _limit > maxLimit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", maxLimit))
+			return
+		}
+		filters.Limit = _limit
+	}
+
+	deliveredPayloads, err := api.db.GetRecentDeliveredPayloads(filters)
+	if err != nil {
+		api.log.WithError(err).Error("error getting delivered payloads")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tooLateSlotTo := filters.SlotTo
+	if tooLateSlotTo == 0 {
+		tooLateSlotTo = math.MaxInt64
+	}
+	tooLateGetPayloads, err := api.db.GetTooLateGetPayloads(filters.SlotFrom, tooLateSlotTo, filters.ProposerPubkey, filters.Limit)
+	if err != nil {
+		api.log.WithError(err).Error("error getting too late getPayload calls")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	genesisTime := uint64(0)
+	if api.genesisInfo != nil {
+		genesisTime = api.genesisInfo.Data.GenesisTime
+	}
+
+	response := make([]common.GetPayloadTimingJSON, 0, len(deliveredPayloads)+len(tooLateGetPayloads))
+	for _, payload := range deliveredPayloads {
+		response = append(response, database.DeliveredPayloadEntryToGetPayloadTimingJSON(payload, genesisTime))
+	}
+	for _, entry := range tooLateGetPayloads {
+		response = append(response, database.TooLateGetPayloadEntryToGetPayloadTimingJSON(entry))
+	}
+	sort.SliceStable(response, func(i, j int) bool {
+		return response[i].Slot > response[j].Slot
+	})
+	if uint64(len(response)) > filters.Limit {
+		response = response[:filters.Limit]
+	}
+
+	respondDataRows(api, w, req, response)
+}
+
+// respondValidatorRegistrations responds with the registration entries as a list of signed validator registrations
+func (api *RelayAPI) respondValidatorRegistrations(w http.ResponseWriter, req *http.Request, registrationEntries []*database.ValidatorRegistrationEntry) {
+	response := make([]validatorRegistrationRow, len(registrationEntries))
+	for i, registrationEntry := range registrationEntries {
+		signedRegistration, err := registrationEntry.ToSignedValidatorRegistration()
+		if err != nil {
+			api.log.WithError(err).Error("error converting registration entry to signed validator registration")
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response[i] = validatorRegistrationRow{signedRegistration}
+	}
+
+	respondDataRows(api, w, req, response)
+}
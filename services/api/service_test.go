@@ -288,7 +288,7 @@ func TestGetHeader(t *testing.T) {
 		ProposerPubkey: proposerPubkey,
 	}
 	payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, bidValue, &opts)
-	_, err := backend.redis.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+	_, err := backend.redis.SaveBidAndUpdateTopBid(payload, getPayloadResp, getHeaderResp, time.Now(), false)
 	require.NoError(t, err)
 
 	// Check 1: regular request works and returns a bid
@@ -363,6 +363,377 @@ func TestDataApiGetDataProposerPayloadDelivered(t *testing.T) {
 	})
 }
 
+func TestDataApiPagination(t *testing.T) {
+	builderPubkey := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+
+	t.Run("builder_blocks_received", func(t *testing.T) {
+		path := "/relay/v1/data/bidtraces/builder_blocks_received"
+		backend := newTestBackend(t, 1)
+		mockDB := database.MockDB{}
+		for id := int64(5); id > 0; id-- {
+			mockDB.BuilderSubmissions = append(mockDB.BuilderSubmissions, &database.BuilderBlockSubmissionEntry{ID: id, Slot: uint64(100 + id), BuilderPubkey: builderPubkey}) //nolint:exhaustruct
+		}
+		backend.relay.db = mockDB
+
+		rr := backend.request(http.MethodGet, path+"?cursor=abc&builder_pubkey="+builderPubkey, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid cursor argument")
+
+		rr = backend.request(http.MethodGet, path+"?limit=2&builder_pubkey="+builderPubkey, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "3", rr.Header().Get(headerNextCursor))
+		resp := []common.BidTraceV2WithTimestampJSON{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp, 2)
+		require.Equal(t, uint64(105), resp[0].Slot)
+
+		rr = backend.request(http.MethodGet, path+"?limit=2&cursor=3&builder_pubkey="+builderPubkey, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "1", rr.Header().Get(headerNextCursor))
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp, 2)
+		require.Equal(t, uint64(103), resp[0].Slot)
+
+		rr = backend.request(http.MethodGet, path+"?limit=2&cursor=1&builder_pubkey="+builderPubkey, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Empty(t, rr.Header().Get(headerNextCursor))
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp, 1)
+	})
+
+	t.Run("proposer_payload_delivered", func(t *testing.T) {
+		path := "/relay/v1/data/bidtraces/proposer_payload_delivered"
+		backend := newTestBackend(t, 1)
+		mockDB := database.MockDB{}
+		for slot := uint64(10); slot > 7; slot-- {
+			mockDB.DeliveredPayloads = append(mockDB.DeliveredPayloads, &database.DeliveredPayloadEntry{Slot: slot}) //nolint:exhaustruct
+		}
+		backend.relay.db = mockDB
+
+		rr := backend.request(http.MethodGet, path+"?limit=2", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "8", rr.Header().Get(headerNextCursor))
+
+		rr = backend.request(http.MethodGet, path+"?limit=2&cursor=8", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Empty(t, rr.Header().Get(headerNextCursor))
+
+		// no cursor when ordering by value
+		rr = backend.request(http.MethodGet, path+"?limit=2&order_by=-value", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Empty(t, rr.Header().Get(headerNextCursor))
+	})
+}
+
+func TestDataApiRangeFilters(t *testing.T) {
+	pathBids := "/relay/v1/data/bidtraces/builder_blocks_received"
+	pathPayloads := "/relay/v1/data/bidtraces/proposer_payload_delivered"
+	backend := newTestBackend(t, 1)
+
+	testCases := []struct {
+		name   string
+		path   string
+		status int
+		errMsg string
+	}{
+		{"bids slot range", pathBids + "?slot_from=100&slot_to=200", http.StatusOK, ""},
+		{"bids epoch", pathBids + "?epoch=200000", http.StatusOK, ""},
+		{"bids received range", pathBids + "?received_after=1690000000&received_before=1690003600", http.StatusOK, ""},
+		{"bids missing slot_to", pathBids + "?slot_from=100", http.StatusBadRequest, "invalid slot_to argument"},
+		{"bids reversed slot range", pathBids + "?slot_from=200&slot_to=100", http.StatusBadRequest, "invalid slot_to argument"},
+		{"bids slot range too large", pathBids + "?slot_from=0&slot_to=7200", http.StatusBadRequest, "maximum slot range is 7200"},
+		{"bids epoch and slot range", pathBids + "?epoch=1&slot_from=1&slot_to=2", http.StatusBadRequest, "cannot specify both epoch and slot_from/slot_to"},
+		{"bids slot and slot range", pathBids + "?slot=1&slot_from=1&slot_to=2", http.StatusBadRequest, "cannot specify both slot and slot range"},
+		{"bids missing received_before", pathBids + "?received_after=1690000000", http.StatusBadRequest, "invalid received_before argument"},
+		{"bids received range too large", pathBids + "?received_after=1690000000&received_before=1690086401", http.StatusBadRequest, "maximum received range is 86400 seconds"},
+		{"payloads slot range", pathPayloads + "?slot_from=100&slot_to=200", http.StatusOK, ""},
+		{"payloads epoch", pathPayloads + "?epoch=200000&builder_pubkey=0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908", http.StatusOK, ""},
+		{"payloads invalid epoch", pathPayloads + "?epoch=abc", http.StatusBadRequest, "invalid epoch argument"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := backend.request(http.MethodGet, tc.path, nil)
+			require.Equal(t, tc.status, rr.Code, rr.Body.String())
+			if tc.errMsg != "" {
+				require.Contains(t, rr.Body.String(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestDataApiValidatorRegistrations(t *testing.T) {
+	sk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	mockDB := database.MockDB{}
+	for i := 3; i > 0; i-- {
+		reg, err := generateSignedValidatorRegistration(sk, types.Address{byte(i)}, uint64(1690000000+i))
+		require.NoError(t, err)
+		entry := database.SignedValidatorRegistrationToEntry(*reg)
+		mockDB.Registrations = append(mockDB.Registrations, &entry)
+	}
+	pubkey := mockDB.Registrations[0].Pubkey
+	otherPubkey := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+
+	t.Run("history", func(t *testing.T) {
+		path := "/relay/v1/data/validator_registration/history"
+		backend := newTestBackend(t, 1)
+		backend.relay.db = mockDB
+
+		rr := backend.request(http.MethodGet, path, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "missing pubkey argument")
+
+		rr = backend.request(http.MethodGet, path+"?pubkey="+pubkey+"&limit=201", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "maximum limit is 200")
+
+		rr = backend.request(http.MethodGet, path+"?pubkey="+pubkey, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := []types.SignedValidatorRegistration{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp, 3)
+		require.Equal(t, uint64(1690000003), resp[0].Message.Timestamp)
+		require.Equal(t, types.Address{3}, resp[0].Message.FeeRecipient)
+		require.Equal(t, uint64(1690000001), resp[2].Message.Timestamp)
+
+		rr = backend.request(http.MethodGet, path+"?pubkey="+otherPubkey, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.JSONEq(t, "[]", rr.Body.String())
+	})
+
+	t.Run("bulk lookup", func(t *testing.T) {
+		path := "/relay/v1/data/validator_registrations"
+		backend := newTestBackend(t, 1)
+		backend.relay.db = mockDB
+
+		rr := backend.request(http.MethodPost, path, []string{})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "no pubkeys in request")
+
+		rr = backend.request(http.MethodPost, path, []string{pubkey, "0x1234"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid pubkey 0x1234")
+
+		rr = backend.request(http.MethodPost, path, map[string]string{"pubkey": pubkey})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		tooMany := make([]string, dataAPIMaxRegPubkeys+1)
+		for i := range tooMany {
+			tooMany[i] = otherPubkey
+		}
+		rr = backend.request(http.MethodPost, path, tooMany)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "maximum number of pubkeys")
+
+		rr = backend.request(http.MethodPost, path, []string{pubkey, otherPubkey})
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := []types.SignedValidatorRegistration{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp, 1)
+		require.Equal(t, uint64(1690000003), resp[0].Message.Timestamp)
+	})
+}
+
+func TestDataApiBuilders(t *testing.T) {
+	path := "/relay/v1/data/builders"
+	backend := newTestBackend(t, 1)
+	backend.relay.db = database.MockDB{
+		Builders: map[string]*database.BlockBuilderEntry{
+			"0xa1": {BuilderPubkey: "0xa1", BuilderID: "builder-a", NumSubmissionsTotal: 100, NumSubmissionsSimError: 5, IsHighPrio: true}, //nolint:exhaustruct
+			"0xb1": {BuilderPubkey: "0xb1", BuilderID: "builder-b", NumSubmissionsTotal: 10},                                               //nolint:exhaustruct
+		},
+	}
+
+	// The statistics window ends at the head slot
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	backend.relay.headSlot.Store(10000)
+
+	rr = backend.request(http.MethodGet, path+"?window=0", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "invalid window argument")
+
+	rr = backend.request(http.MethodGet, path+"?window=7201", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "maximum window is 7200")
+
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := []common.BuilderStatsJSON{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 2)
+
+	rr = backend.request(http.MethodGet, path+"?builder_id=builder-a&window=100", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	require.Equal(t, "0xa1", resp[0].BuilderPubkey)
+	require.True(t, resp[0].IsHighPrio)
+	require.Equal(t, uint64(9900), resp[0].WindowSlotFrom)
+	require.InDelta(t, 0.05, resp[0].SimErrorRate, 1e-9)
+	require.Equal(t, "0", resp[0].ValueDelivered)
+}
+
+func TestDataApiSlotAuctionSummary(t *testing.T) {
+	path := "/relay/v1/data/slot_auction_summary"
+	backend := newTestBackend(t, 1)
+	mockDB := database.MockDB{AuctionSummaries: map[uint64]*database.SlotAuctionSummaryEntry{}}
+	for slot := uint64(62); slot <= 65; slot++ {
+		err := mockDB.SaveSlotAuctionSummary(&database.SlotAuctionSummaryEntry{Slot: slot, TopBidTimeline: "[]"}) //nolint:exhaustruct
+		require.NoError(t, err)
+	}
+	backend.relay.db = mockDB
+
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "need to query for specific slot or slot range or epoch")
+
+	rr = backend.request(http.MethodGet, path+"?slot=64&slot_from=1&slot_to=2", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = backend.request(http.MethodGet, path+"?slot=64", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := []common.SlotAuctionSummaryJSON{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	require.Equal(t, uint64(64), resp[0].Slot)
+
+	rr = backend.request(http.MethodGet, path+"?epoch=1", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 2)
+	require.Equal(t, uint64(63), resp[0].Slot)
+
+	rr = backend.request(http.MethodGet, path+"?slot_from=60&slot_to=70", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 4)
+}
+
+func TestDataApiDeliveredBlock(t *testing.T) {
+	deliveredHash := "0x1111111111111111111111111111111111111111111111111111111111111111"
+	archivedHash := "0x2222222222222222222222222222222222222222222222222222222222222222"
+	unknownHash := "0x3333333333333333333333333333333333333333333333333333333333333333"
+	backend := newTestBackend(t, 1)
+	backend.relay.capellaEpoch = 1
+	backend.relay.db = database.MockDB{
+		DeliveredPayloads: []*database.DeliveredPayloadEntry{
+			{Slot: 40, BlockHash: deliveredHash, SignedBlindedBeaconBlock: database.NewNullString(`{"message":{},"signature":"0x01"}`)}, //nolint:exhaustruct
+			{Slot: 30, BlockHash: archivedHash}, //nolint:exhaustruct
+		},
+		ExecutionPayloads: map[string]*database.ExecutionPayloadEntry{
+			deliveredHash: {Slot: 40, BlockHash: deliveredHash, Version: "capella", Payload: `{"block_hash":"` + deliveredHash + `"}`}, //nolint:exhaustruct
+			unknownHash:   {Slot: 50, BlockHash: unknownHash, Version: "capella", Payload: `{}`},                                       //nolint:exhaustruct
+		},
+	}
+
+	t.Run("signed blinded block", func(t *testing.T) {
+		path := "/relay/v1/data/signed_blinded_block"
+		rr := backend.request(http.MethodGet, path+"?block_hash=0x1234", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = backend.request(http.MethodGet, path+"?block_hash="+unknownHash, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = backend.request(http.MethodGet, path+"?block_hash="+archivedHash, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = backend.request(http.MethodGet, path+"?block_hash="+deliveredHash, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.JSONEq(t, `{"version":"capella","data":{"message":{},"signature":"0x01"}}`, rr.Body.String())
+	})
+
+	t.Run("execution payload", func(t *testing.T) {
+		path := "/relay/v1/data/execution_payload"
+
+		// payloads of blocks that were not delivered are never returned
+		rr := backend.request(http.MethodGet, path+"?block_hash="+unknownHash, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = backend.request(http.MethodGet, path+"?block_hash="+archivedHash, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "execution payload not available")
+
+		rr = backend.request(http.MethodGet, path+"?block_hash="+deliveredHash, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.JSONEq(t, `{"version":"capella","data":{"block_hash":"`+deliveredHash+`"}}`, rr.Body.String())
+	})
+}
+
+func TestDataApiGetPayloadTiming(t *testing.T) {
+	path := "/relay/v1/data/getpayload_timing"
+	proposerPubkey := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+	otherPubkey := "0xb67a5148a03229926e34b190af81a82a81c4df66831c98c03a139778418dd09a3b542ced0022620d19f35781ece6dc36"
+	genesisTime := uint64(1606824023)
+	slotStart := time.Unix(int64(genesisTime+40*common.SecondsPerSlot), 0)
+
+	backend := newTestBackend(t, 1)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{Data: beaconclient.GetGenesisResponseData{GenesisTime: genesisTime}} //nolint:exhaustruct
+	backend.relay.db = database.MockDB{
+		DeliveredPayloads: []*database.DeliveredPayloadEntry{
+			{Slot: 40, ProposerPubkey: proposerPubkey, SignedAt: database.NewNullTime(slotStart.Add(1200 * time.Millisecond)), PublishMs: 150}, //nolint:exhaustruct
+			{Slot: 39, ProposerPubkey: otherPubkey}, //nolint:exhaustruct
+		},
+		TooLateGetPayloads: []*database.TooLateGetPayloadEntry{
+			{Slot: 41, ProposerPubkey: proposerPubkey, MsIntoSlot: 4200}, //nolint:exhaustruct
+		},
+	}
+
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "need to query for specific slot or slot range or epoch or proposer_pubkey")
+
+	rr = backend.request(http.MethodGet, path+"?proposer_pubkey=0x1234", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = backend.request(http.MethodGet, path+"?proposer_pubkey="+proposerPubkey, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := []common.GetPayloadTimingJSON{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 2)
+	require.Equal(t, uint64(41), resp[0].Slot)
+	require.True(t, resp[0].TooLate)
+	require.Equal(t, uint64(4200), resp[0].MsIntoSlot)
+	require.Equal(t, uint64(40), resp[1].Slot)
+	require.True(t, resp[1].Delivered)
+	require.Equal(t, uint64(1200), resp[1].MsIntoSlot)
+	require.Equal(t, uint64(150), resp[1].PublishMs)
+
+	rr = backend.request(http.MethodGet, path+"?slot_from=39&slot_to=40", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 2)
+	require.Equal(t, uint64(40), resp[0].Slot)
+	require.Equal(t, uint64(39), resp[1].Slot)
+
+	rr = backend.request(http.MethodGet, path+"?slot=41&limit=1", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	require.True(t, resp[0].TooLate)
+}
+
+func TestInternalDemotions(t *testing.T) {
+	path := "/internal/v1/demotions"
+	backend := newTestBackend(t, 1)
+
+	rr := backend.request(http.MethodGet, path+"?slot_from=10", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "invalid slot_to argument")
+
+	rr = backend.request(http.MethodGet, path+"?slot_from=10&slot_to=9", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = backend.request(http.MethodGet, path+"?slot_from=10&slot_to=20&builder_pubkey=0x1234", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "invalid builder_pubkey argument")
+
+	rr = backend.request(http.MethodGet, path+"?slot_from=10&slot_to=20", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, "[]", rr.Body.String())
+}
+
 func TestBuilderSubmitBlockSSZ(t *testing.T) {
 	requestPayloadJSONBytes, err := os.ReadFile("../../testdata/submitBlockPayloadCapella_Goerli.json")
 	require.NoError(t, err)
@@ -38,6 +38,11 @@ func checkBLSPublicKeyHex(pkHex string) error {
 	return proposerPubkey.UnmarshalText([]byte(pkHex))
 }
 
+func checkHashHex(hashHex string) error {
+	var hash boostTypes.Hash
+	return hash.UnmarshalText([]byte(hashHex))
+}
+
 func ComputeWithdrawalsRoot(w []*capella.Withdrawal) (phase0.Root, error) {
 	if w == nil {
 		return phase0.Root{}, ErrNoWithdrawals
@@ -0,0 +1,204 @@
+package housekeeper
+
+import (
+	"encoding/json"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/sirupsen/logrus"
+)
+
+// time to wait after a new head before summarizing the auction, so that deliveries and late getPayload calls are stored
+var auctionSummaryDelay = time.Duration(cli.GetEnvInt("AUCTION_SUMMARY_DELAY_MS", 8000)) * time.Millisecond
+
+// saveSlotAuctionSummaries summarizes the auctions of an inclusive slot range once they are over
+func (hk *Housekeeper) saveSlotAuctionSummaries(slotFrom, slotTo uint64) {
+	time.Sleep(auctionSummaryDelay)
+	for slot := slotFrom; slot <= slotTo; slot++ {
+		hk.saveSlotAuctionSummary(slot)
+	}
+}
+
+func (hk *Housekeeper) saveSlotAuctionSummary(slot uint64) {
+	log := hk.log.WithFields(logrus.Fields{
+		"method": "saveSlotAuctionSummary",
+		"slot":   slot,
+	})
+
+	submissions, err := hk.db.GetBuilderSubmissions(database.GetBuilderSubmissionsFilters{Slot: slot}) //nolint:exhaustruct
+	if err != nil {
+		log.WithError(err).Error("failed to get builder submissions")
+		return
+	}
+
+	var delivered *database.DeliveredPayloadEntry
+	deliveredPayloads, err := hk.db.GetRecentDeliveredPayloads(database.GetPayloadsFilters{Slot: slot, Limit: 1}) //nolint:exhaustruct
+	if err != nil {
+		log.WithError(err).Error("failed to get delivered payload")
+		return
+	} else if len(deliveredPayloads) > 0 {
+		delivered = deliveredPayloads[0]
+	}
+
+	tooLate, err := hk.db.GetTooLateGetPayload(slot)
+	if err != nil {
+		log.WithError(err).Error("failed to get too late getPayload calls")
+		return
+	}
+
+	summary, err := computeSlotAuctionSummary(slot, submissions, delivered, tooLate, hk.genesisTime)
+	if err != nil {
+		log.WithError(err).Error("failed to compute slot auction summary")
+		return
+	}
+
+	if summary.ParentHash != "" {
+		floorValue, err := hk.redis.GetFloorBidValue(slot, summary.ParentHash, summary.ProposerPubkey)
+		if err != nil {
+			log.WithError(err).Warn("failed to get floor bid value")
+		} else {
+			summary.FloorValue = floorValue.String()
+		}
+	}
+
+	err = hk.db.SaveSlotAuctionSummary(summary)
+	if err != nil {
+		log.WithError(err).Error("failed to save slot auction summary")
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"numBids":          summary.NumBids,
+		"numBuilders":      summary.NumBuilders,
+		"winningValue":     summary.WinningValue,
+		"getPayloadCalled": summary.GetPayloadCalled,
+	}).Info("saved slot auction summary")
+}
+
+// computeSlotAuctionSummary summarizes the auction of a slot from the valid submissions, the delivered payload (nil
+// if none) and the getPayload calls that came too late. The floor value is left for the caller to fill in.
+func computeSlotAuctionSummary(slot uint64, submissions []*database.BuilderBlockSubmissionEntry, delivered *database.DeliveredPayloadEntry, tooLate []*database.TooLateGetPayloadEntry, genesisTime uint64) (*database.SlotAuctionSummaryEntry, error) {
+	summary := &database.SlotAuctionSummaryEntry{ //nolint:exhaustruct
+		Slot:           slot,
+		Epoch:          slot / common.SlotsPerEpoch,
+		TopBidTimeline: "[]",
+		FloorValue:     "0",
+		WinningValue:   "0",
+		RunnerUpValue:  "0",
+		Margin:         "0",
+	}
+
+	// The auction is for the parent and proposer of the delivered payload, or else the one with the highest bid
+	if delivered != nil {
+		summary.ParentHash = delivered.ParentHash
+		summary.ProposerPubkey = delivered.ProposerPubkey
+	} else {
+		highestValue := big.NewInt(-1)
+		for _, submission := range submissions {
+			if value := bidValue(submission.Value); value.Cmp(highestValue) > 0 {
+				highestValue = value
+				summary.ParentHash = submission.ParentHash
+				summary.ProposerPubkey = submission.ProposerPubkey
+			}
+		}
+	}
+
+	bids := []*database.BuilderBlockSubmissionEntry{}
+	for _, submission := range submissions {
+		if submission.ParentHash == summary.ParentHash && submission.ProposerPubkey == summary.ProposerPubkey {
+			bids = append(bids, submission)
+		}
+	}
+	sort.Slice(bids, func(i, j int) bool {
+		ti, tj := bidReceivedAt(bids[i]), bidReceivedAt(bids[j])
+		if ti.Equal(tj) {
+			return bids[i].ID < bids[j].ID
+		}
+		return ti.Before(tj)
+	})
+
+	// Each builder's latest bid replaces its earlier ones, the top bid is the highest of the latest bids
+	latestBids := make(map[string]*database.BuilderBlockSubmissionEntry)
+	timeline := []common.AuctionTopBidJSON{}
+	var topBid *database.BuilderBlockSubmissionEntry
+	for _, bid := range bids {
+		latestBids[bid.BuilderPubkey] = bid
+
+		var newTopBid *database.BuilderBlockSubmissionEntry
+		for _, latestBid := range latestBids {
+			if newTopBid == nil || bidValue(latestBid.Value).Cmp(bidValue(newTopBid.Value)) > 0 {
+				newTopBid = latestBid
+			}
+		}
+		if topBid == nil || newTopBid.BlockHash != topBid.BlockHash {
+			topBid = newTopBid
+			timeline = append(timeline, common.AuctionTopBidJSON{
+				TimestampMs:   bidReceivedAt(bid).UnixMilli(),
+				BuilderPubkey: topBid.BuilderPubkey,
+				BlockHash:     topBid.BlockHash,
+				Value:         topBid.Value,
+			})
+		}
+	}
+
+	timelineJSON, err := json.Marshal(timeline)
+	if err != nil {
+		return nil, err
+	}
+	summary.TopBidTimeline = string(timelineJSON)
+	summary.NumBids = uint64(len(bids))
+	summary.NumBuilders = uint64(len(latestBids))
+
+	if delivered != nil {
+		summary.WinningBuilderPubkey = delivered.BuilderPubkey
+		summary.WinningBlockHash = delivered.BlockHash
+		summary.WinningValue = bidValue(delivered.Value).String()
+
+		// The runner-up is the highest bid of any other builder
+		runnerUpValue := big.NewInt(0)
+		for _, bid := range bids {
+			if value := bidValue(bid.Value); bid.BuilderPubkey != delivered.BuilderPubkey && value.Cmp(runnerUpValue) > 0 {
+				runnerUpValue = value
+				summary.RunnerUpBuilderPubkey = bid.BuilderPubkey
+			}
+		}
+		summary.RunnerUpValue = runnerUpValue.String()
+		summary.Margin = new(big.Int).Sub(bidValue(delivered.Value), runnerUpValue).String()
+
+		summary.GetPayloadCalled = true
+		summary.PublishMs = delivered.PublishMs
+		slotStartMs := int64(genesisTime+slot*common.SecondsPerSlot) * 1000
+		if genesisTime > 0 && delivered.SignedAt.Valid && delivered.SignedAt.Time.UnixMilli() > slotStartMs {
+			summary.GetPayloadMsIntoSlot = uint64(delivered.SignedAt.Time.UnixMilli() - slotStartMs)
+		}
+	}
+
+	if len(tooLate) > 0 {
+		summary.GetPayloadCalled = true
+		summary.GetPayloadTooLate = true
+		if summary.GetPayloadMsIntoSlot == 0 {
+			summary.GetPayloadMsIntoSlot = tooLate[0].MsIntoSlot
+		}
+	}
+
+	return summary, nil
+}
+
+func bidValue(value string) *big.Int {
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return big.NewInt(0)
+	}
+	return v
+}
+
+func bidReceivedAt(bid *database.BuilderBlockSubmissionEntry) time.Time {
+	if bid.ReceivedAt.Valid {
+		return bid.ReceivedAt.Time
+	}
+	return bid.InsertedAt
+}
@@ -0,0 +1,108 @@
+package housekeeper
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeSlotAuctionSummary(t *testing.T) {
+	slot := uint64(100)
+	genesisTime := uint64(1606824023)
+	slotStart := time.Unix(int64(genesisTime+slot*common.SecondsPerSlot), 0)
+	parentHash := "0x01"
+	proposerPubkey := "0xaa"
+
+	bid := func(id int64, builder, blockHash, value string, ms int) *database.BuilderBlockSubmissionEntry {
+		return &database.BuilderBlockSubmissionEntry{ //nolint:exhaustruct
+			ID:             id,
+			ReceivedAt:     database.NewNullTime(slotStart.Add(time.Duration(ms) * time.Millisecond)),
+			Slot:           slot,
+			ParentHash:     parentHash,
+			ProposerPubkey: proposerPubkey,
+			BuilderPubkey:  builder,
+			BlockHash:      blockHash,
+			Value:          value,
+		}
+	}
+	submissions := []*database.BuilderBlockSubmissionEntry{
+		bid(4, "0xb1", "0x14", "40", -100),
+		bid(3, "0xb2", "0x23", "30", -500),
+		bid(2, "0xb1", "0x12", "20", -1000),
+		bid(1, "0xb1", "0x11", "10", -2000),
+		// bid for another parent hash
+		{ID: 5, Slot: slot, ParentHash: "0x02", ProposerPubkey: proposerPubkey, BuilderPubkey: "0xb3", BlockHash: "0x35", Value: "1"}, //nolint:exhaustruct
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		delivered := &database.DeliveredPayloadEntry{ //nolint:exhaustruct
+			SignedAt:       database.NewNullTime(slotStart.Add(1500 * time.Millisecond)),
+			Slot:           slot,
+			ParentHash:     parentHash,
+			ProposerPubkey: proposerPubkey,
+			BuilderPubkey:  "0xb1",
+			BlockHash:      "0x14",
+			Value:          "40",
+			PublishMs:      120,
+		}
+		summary, err := computeSlotAuctionSummary(slot, submissions, delivered, nil, genesisTime)
+		require.NoError(t, err)
+		require.Equal(t, slot/common.SlotsPerEpoch, summary.Epoch)
+		require.Equal(t, parentHash, summary.ParentHash)
+		require.Equal(t, uint64(4), summary.NumBids)
+		require.Equal(t, uint64(2), summary.NumBuilders)
+		require.Equal(t, "0xb1", summary.WinningBuilderPubkey)
+		require.Equal(t, "40", summary.WinningValue)
+		require.Equal(t, "0xb2", summary.RunnerUpBuilderPubkey)
+		require.Equal(t, "30", summary.RunnerUpValue)
+		require.Equal(t, "10", summary.Margin)
+		require.True(t, summary.GetPayloadCalled)
+		require.False(t, summary.GetPayloadTooLate)
+		require.Equal(t, uint64(1500), summary.GetPayloadMsIntoSlot)
+		require.Equal(t, uint64(120), summary.PublishMs)
+
+		timeline := []common.AuctionTopBidJSON{}
+		require.NoError(t, json.Unmarshal([]byte(summary.TopBidTimeline), &timeline))
+		require.Len(t, timeline, 4)
+		require.Equal(t, "10", timeline[0].Value)
+		require.Equal(t, "20", timeline[1].Value)
+		require.Equal(t, "0xb2", timeline[2].BuilderPubkey)
+		require.Equal(t, "40", timeline[3].Value)
+		require.Equal(t, slotStart.Add(-100*time.Millisecond).UnixMilli(), timeline[3].TimestampMs)
+	})
+
+	t.Run("cancelled top bid", func(t *testing.T) {
+		// builder 1 lowers its bid, which makes builder 2 the top bid again
+		cancellation := bid(6, "0xb1", "0x16", "5", 0)
+		summary, err := computeSlotAuctionSummary(slot, append([]*database.BuilderBlockSubmissionEntry{cancellation}, submissions...), nil, nil, genesisTime)
+		require.NoError(t, err)
+		timeline := []common.AuctionTopBidJSON{}
+		require.NoError(t, json.Unmarshal([]byte(summary.TopBidTimeline), &timeline))
+		require.Len(t, timeline, 5)
+		require.Equal(t, "0x23", timeline[4].BlockHash)
+	})
+
+	t.Run("too late", func(t *testing.T) {
+		tooLate := []*database.TooLateGetPayloadEntry{{Slot: slot, MsIntoSlot: 4500}} //nolint:exhaustruct
+		summary, err := computeSlotAuctionSummary(slot, submissions, nil, tooLate, genesisTime)
+		require.NoError(t, err)
+		require.Equal(t, parentHash, summary.ParentHash)
+		require.Equal(t, "", summary.WinningBlockHash)
+		require.Equal(t, "0", summary.Margin)
+		require.True(t, summary.GetPayloadCalled)
+		require.True(t, summary.GetPayloadTooLate)
+		require.Equal(t, uint64(4500), summary.GetPayloadMsIntoSlot)
+	})
+
+	t.Run("no bids", func(t *testing.T) {
+		summary, err := computeSlotAuctionSummary(slot, nil, nil, nil, genesisTime)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), summary.NumBids)
+		require.Equal(t, "[]", summary.TopBidTimeline)
+		require.False(t, summary.GetPayloadCalled)
+	})
+}
@@ -4,6 +4,7 @@
 // - Updating proposer duties
 // - Saving metrics
 // - Deleting old bids
+// - Summarizing slot auctions
 // - ...
 package housekeeper
 
@@ -51,7 +52,8 @@ type Housekeeper struct {
 	isUpdatingProposerDuties uberatomic.Bool
 	proposerDutiesSlot       uint64
 
-	headSlot uberatomic.Uint64
+	headSlot    uberatomic.Uint64
+	genesisTime uint64
 
 	lastValdatorUpdateSlot uberatomic.Uint64
 	lastValdatorIsUpdating uberatomic.Bool
@@ -89,6 +91,12 @@ func (hk *Housekeeper) Start() (err error) {
 		return err
 	}
 
+	genesisInfo, err := hk.beaconClient.GetGenesis()
+	if err != nil {
+		return err
+	}
+	hk.genesisTime = genesisInfo.Data.GenesisTime
+
 	// Start pprof API, if requested
 	if hk.pprofAPI {
 		go hk.startPprofAPI()
@@ -149,6 +157,16 @@ func (hk *Housekeeper) processNewSlot(headSlot uint64) {
 	// Update proposer duties
 	go hk.updateProposerDuties(headSlot)
 
+	// Summarize the auctions of the new head slot and any missed slots before it
+	summarySlotFrom := headSlot
+	if prevHeadSlot > 0 {
+		summarySlotFrom = prevHeadSlot + 1
+	}
+	if headSlot-summarySlotFrom >= common.SlotsPerEpoch {
+		summarySlotFrom = headSlot - common.SlotsPerEpoch + 1
+	}
+	go hk.saveSlotAuctionSummaries(summarySlotFrom, headSlot)
+
 	// Set headSlot in redis (for the website)
 	err := hk.redis.SetStats(datastore.RedisStatsFieldLatestSlot, headSlot)
 	if err != nil {